@@ -0,0 +1,109 @@
+// Package apikey provides an auth provider which verifies static API keys,
+// sent in the metadata key auth.APIKeyKey.
+package apikey
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
+
+	"github.com/go-orb/go-orb/auth"
+	"github.com/go-orb/go-orb/config"
+	"github.com/go-orb/go-orb/log"
+	"github.com/go-orb/go-orb/util/metadata"
+)
+
+// Name is the name of this provider.
+const Name = "apikey"
+
+// Errors of the apikey provider.
+var (
+	ErrInvalidKey = errors.New("invalid api key")
+	ErrNoKeys     = errors.New("no api keys configured")
+)
+
+var _ auth.Provider = (*Provider)(nil)
+
+func init() {
+	auth.Providers.Add(Name, Provide)
+}
+
+// Config is the config of the apikey provider.
+type Config struct {
+	// Keys maps the API keys to the subject of their principal.
+	Keys map[string]string `json:"keys" yaml:"keys"`
+	// MetadataKey is the incoming metadata key with the API key, defaults to auth.APIKeyKey.
+	MetadataKey string `json:"metadataKey,omitempty" yaml:"metadataKey,omitempty"`
+}
+
+type key struct {
+	digest  [sha256.Size]byte
+	subject string
+}
+
+// Provider verifies API keys.
+type Provider struct {
+	metadataKey string
+	keys        []key
+}
+
+// Provide creates an apikey provider from the config.
+func Provide(configData map[string]any, _ log.Logger) (auth.Provider, error) {
+	cfg := Config{MetadataKey: auth.APIKeyKey}
+
+	if err := config.Parse(nil, "", configData, &cfg); err != nil && !errors.Is(err, config.ErrNoSuchKey) {
+		return nil, err
+	}
+
+	return New(cfg)
+}
+
+// New creates an apikey provider.
+func New(cfg Config) (*Provider, error) {
+	if len(cfg.Keys) == 0 {
+		return nil, ErrNoKeys
+	}
+
+	if cfg.MetadataKey == "" {
+		cfg.MetadataKey = auth.APIKeyKey
+	}
+
+	p := &Provider{metadataKey: cfg.MetadataKey, keys: make([]key, 0, len(cfg.Keys))}
+	for k, subject := range cfg.Keys {
+		p.keys = append(p.keys, key{digest: sha256.Sum256([]byte(k)), subject: subject})
+	}
+
+	return p, nil
+}
+
+// Name returns the name of this provider.
+func (p *Provider) Name() string {
+	return Name
+}
+
+// Verify looks up the API key in md.
+//
+// The digests of all keys get compared in constant time, so the time it
+// takes doesn't tell which or how many bytes of a key matched.
+func (p *Provider) Verify(_ context.Context, md *metadata.MD) (*auth.Principal, error) {
+	value, ok := md.Value(p.metadataKey)
+	if !ok || value == "" {
+		return nil, auth.ErrNoCredentials
+	}
+
+	digest := sha256.Sum256([]byte(value))
+	subject, found := "", false
+
+	for _, k := range p.keys {
+		if subtle.ConstantTimeCompare(digest[:], k.digest[:]) == 1 {
+			subject, found = k.subject, true
+		}
+	}
+
+	if !found {
+		return nil, ErrInvalidKey
+	}
+
+	return &auth.Principal{Subject: subject, Provider: Name}, nil
+}
@@ -0,0 +1,62 @@
+package apikey
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/go-orb/go-orb/auth"
+	"github.com/go-orb/go-orb/util/metadata"
+)
+
+func TestVerify(t *testing.T) {
+	p, err := New(Config{Keys: map[string]string{"key-a": "alice", "key-b": "bob"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		md       *metadata.MD
+		subject  string
+		expected error
+	}{
+		{metadata.Pairs(auth.APIKeyKey, "key-a"), "alice", nil},
+		{metadata.Pairs(auth.APIKeyKey, "key-b"), "bob", nil},
+		{metadata.Pairs(auth.APIKeyKey, "key-c"), "", ErrInvalidKey},
+		{metadata.Pairs(auth.APIKeyKey, "key-"), "", ErrInvalidKey},
+		{metadata.Pairs(auth.APIKeyKey, ""), "", auth.ErrNoCredentials},
+		{metadata.New(nil), "", auth.ErrNoCredentials},
+		{metadata.Pairs("x-other-key", "key-a"), "", auth.ErrNoCredentials},
+	} {
+		principal, err := p.Verify(context.Background(), tc.md)
+		if !errors.Is(err, tc.expected) {
+			t.Fatalf("expected %v, got %v", tc.expected, err)
+		}
+
+		if tc.expected == nil && (principal.Subject != tc.subject || principal.Provider != Name) {
+			t.Fatalf("expected the principal %s, got %+v", tc.subject, principal)
+		}
+	}
+}
+
+func TestMetadataKey(t *testing.T) {
+	p, err := New(Config{Keys: map[string]string{"key-a": "alice"}, MetadataKey: "x-other-key"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	principal, err := p.Verify(context.Background(), metadata.Pairs("x-other-key", "key-a"))
+	if err != nil || principal.Subject != "alice" {
+		t.Fatalf("expected alice from the custom key, got %+v: %v", principal, err)
+	}
+
+	if _, err := p.Verify(context.Background(), metadata.Pairs(auth.APIKeyKey, "key-a")); !errors.Is(err, auth.ErrNoCredentials) {
+		t.Fatalf("expected the default key to be ignored, got %v", err)
+	}
+}
+
+func TestNoKeys(t *testing.T) {
+	if _, err := New(Config{}); !errors.Is(err, ErrNoKeys) {
+		t.Fatalf("expected ErrNoKeys, got %v", err)
+	}
+}
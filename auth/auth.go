@@ -0,0 +1,150 @@
+// Package auth provides authentication for go-orb services.
+//
+// Providers verify the credentials in the incoming metadata and return the
+// Principal, the "auth" server middleware stores it in the handlers context.
+// Clients attach their credentials with the "auth" client middleware.
+//
+// Providers register themselves in Providers, like the "jwt" and "apikey" subpackages.
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-orb/go-orb/config"
+	"github.com/go-orb/go-orb/log"
+	"github.com/go-orb/go-orb/util/container"
+	"github.com/go-orb/go-orb/util/metadata"
+	"github.com/go-orb/go-orb/util/orberrors"
+)
+
+// Metadata keys of the built-in credentials.
+const (
+	// AuthorizationKey holds "Bearer <token>".
+	AuthorizationKey = "authorization"
+	// APIKeyKey is the default key of API keys.
+	APIKeyKey = "x-api-key"
+)
+
+// ErrNoCredentials is returned by a Provider when the metadata has no credentials
+// it handles, the next provider gets asked.
+var ErrNoCredentials = errors.New("no credentials")
+
+// Principal is the authenticated caller.
+type Principal struct {
+	// Subject identifies the caller, e.g. a user or service name.
+	Subject string
+	// Provider is the name of the provider which verified the credentials.
+	Provider string
+	// Claims are provider specific attributes, e.g. the claims of a JWT.
+	Claims map[string]any
+	// ExpiresAt is the time the credentials expire, zero if they don't.
+	ExpiresAt time.Time
+}
+
+// Provider verifies credentials.
+type Provider interface {
+	// Name returns the name of the provider.
+	Name() string
+	// Verify returns the principal of the credentials in md, ErrNoCredentials if there
+	// are none it handles. Other errors reject the request.
+	Verify(ctx context.Context, md *metadata.MD) (*Principal, error)
+}
+
+// ProviderFactory creates a provider from its config.
+type ProviderFactory func(configData map[string]any, logger log.Logger) (Provider, error)
+
+// Providers is the plugins container for providers.
+//
+//nolint:gochecknoglobals
+var Providers = container.NewMap[string, ProviderFactory]()
+
+type principalKey struct{}
+
+// ContextWithPrincipal returns a copy of ctx with p.
+func ContextWithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// PrincipalFromContext returns the principal of the authenticated caller.
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(*Principal)
+	return p, ok && p != nil
+}
+
+// ProviderConfig selects a provider, its other keys are the providers config.
+type ProviderConfig struct {
+	Name string `json:"name" yaml:"name"`
+}
+
+// Authenticator asks its providers in order to verify the credentials of a request.
+type Authenticator struct {
+	providers []Provider
+	// optional lets requests without credentials through.
+	optional bool
+}
+
+// New creates an authenticator, with optional requests without credentials pass unauthenticated.
+func New(optional bool, providers ...Provider) *Authenticator {
+	return &Authenticator{providers: providers, optional: optional}
+}
+
+// NewFromConfig creates the providers from their configs, see ProviderConfig.
+func NewFromConfig(optional bool, configs []map[string]any, logger log.Logger) (*Authenticator, error) {
+	providers := make([]Provider, 0, len(configs))
+
+	for _, data := range configs {
+		var cfg ProviderConfig
+		if err := config.Parse(nil, "", data, &cfg); err != nil && !errors.Is(err, config.ErrNoSuchKey) {
+			return nil, err
+		}
+
+		factory, ok := Providers.Get(cfg.Name)
+		if !ok {
+			return nil, fmt.Errorf("auth provider '%s' not found, did you import it?", cfg.Name)
+		}
+
+		p, err := factory(data, logger.With("provider", cfg.Name))
+		if err != nil {
+			return nil, err
+		}
+
+		providers = append(providers, p)
+	}
+
+	return New(optional, providers...), nil
+}
+
+// Authenticate verifies the credentials in the incoming metadata of ctx and returns
+// a copy of ctx with the principal. Failures are orberrors.ErrUnauthorized.
+func (a *Authenticator) Authenticate(ctx context.Context) (context.Context, error) {
+	md, ok := metadata.IncomingMD(ctx)
+	if !ok {
+		md = metadata.New(nil)
+	}
+
+	for _, p := range a.providers {
+		principal, err := p.Verify(ctx, md)
+
+		switch {
+		case errors.Is(err, ErrNoCredentials):
+			continue
+		case err != nil:
+			return nil, orberrors.ErrUnauthorized.Wrap(err)
+		}
+
+		if principal.Provider == "" {
+			principal.Provider = p.Name()
+		}
+
+		return ContextWithPrincipal(ctx, principal), nil
+	}
+
+	if a.optional {
+		return ctx, nil
+	}
+
+	return nil, orberrors.ErrUnauthorized.WrapNew("no credentials")
+}
@@ -0,0 +1,36 @@
+package auth
+
+import (
+	"context"
+
+	"github.com/go-orb/go-orb/util/metadata"
+)
+
+// Credentials write the credentials of a client into the outgoing metadata of a request.
+type Credentials interface {
+	Apply(ctx context.Context, md *metadata.MD) error
+}
+
+// CredentialsFunc is a function which implements Credentials, e.g. to refresh tokens.
+type CredentialsFunc func(ctx context.Context, md *metadata.MD) error
+
+// Apply calls f.
+func (f CredentialsFunc) Apply(ctx context.Context, md *metadata.MD) error {
+	return f(ctx, md)
+}
+
+// BearerToken sends token in the AuthorizationKey.
+func BearerToken(token string) Credentials {
+	return CredentialsFunc(func(_ context.Context, md *metadata.MD) error {
+		md.Set(AuthorizationKey, "Bearer "+token)
+		return nil
+	})
+}
+
+// APIKey sends key in the APIKeyKey.
+func APIKey(key string) Credentials {
+	return CredentialsFunc(func(_ context.Context, md *metadata.MD) error {
+		md.Set(APIKeyKey, key)
+		return nil
+	})
+}
@@ -0,0 +1,278 @@
+// Package jwt provides an auth provider which verifies HMAC signed JSON Web Tokens,
+// sent as "Bearer <token>" in the auth.AuthorizationKey.
+//
+// It supports the algorithms HS256, HS384 and HS512, tokens with any other
+// algorithm including "none" get rejected.
+package jwt
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-orb/go-orb/auth"
+	"github.com/go-orb/go-orb/config"
+	"github.com/go-orb/go-orb/log"
+	"github.com/go-orb/go-orb/util/metadata"
+)
+
+// Name is the name of this provider.
+const Name = "jwt"
+
+// Errors of invalid tokens.
+var (
+	ErrMalformed        = errors.New("malformed token")
+	ErrAlgorithm        = errors.New("unsupported token algorithm")
+	ErrSignature        = errors.New("invalid token signature")
+	ErrExpired          = errors.New("token expired")
+	ErrNotYetValid      = errors.New("token not yet valid")
+	ErrInvalidIssuer    = errors.New("invalid token issuer")
+	ErrInvalidAudience  = errors.New("invalid token audience")
+	ErrMissingSubject   = errors.New("token has no subject")
+	ErrNoSecret         = errors.New("no secret configured")
+	errUnsupportedClaim = errors.New("unsupported claim type")
+)
+
+var _ auth.Provider = (*Provider)(nil)
+
+func init() {
+	auth.Providers.Add(Name, Provide)
+}
+
+//nolint:gochecknoglobals
+var algorithms = map[string]func() hash.Hash{
+	"HS256": sha256.New,
+	"HS384": sha512.New384,
+	"HS512": sha512.New,
+}
+
+// Config is the config of the jwt provider.
+type Config struct {
+	// Secret is the HMAC key.
+	Secret string `json:"secret" yaml:"secret"`
+	// Algorithm is the algorithm Sign uses, Verify accepts all HMAC algorithms.
+	Algorithm string `json:"algorithm,omitempty" yaml:"algorithm,omitempty"`
+	// Issuer is the required "iss" claim, empty accepts any.
+	Issuer string `json:"issuer,omitempty" yaml:"issuer,omitempty"`
+	// Audience is the required "aud" claim, empty accepts any.
+	Audience string `json:"audience,omitempty" yaml:"audience,omitempty"`
+	// Leeway is the tolerated clock skew for "exp" and "nbf".
+	Leeway config.Duration `json:"leeway,omitempty" yaml:"leeway,omitempty"`
+}
+
+// Provider verifies and signs tokens.
+type Provider struct {
+	config Config
+	now    func() time.Time
+}
+
+// Provide creates a jwt provider from the config.
+func Provide(configData map[string]any, _ log.Logger) (auth.Provider, error) {
+	cfg := Config{Algorithm: "HS256"}
+
+	if err := config.Parse(nil, "", configData, &cfg); err != nil && !errors.Is(err, config.ErrNoSuchKey) {
+		return nil, err
+	}
+
+	return New(cfg)
+}
+
+// New creates a jwt provider.
+func New(cfg Config) (*Provider, error) {
+	if cfg.Secret == "" {
+		return nil, ErrNoSecret
+	}
+
+	if cfg.Algorithm == "" {
+		cfg.Algorithm = "HS256"
+	}
+
+	if _, ok := algorithms[cfg.Algorithm]; !ok {
+		return nil, fmt.Errorf("%w: %s", ErrAlgorithm, cfg.Algorithm)
+	}
+
+	return &Provider{config: cfg, now: time.Now}, nil
+}
+
+// Name returns the name of this provider.
+func (p *Provider) Name() string {
+	return Name
+}
+
+// Verify verifies the bearer token in md, the scheme "Bearer" is case-insensitive.
+func (p *Provider) Verify(_ context.Context, md *metadata.MD) (*auth.Principal, error) {
+	value, ok := md.Value(auth.AuthorizationKey)
+	if !ok {
+		return nil, auth.ErrNoCredentials
+	}
+
+	scheme, token, ok := strings.Cut(value, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return nil, auth.ErrNoCredentials
+	}
+
+	claims, err := p.Parse(token)
+	if err != nil {
+		return nil, err
+	}
+
+	subject, _ := claims["sub"].(string) //nolint:errcheck
+	if subject == "" {
+		return nil, ErrMissingSubject
+	}
+
+	principal := &auth.Principal{Subject: subject, Provider: Name, Claims: claims}
+	if exp, ok := claims["exp"].(float64); ok {
+		principal.ExpiresAt = time.Unix(int64(exp), 0)
+	}
+
+	return principal, nil
+}
+
+// Parse verifies token and returns its claims.
+func (p *Provider) Parse(token string) (map[string]any, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrMalformed
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+	}
+	if err := decode(parts[0], &header); err != nil {
+		return nil, err
+	}
+
+	newHash, ok := algorithms[header.Alg]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrAlgorithm, header.Alg)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrMalformed
+	}
+
+	if !hmac.Equal(signature, sign(newHash, p.config.Secret, parts[0]+"."+parts[1])) {
+		return nil, ErrSignature
+	}
+
+	claims := map[string]any{}
+	if err := decode(parts[1], &claims); err != nil {
+		return nil, err
+	}
+
+	if err := p.validate(claims); err != nil {
+		return nil, err
+	}
+
+	return claims, nil
+}
+
+// Sign returns a token with claims, signed with the configured algorithm.
+func (p *Provider) Sign(claims map[string]any) (string, error) {
+	header, err := json.Marshal(map[string]string{"alg": p.config.Algorithm, "typ": "JWT"})
+	if err != nil {
+		return "", err
+	}
+
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	signature := sign(algorithms[p.config.Algorithm], p.config.Secret, unsigned)
+
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+func (p *Provider) validate(claims map[string]any) error {
+	now := p.now()
+	leeway := time.Duration(p.config.Leeway)
+
+	if exp, err := numericDate(claims, "exp"); err != nil {
+		return err
+	} else if !exp.IsZero() && now.After(exp.Add(leeway)) {
+		return ErrExpired
+	}
+
+	if nbf, err := numericDate(claims, "nbf"); err != nil {
+		return err
+	} else if !nbf.IsZero() && now.Add(leeway).Before(nbf) {
+		return ErrNotYetValid
+	}
+
+	if p.config.Issuer != "" {
+		if iss, _ := claims["iss"].(string); iss != p.config.Issuer { //nolint:errcheck
+			return ErrInvalidIssuer
+		}
+	}
+
+	if p.config.Audience != "" && !hasAudience(claims["aud"], p.config.Audience) {
+		return ErrInvalidAudience
+	}
+
+	return nil
+}
+
+// numericDate returns the time of the claim key, zero if it's not set.
+func numericDate(claims map[string]any, key string) (time.Time, error) {
+	v, ok := claims[key]
+	if !ok {
+		return time.Time{}, nil
+	}
+
+	seconds, ok := v.(float64)
+	if !ok {
+		return time.Time{}, fmt.Errorf("%w: %s", errUnsupportedClaim, key)
+	}
+
+	return time.Unix(int64(seconds), 0), nil
+}
+
+// hasAudience reports if aud, a string or a list of strings, contains audience.
+func hasAudience(aud any, audience string) bool {
+	switch v := aud.(type) {
+	case string:
+		return v == audience
+	case []any:
+		return slices.ContainsFunc(v, func(a any) bool {
+			s, ok := a.(string)
+			return ok && s == audience
+		})
+	default:
+		return false
+	}
+}
+
+func decode(part string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(part)
+	if err != nil {
+		return ErrMalformed
+	}
+
+	d := json.NewDecoder(bytes.NewReader(data))
+	if err := d.Decode(v); err != nil {
+		return ErrMalformed
+	}
+
+	return nil
+}
+
+func sign(newHash func() hash.Hash, secret, unsigned string) []byte {
+	mac := hmac.New(newHash, []byte(secret))
+	mac.Write([]byte(unsigned))
+
+	return mac.Sum(nil)
+}
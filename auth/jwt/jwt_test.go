@@ -0,0 +1,74 @@
+package jwt
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-orb/go-orb/auth"
+	"github.com/go-orb/go-orb/util/metadata"
+)
+
+func TestVerify(t *testing.T) {
+	p, err := New(Config{Secret: "secret", Issuer: "orb", Audience: "api"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	p.now = func() time.Time { return now }
+
+	valid, err := p.Sign(map[string]any{"sub": "alice", "iss": "orb", "aud": []string{"api"}, "exp": now.Add(time.Minute).Unix()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	principal, err := p.Verify(context.Background(), metadata.Pairs(auth.AuthorizationKey, "Bearer "+valid))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if principal.Subject != "alice" || principal.ExpiresAt.Unix() != now.Add(time.Minute).Unix() {
+		t.Fatalf("unexpected principal: %+v", principal)
+	}
+
+	expired, _ := p.Sign(map[string]any{"sub": "alice", "iss": "orb", "aud": "api", "exp": now.Add(-time.Minute).Unix()}) //nolint:errcheck
+	foreign, _ := p.Sign(map[string]any{"sub": "alice", "iss": "other", "aud": "api"})                                    //nolint:errcheck
+	parts := strings.Split(valid, ".")
+	none := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none"}`)) + "." + parts[1] + "."
+
+	for _, tc := range []struct {
+		token    string
+		expected error
+	}{
+		{expired, ErrExpired},
+		{foreign, ErrInvalidIssuer},
+		{none, ErrAlgorithm},
+		{parts[0] + "." + parts[1] + "." + parts[2][1:], ErrSignature},
+		{"garbage", ErrMalformed},
+	} {
+		if _, err := p.Verify(context.Background(), metadata.Pairs(auth.AuthorizationKey, "Bearer "+tc.token)); !errors.Is(err, tc.expected) {
+			t.Fatalf("expected %v, got %v", tc.expected, err)
+		}
+	}
+
+	if _, err := p.Verify(context.Background(), metadata.New(nil)); !errors.Is(err, auth.ErrNoCredentials) {
+		t.Fatalf("expected ErrNoCredentials, got %v", err)
+	}
+
+	// The scheme is case-insensitive, other schemes aren't bearer tokens.
+	for _, scheme := range []string{"bearer", "BEARER"} {
+		if _, err := p.Verify(context.Background(), metadata.Pairs(auth.AuthorizationKey, scheme+" "+valid)); err != nil {
+			t.Fatalf("expected the scheme %q to be accepted, got %v", scheme, err)
+		}
+	}
+
+	for _, value := range []string{"Basic " + valid, valid} {
+		if _, err := p.Verify(context.Background(), metadata.Pairs(auth.AuthorizationKey, value)); !errors.Is(err, auth.ErrNoCredentials) {
+			t.Fatalf("expected ErrNoCredentials for %q, got %v", value, err)
+		}
+	}
+}
@@ -0,0 +1,231 @@
+package client
+
+import (
+	"context"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/go-orb/go-orb/util/matcher"
+)
+
+// maxCachedChains is the number of targets whose chains get cached.
+const maxCachedChains = 4096
+
+// chainEntry is a configured middleware.
+type chainEntry struct {
+	// id is the name in the matcher, names may be configured multiple times.
+	id   string
+	name string
+	// include is set if the middleware has include patterns.
+	include    bool
+	middleware Middleware
+}
+
+// chainKey identifies a target and the middleware overrides of a call.
+type chainKey struct {
+	target string
+	only   string
+	skip   string
+}
+
+// MiddlewareChain applies the configured middlewares which select a target, client
+// plugins call Request and Stream with their transport handlers as final handlers.
+//
+// The chain of each target, service/endpoint and the overrides of the call, gets
+// resolved once and cached. Add all middlewares before the first request.
+type MiddlewareChain struct {
+	request MiddlewareRequestHandler
+	stream  MiddlewareStreamHandler
+
+	entries []chainEntry
+	matcher matcher.Matcher[Middleware]
+
+	mu       sync.RWMutex
+	requests map[chainKey]MiddlewareRequestHandler
+	streams  map[chainKey]MiddlewareStreamHandler
+}
+
+// NewMiddlewareChain creates a chain with the final handlers, stream may be nil
+// if the client doesn't support streaming.
+func NewMiddlewareChain(request MiddlewareRequestHandler, stream MiddlewareStreamHandler) *MiddlewareChain {
+	if stream == nil {
+		stream = func(_ context.Context, _ string, _ string, _ *CallOptions) (StreamIface[any, any], error) {
+			return nil, ErrStreamNotSupported
+		}
+	}
+
+	return &MiddlewareChain{
+		request:  request,
+		stream:   stream,
+		matcher:  matcher.NewMatcher[Middleware](nil),
+		requests: make(map[chainKey]MiddlewareRequestHandler),
+		streams:  make(map[chainKey]MiddlewareStreamHandler),
+	}
+}
+
+// Add appends mw with the include and exclude patterns of cfg, the first middleware is the outermost.
+func (c *MiddlewareChain) Add(mw Middleware, cfg MiddlewareConfig) error {
+	id := strconv.Itoa(len(c.entries))
+
+	if len(cfg.Include) == 0 {
+		c.matcher.Use(id, mw)
+	}
+
+	for _, selector := range cfg.Include {
+		if err := c.matcher.Add(selector, id, mw); err != nil {
+			return err
+		}
+	}
+
+	for _, selector := range cfg.Exclude {
+		if err := c.matcher.Exclude(selector, id); err != nil {
+			return err
+		}
+	}
+
+	c.entries = append(c.entries, chainEntry{id: id, name: cfg.Name, include: len(cfg.Include) > 0, middleware: mw})
+
+	c.mu.Lock()
+	clear(c.requests)
+	clear(c.streams)
+	c.mu.Unlock()
+
+	return nil
+}
+
+// Middlewares returns the middlewares used for a request to service and endpoint with opts.
+func (c *MiddlewareChain) Middlewares(service, endpoint string, opts *CallOptions) []Middleware {
+	target := service + "/" + endpoint
+	result := make([]Middleware, 0, len(c.entries))
+
+	var only map[string]bool
+	if opts.Middlewares != nil {
+		only = c.only(target, opts.Middlewares)
+	}
+
+	for _, e := range c.entries {
+		switch {
+		case slices.Contains(opts.SkipMiddlewares, e.name):
+			continue
+		case only != nil:
+			if !only[e.id] {
+				continue
+			}
+		case !c.matcher.Matches(target, e.id):
+			continue
+		}
+
+		result = append(result, e.middleware)
+	}
+
+	return result
+}
+
+// only returns the ids of the middlewares used for names of WithMiddlewares. Of the
+// middlewares of a name the ones which select target are used, if none does a single
+// one is used, preferably one without include patterns.
+func (c *MiddlewareChain) only(target string, names []string) map[string]bool {
+	ids := make(map[string]bool)
+
+	for _, name := range names {
+		var (
+			fallback *chainEntry
+			matched  bool
+		)
+
+		for i := range c.entries {
+			e := &c.entries[i]
+
+			switch {
+			case e.name != name:
+				continue
+			case c.matcher.Matches(target, e.id):
+				ids[e.id] = true
+				matched = true
+			case fallback == nil || (fallback.include && !e.include):
+				fallback = e
+			}
+		}
+
+		if !matched && fallback != nil {
+			ids[fallback.id] = true
+		}
+	}
+
+	return ids
+}
+
+// Request runs the request through the chain of its target.
+func (c *MiddlewareChain) Request(
+	ctx context.Context,
+	service string,
+	endpoint string,
+	req any,
+	result any,
+	opts *CallOptions,
+) error {
+	key := newChainKey(service, endpoint, opts)
+
+	c.mu.RLock()
+	handler, ok := c.requests[key]
+	c.mu.RUnlock()
+
+	if !ok {
+		handler = c.request
+		mws := c.Middlewares(service, endpoint, opts)
+
+		for i := len(mws) - 1; i >= 0; i-- {
+			handler = mws[i].Request(handler)
+		}
+
+		c.mu.Lock()
+		if len(c.requests) < maxCachedChains {
+			c.requests[key] = handler
+		}
+		c.mu.Unlock()
+	}
+
+	return handler(ctx, service, endpoint, req, result, opts)
+}
+
+// Stream opens the stream through the chain of its target.
+func (c *MiddlewareChain) Stream(
+	ctx context.Context,
+	service string,
+	endpoint string,
+	opts *CallOptions,
+) (StreamIface[any, any], error) {
+	key := newChainKey(service, endpoint, opts)
+
+	c.mu.RLock()
+	handler, ok := c.streams[key]
+	c.mu.RUnlock()
+
+	if !ok {
+		handler = ChainStream(c.stream, c.Middlewares(service, endpoint, opts)...)
+
+		c.mu.Lock()
+		if len(c.streams) < maxCachedChains {
+			c.streams[key] = handler
+		}
+		c.mu.Unlock()
+	}
+
+	return handler(ctx, service, endpoint, opts)
+}
+
+func newChainKey(service, endpoint string, opts *CallOptions) chainKey {
+	key := chainKey{
+		target: service + "/" + endpoint,
+		skip:   strings.Join(opts.SkipMiddlewares, ","),
+	}
+
+	// A leading marker tells an empty override from none.
+	if opts.Middlewares != nil {
+		key.only = "=" + strings.Join(opts.Middlewares, ",")
+	}
+
+	return key
+}
@@ -0,0 +1,93 @@
+package client
+
+import (
+	"context"
+	"slices"
+	"testing"
+)
+
+// tag appends its name to the result of each request.
+type tag struct {
+	Middleware
+
+	name   string
+	builds int
+}
+
+func (t *tag) Request(next MiddlewareRequestHandler) MiddlewareRequestHandler {
+	t.builds++
+
+	return func(ctx context.Context, service string, endpoint string, req any, result any, opts *CallOptions) error {
+		names := result.(*[]string) //nolint:errcheck,forcetypeassert
+		*names = append(*names, t.name)
+
+		return next(ctx, service, endpoint, req, result, opts)
+	}
+}
+
+func TestMiddlewareChain(t *testing.T) {
+	chain := NewMiddlewareChain(func(_ context.Context, _, _ string, _, _ any, _ *CallOptions) error {
+		return nil
+	}, nil)
+
+	lookupRetry := &tag{name: "lookup-retry"}
+	retry := &tag{name: "retry"}
+	auth := &tag{name: "auth"}
+
+	for _, tc := range []struct {
+		mw  *tag
+		cfg MiddlewareConfig
+	}{
+		{auth, MiddlewareConfig{Name: "auth", Exclude: []string{"Health/"}}},
+		{lookupRetry, MiddlewareConfig{Name: "retry", Include: []string{"^lookup/"}}},
+		{retry, MiddlewareConfig{Name: "retry", Exclude: []string{"^lookup/", "^payment/"}}},
+	} {
+		if err := chain.Add(tc.mw, tc.cfg); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, tc := range []struct {
+		service  string
+		endpoint string
+		opts     []CallOption
+		expected []string
+	}{
+		{"lookup", "Users.Get", nil, []string{"auth", "lookup-retry"}},
+		{"payment", "Payments.Charge", nil, []string{"auth"}},
+		{"other", "Foo.Bar", nil, []string{"auth", "retry"}},
+		{"other", "Health/Check", nil, []string{"retry"}},
+		{"lookup", "Users.Get", []CallOption{WithoutMiddlewares("retry")}, []string{"auth"}},
+		// A single retry middleware is forced, the one without include patterns.
+		{"payment", "Payments.Charge", []CallOption{WithMiddlewares("retry")}, []string{"retry"}},
+		// The retry middleware which selects the target is used.
+		{"lookup", "Users.Get", []CallOption{WithMiddlewares("retry", "auth")}, []string{"auth", "lookup-retry"}},
+		{"payment", "Payments.Charge", []CallOption{WithMiddlewares()}, []string{}},
+	} {
+		opts := &CallOptions{}
+		for _, o := range tc.opts {
+			o(opts)
+		}
+
+		// Twice, the second request uses the cached chain.
+		for range 2 {
+			result := []string{}
+			if err := chain.Request(context.Background(), tc.service, tc.endpoint, nil, &result, opts); err != nil {
+				t.Fatal(err)
+			}
+
+			if !slices.Equal(result, tc.expected) {
+				t.Fatalf("%s/%s: expected %v, got %v", tc.service, tc.endpoint, tc.expected, result)
+			}
+		}
+	}
+
+	// auth is in the chains of 5 distinct targets and overrides.
+	if auth.builds != 5 {
+		t.Fatalf("expected the chains to be cached, auth has been built %d times", auth.builds)
+	}
+
+	if _, err := chain.Stream(context.Background(), "other", "Foo.Bar", &CallOptions{}); err == nil {
+		t.Fatal("expected ErrStreamNotSupported")
+	}
+}
@@ -57,12 +57,34 @@ type RequestInfos struct {
 	Endpoint  string
 	Transport string
 	Address   string
+
+	// Tier is the locality tier which served the request, see ResolveNodes.
+	Tier string
+	// Region is the region of the node which served the request.
+	Region string
 }
 
 // RequestInfo returns the request infos from the context.
 func RequestInfo(ctx context.Context) (RequestInfos, bool) {
 	v, ok := ctx.Value(RequestInfosKey{}).(*RequestInfos)
-	return *v, ok
+	if !ok || v == nil {
+		return RequestInfos{}, false
+	}
+
+	return *v, true
+}
+
+// ContextWithRequestInfos returns ctx with its RequestInfos, it adds empty ones if ctx has none.
+// Middlewares use it to read the infos after the request, the client fills in the RequestInfos
+// of ctx instead of adding new ones.
+func ContextWithRequestInfos(ctx context.Context, service, endpoint string) (context.Context, *RequestInfos) {
+	if v, ok := ctx.Value(RequestInfosKey{}).(*RequestInfos); ok && v != nil {
+		return ctx, v
+	}
+
+	infos := &RequestInfos{Service: service, Endpoint: endpoint}
+
+	return context.WithValue(ctx, RequestInfosKey{}, infos), infos
 }
 
 // Request is a typesafe shortcut for making a request.
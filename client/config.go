@@ -3,9 +3,11 @@ package client
 import (
 	"context"
 	"crypto/tls"
+	"slices"
 	"time"
 
 	"github.com/go-orb/go-orb/config"
+	"github.com/go-orb/go-orb/registry"
 )
 
 //nolint:gochecknoglobals
@@ -127,6 +129,12 @@ type Config struct {
 
 	// Region registry filter.
 	Region string `json:"region" yaml:"region"`
+
+	// Zone of the client, nodes in the same zone are preferred, see ResolveNodes.
+	Zone string `json:"zone,omitempty" yaml:"zone,omitempty"`
+
+	// FallbackRegions are used in this order when Region has no node, see ResolveNodes.
+	FallbackRegions []string `json:"fallbackRegions,omitempty" yaml:"fallbackRegions,omitempty"`
 }
 
 func (c *Config) config() *Config {
@@ -262,6 +270,22 @@ func WithClientRegion(r string) Option {
 	}
 }
 
+// WithClientZone sets the zone of the client, nodes in this zone are preferred.
+func WithClientZone(z string) Option {
+	return func(cfg ConfigType) {
+		c := cfg.config()
+		c.Zone = z
+	}
+}
+
+// WithClientFallbackRegions sets the regions to use in order when Region has no node.
+func WithClientFallbackRegions(r ...string) Option {
+	return func(cfg ConfigType) {
+		c := cfg.config()
+		c.FallbackRegions = r
+	}
+}
+
 // NewConfig generates a new config with all the defaults.
 func NewConfig(opts ...Option) Config {
 	cfg := Config{
@@ -337,11 +361,62 @@ type CallOptions struct {
 
 	// Region registry filter.
 	Region string
+
+	// Zone of the caller, nodes in the same zone are preferred.
+	Zone string
+
+	// FallbackRegions are used in this order when Region has no node.
+	FallbackRegions []string
+
+	// HashKey is the key used by SelectHashNode, see WithHashKey.
+	HashKey string
+
+	// Hedges is the maximum number of hedged requests, 0 disables hedging.
+	// It needs the "hedge" middleware, only enable it for read-only endpoints.
+	Hedges int
+
+	// Middlewares overrides the middlewares selected by the config when it's not nil,
+	// only the configured middlewares with these names are used, see MiddlewareChain.
+	Middlewares []string
+	// SkipMiddlewares are the names of configured middlewares which aren't used.
+	SkipMiddlewares []string
 }
 
 // CallOption used by Call or Stream.
 type CallOption func(*CallOptions)
 
+// NewCallOptions returns the CallOptions for a call with the defaults from cfg,
+// opts get applied on top of them. Client implementations should use it to build
+// the CallOptions, so the locality settings Zone and FallbackRegions reach ResolveNodes.
+func NewCallOptions(cfg *Config, opts ...CallOption) *CallOptions {
+	co := &CallOptions{
+		ContentType:         cfg.ContentType,
+		PreferredTransports: slices.Clone(cfg.PreferredTransports),
+		AnyTransport:        cfg.AnyTransport,
+		Selector:            cfg.Selector,
+		DialTimeout:         time.Duration(cfg.DialTimeout),
+		ConnectionTimeout:   time.Duration(cfg.ConnectionTimeout),
+		RequestTimeout:      time.Duration(cfg.RequestTimeout),
+		StreamTimeout:       time.Duration(cfg.StreamTimeout),
+		TLSConfig:           cfg.TLSConfig,
+		Namespace:           cfg.Namespace,
+		Region:              cfg.Region,
+		Zone:                cfg.Zone,
+		FallbackRegions:     slices.Clone(cfg.FallbackRegions),
+		ConnClose:           DefaultConnClose,
+		RetryFunc:           DefaultCallOptionsRetryFunc,
+		Retries:             DefaultCallOptionsRetries,
+		MaxCallRecvMsgSize:  DefaultMaxCallRecvMsgSize,
+		MaxCallSendMsgSize:  DefaultMaxCallSendMsgSize,
+	}
+
+	for _, o := range opts {
+		o(co)
+	}
+
+	return co
+}
+
 // Call Options.
 
 // WithContentType set's the call's Content-Type.
@@ -474,3 +549,54 @@ func WithRegion(r string) CallOption {
 		o.Region = r
 	}
 }
+
+// WithZone sets the zone of the caller, nodes in this zone are preferred.
+func WithZone(z string) CallOption {
+	return func(o *CallOptions) {
+		o.Zone = z
+	}
+}
+
+// WithFallbackRegions sets the regions to use in order when the region has no node.
+func WithFallbackRegions(r ...string) CallOption {
+	return func(o *CallOptions) {
+		o.FallbackRegions = r
+	}
+}
+
+// WithHashKey makes requests with the same key land on the same node, it sets the
+// selector of the call to SelectHashNode with key. It replaces the selector of an
+// earlier WithSelector and gets replaced by a later one, which doesn't get the key.
+func WithHashKey(key string) CallOption {
+	return func(o *CallOptions) {
+		o.HashKey = key
+		o.Selector = func(ctx context.Context, service string, nodes []registry.ServiceNode) (registry.ServiceNode, error) {
+			return SelectHashNode(ContextWithHashKey(ctx, key), service, nodes)
+		}
+	}
+}
+
+// WithHedging sends up to n additional requests to other nodes when the first
+// one is slow, the first reply wins. Only use it for read-only endpoints.
+func WithHedging(n int) CallOption {
+	return func(o *CallOptions) {
+		o.Hedges = n
+	}
+}
+
+// WithMiddlewares uses only the configured middlewares with these names for the call.
+// Of the middlewares configured with a name the ones whose patterns select the call are
+// used, if none does a single one is used regardless of its patterns, preferably one
+// without include patterns. Without names no middleware is used.
+func WithMiddlewares(names ...string) CallOption {
+	return func(o *CallOptions) {
+		o.Middlewares = append([]string{}, names...)
+	}
+}
+
+// WithoutMiddlewares skips the configured middlewares with these names for the call.
+func WithoutMiddlewares(names ...string) CallOption {
+	return func(o *CallOptions) {
+		o.SkipMiddlewares = append(o.SkipMiddlewares, names...)
+	}
+}
@@ -0,0 +1,49 @@
+package client
+
+import (
+	"context"
+
+	"github.com/go-orb/go-orb/registry"
+)
+
+// NodeFilter returns false for nodes which should not be selected, for example
+// because a circuit breaker is open for them.
+type NodeFilter func(service string, node registry.ServiceNode) bool
+
+type nodeFiltersKey struct{}
+
+// ContextWithNodeFilter adds a NodeFilter to ctx, ResolveNodes skips the nodes it rejects.
+// This way middlewares can feed back into node selection.
+func ContextWithNodeFilter(ctx context.Context, filter NodeFilter) context.Context {
+	filters, _ := ctx.Value(nodeFiltersKey{}).([]NodeFilter) //nolint:errcheck
+
+	// Copy, so parallel requests with the same parent don't share the slice.
+	next := make([]NodeFilter, 0, len(filters)+1)
+	next = append(next, filters...)
+	next = append(next, filter)
+
+	return context.WithValue(ctx, nodeFiltersKey{}, next)
+}
+
+// FilterNodes returns the nodes which pass all NodeFilters of ctx.
+func FilterNodes(ctx context.Context, service string, nodes []registry.ServiceNode) []registry.ServiceNode {
+	filters, ok := ctx.Value(nodeFiltersKey{}).([]NodeFilter)
+	if !ok || len(filters) == 0 {
+		return nodes
+	}
+
+	result := make([]registry.ServiceNode, 0, len(nodes))
+
+outer:
+	for _, node := range nodes {
+		for _, filter := range filters {
+			if !filter(service, node) {
+				continue outer
+			}
+		}
+
+		result = append(result, node)
+	}
+
+	return result
+}
@@ -0,0 +1,135 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"github.com/go-orb/go-orb/registry"
+)
+
+//nolint:gochecknoglobals
+var (
+	// DefaultZoneMetadataKey is the ServiceNode.Metadata key which contains the zone of a node.
+	DefaultZoneMetadataKey = "zone"
+)
+
+// Locality tiers, they tell which tier served a request, see RequestInfos.Tier.
+const (
+	// TierAny is used when locality is disabled, nodes get taken from the configured region only.
+	TierAny = "any"
+	// TierZone are nodes in the callers region and zone.
+	TierZone = "zone"
+	// TierRegion are nodes in the callers region.
+	TierRegion = "region"
+	// TierFallback are nodes in one of the fallback regions.
+	TierFallback = "fallback"
+)
+
+// LocalityEnabled returns true if locality aware resolving is enabled in opts,
+// which is the case if a Zone or FallbackRegions are set.
+func (o *CallOptions) LocalityEnabled() bool {
+	return o.Zone != "" || len(o.FallbackRegions) > 0
+}
+
+// ResolveNodes returns the nodes of service from the registry with the given schemes.
+//
+// Without locality it returns the nodes of opts.Namespace and opts.Region. With
+// locality it prefers nodes in the zone opts.Zone, then nodes in opts.Region and
+// then the nodes of opts.FallbackRegions in order.
+//
+// Nodes rejected by a NodeFilter of ctx are skipped, see ContextWithNodeFilter.
+// The tier which served the nodes gets written into the RequestInfos of ctx.
+// Returns ErrNoNodeFound if there's no node in any tier.
+func ResolveNodes(
+	ctx context.Context,
+	reg registry.Registry,
+	service string,
+	schemes []string,
+	opts *CallOptions,
+) ([]registry.ServiceNode, error) {
+	if !opts.LocalityEnabled() {
+		nodes, err := getNodes(ctx, reg, opts.Namespace, opts.Region, service, schemes)
+		if err != nil {
+			return nil, err
+		}
+
+		setTier(ctx, TierAny, opts.Region)
+
+		return nodes, nil
+	}
+
+	nodes, err := getNodes(ctx, reg, opts.Namespace, opts.Region, service, schemes)
+	if err != nil && !errors.Is(err, ErrNoNodeFound) {
+		return nil, err
+	}
+
+	if opts.Zone != "" {
+		zoneNodes := slices.DeleteFunc(slices.Clone(nodes), func(n registry.ServiceNode) bool {
+			return n.Metadata[DefaultZoneMetadataKey] != opts.Zone
+		})
+
+		if len(zoneNodes) > 0 {
+			setTier(ctx, TierZone, opts.Region)
+			return zoneNodes, nil
+		}
+	}
+
+	if len(nodes) > 0 {
+		setTier(ctx, TierRegion, opts.Region)
+		return nodes, nil
+	}
+
+	for _, region := range opts.FallbackRegions {
+		if region == opts.Region {
+			continue
+		}
+
+		nodes, err := getNodes(ctx, reg, opts.Namespace, region, service, schemes)
+		if errors.Is(err, ErrNoNodeFound) {
+			continue
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		setTier(ctx, TierFallback, region)
+
+		return nodes, nil
+	}
+
+	return nil, ErrNoNodeFound
+}
+
+// getNodes returns the nodes from the registry which pass the NodeFilters of ctx,
+// it returns ErrNoNodeFound instead of registry.ErrNotFound.
+func getNodes(
+	ctx context.Context,
+	reg registry.Registry,
+	namespace, region, service string,
+	schemes []string,
+) ([]registry.ServiceNode, error) {
+	nodes, err := reg.GetService(ctx, namespace, region, service, schemes)
+	if errors.Is(err, registry.ErrNotFound) {
+		return nil, ErrNoNodeFound
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	nodes = FilterNodes(ctx, service, nodes)
+	if len(nodes) == 0 {
+		return nil, ErrNoNodeFound
+	}
+
+	return nodes, nil
+}
+
+func setTier(ctx context.Context, tier, region string) {
+	if v, ok := ctx.Value(RequestInfosKey{}).(*RequestInfos); ok && v != nil {
+		v.Tier = tier
+		v.Region = region
+	}
+}
@@ -0,0 +1,106 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/go-orb/go-orb/log"
+	"github.com/go-orb/go-orb/registry"
+	"github.com/go-orb/go-orb/registry/memory"
+)
+
+func newTestRegistry(t *testing.T, nodes ...registry.ServiceNode) registry.Registry {
+	t.Helper()
+
+	reg := memory.New(memory.NewConfig(), log.Logger{Logger: slog.New(slog.NewTextHandler(io.Discard, nil))})
+	if err := reg.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { _ = reg.Stop(context.Background()) }) //nolint:errcheck
+
+	for _, node := range nodes {
+		if err := reg.Register(context.Background(), node); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return reg
+}
+
+func localNode(region, zone, address string) registry.ServiceNode {
+	return registry.ServiceNode{
+		Name:     "svc",
+		Version:  "v1",
+		Node:     "grpc",
+		Region:   region,
+		Scheme:   "grpc",
+		Address:  address,
+		Metadata: map[string]string{DefaultZoneMetadataKey: zone},
+	}
+}
+
+func TestNewCallOptions(t *testing.T) {
+	cfg := NewConfig(WithClientRegion("eu"), WithClientZone("a"), WithClientFallbackRegions("us", "ap"))
+
+	opts := NewCallOptions(&cfg, WithZone("b"))
+	if opts.Region != "eu" || opts.Zone != "b" || len(opts.FallbackRegions) != 2 || opts.FallbackRegions[0] != "us" {
+		t.Fatalf("expected the locality settings of the config and the call, got %+v", opts)
+	}
+
+	// The call options don't share the fallback regions with the config.
+	opts.FallbackRegions[0] = "sa"
+
+	if cfg.FallbackRegions[0] != "us" {
+		t.Fatal("expected the config to be untouched")
+	}
+}
+
+func TestResolveNodes(t *testing.T) {
+	reg := newTestRegistry(t,
+		localNode("eu", "a", "127.0.0.1:1"),
+		localNode("eu", "b", "127.0.0.1:2"),
+		localNode("us", "a", "127.0.0.1:3"),
+		localNode("ap", "a", "127.0.0.1:4"),
+	)
+
+	for _, tc := range []struct {
+		name    string
+		opts    []Option
+		tier    string
+		region  string
+		address string
+		err     error
+	}{
+		{"zone", []Option{WithClientRegion("eu"), WithClientZone("b")}, TierZone, "eu", "127.0.0.1:2", nil},
+		{"region", []Option{WithClientRegion("eu"), WithClientZone("c")}, TierRegion, "eu", "", nil},
+		{"no locality", []Option{WithClientRegion("eu")}, TierAny, "eu", "", nil},
+		{"fallback", []Option{WithClientRegion("sa"), WithClientFallbackRegions("eu", "us")}, TierFallback, "eu", "", nil},
+		{"fallback order", []Option{WithClientRegion("sa"), WithClientFallbackRegions("af", "ap", "us")}, TierFallback, "ap", "127.0.0.1:4", nil},
+		{"no node", []Option{WithClientRegion("sa"), WithClientFallbackRegions("af")}, "", "", "", ErrNoNodeFound},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := NewConfig(tc.opts...)
+			infos := &RequestInfos{}
+			ctx := context.WithValue(context.Background(), RequestInfosKey{}, infos)
+
+			nodes, err := ResolveNodes(ctx, reg, "svc", nil, NewCallOptions(&cfg))
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("expected %v, got %v", tc.err, err)
+			}
+
+			if infos.Tier != tc.tier || infos.Region != tc.region {
+				t.Fatalf("expected tier %q in %q, got %q in %q", tc.tier, tc.region, infos.Tier, infos.Region)
+			}
+
+			for _, node := range nodes {
+				if node.Region != tc.region || tc.address != "" && node.Address != tc.address {
+					t.Fatalf("expected nodes from %q %s, got %s in %s", tc.region, tc.address, node.Address, node.Region)
+				}
+			}
+		})
+	}
+}
@@ -12,13 +12,25 @@ import (
 const MiddlewareComponentType = "middleware"
 
 // MiddlewareConfig is the basic config for every middleware.
+//
+// Include and Exclude select the requests a middleware handles, they are regular
+// expressions matched against "service/endpoint". Configure a middleware multiple
+// times to use different configs for different services, see MiddlewareChain.
 type MiddlewareConfig struct {
 	Name string `json:"name" yaml:"name"`
+
+	// Include limits the middleware to the matching requests, it handles all if empty.
+	Include []string `json:"include,omitempty" yaml:"include,omitempty"`
+	// Exclude skips the middleware for the matching requests, even if they're included.
+	Exclude []string `json:"exclude,omitempty" yaml:"exclude,omitempty"`
 }
 
 // MiddlewareRequestHandler is the middleware handler for client.Request without a codec in between.
 type MiddlewareRequestHandler func(ctx context.Context, service string, endpoint string, req any, result any, opts *CallOptions) error
 
+// MiddlewareStreamHandler is the middleware handler for client.Stream, it opens the stream.
+type MiddlewareStreamHandler func(ctx context.Context, service string, endpoint string, opts *CallOptions) (StreamIface[any, any], error)
+
 // Middleware is the middleware for clients.
 type Middleware interface {
 	types.Component
@@ -28,6 +40,28 @@ type Middleware interface {
 	) MiddlewareRequestHandler
 }
 
+// StreamMiddleware is implemented by middlewares which handle streams as well.
+//
+// Stream wraps the creation of a stream, to intercept Send, Recv, CloseSend
+// and Close it returns a wrapped stream, see InterceptStream.
+type StreamMiddleware interface {
+	Stream(
+		next MiddlewareStreamHandler,
+	) MiddlewareStreamHandler
+}
+
+// ChainStream wraps handler with the middlewares which implement StreamMiddleware,
+// the first middleware is the outermost like for requests. Client plugins call it in Stream.
+func ChainStream(handler MiddlewareStreamHandler, mws ...Middleware) MiddlewareStreamHandler {
+	for i := len(mws) - 1; i >= 0; i-- {
+		if sm, ok := mws[i].(StreamMiddleware); ok {
+			handler = sm.Stream(handler)
+		}
+	}
+
+	return handler
+}
+
 // MiddlewareFactory is used to create a new client Middleware.
 type MiddlewareFactory func(config map[string]any, client Type, logger log.Logger) (Middleware, error)
 
@@ -0,0 +1,136 @@
+// Package auth provides a client middleware which attaches credentials to the
+// outgoing metadata of requests and streams, the "auth" server middleware verifies them.
+package auth
+
+import (
+	"context"
+	"errors"
+
+	"github.com/go-orb/go-orb/auth"
+	"github.com/go-orb/go-orb/client"
+	"github.com/go-orb/go-orb/config"
+	"github.com/go-orb/go-orb/log"
+	"github.com/go-orb/go-orb/util/metadata"
+)
+
+// Name is the name of this middleware.
+const Name = "auth"
+
+var (
+	_ client.Middleware       = (*Middleware)(nil)
+	_ client.StreamMiddleware = (*Middleware)(nil)
+)
+
+func init() {
+	client.Middlewares.Add(Name, Provide)
+}
+
+// Config is the config of the client auth middleware, it sends static credentials.
+type Config struct {
+	// Token is sent as bearer token.
+	Token string `json:"token,omitempty" yaml:"token,omitempty"`
+	// APIKey is sent in the metadata key auth.APIKeyKey.
+	APIKey string `json:"apiKey,omitempty" yaml:"apiKey,omitempty"`
+}
+
+// Middleware is the client auth middleware.
+type Middleware struct {
+	credentials []auth.Credentials
+}
+
+// Provide creates an auth middleware with the static credentials from the config.
+func Provide(configData map[string]any, _ client.Type, _ log.Logger) (client.Middleware, error) {
+	cfg := Config{}
+
+	if err := config.Parse(nil, "", configData, &cfg); err != nil && !errors.Is(err, config.ErrNoSuchKey) {
+		return nil, err
+	}
+
+	var credentials []auth.Credentials
+	if cfg.Token != "" {
+		credentials = append(credentials, auth.BearerToken(cfg.Token))
+	}
+
+	if cfg.APIKey != "" {
+		credentials = append(credentials, auth.APIKey(cfg.APIKey))
+	}
+
+	return New(credentials...), nil
+}
+
+// ProvideWithCredentials returns a factory whose middlewares send credentials instead of
+// the static ones from the config, e.g. an auth.CredentialsFunc which refreshes tokens.
+func ProvideWithCredentials(credentials ...auth.Credentials) client.MiddlewareFactory {
+	return func(_ map[string]any, _ client.Type, _ log.Logger) (client.Middleware, error) {
+		return New(credentials...), nil
+	}
+}
+
+// New creates a new auth middleware.
+func New(credentials ...auth.Credentials) *Middleware {
+	return &Middleware{credentials: credentials}
+}
+
+// Start is a no-op.
+func (m *Middleware) Start(_ context.Context) error {
+	return nil
+}
+
+// Stop is a no-op.
+func (m *Middleware) Stop(_ context.Context) error {
+	return nil
+}
+
+// Type returns the component type.
+func (m *Middleware) Type() string {
+	return client.MiddlewareComponentType
+}
+
+// String returns the name of this middleware.
+func (m *Middleware) String() string {
+	return Name
+}
+
+// Request attaches the credentials to the request.
+func (m *Middleware) Request(next client.MiddlewareRequestHandler) client.MiddlewareRequestHandler {
+	return func(ctx context.Context, service string, endpoint string, req any, result any, opts *client.CallOptions) error {
+		ctx, err := m.apply(ctx)
+		if err != nil {
+			return err
+		}
+
+		return next(ctx, service, endpoint, req, result, opts)
+	}
+}
+
+// Stream attaches the credentials to the stream.
+func (m *Middleware) Stream(next client.MiddlewareStreamHandler) client.MiddlewareStreamHandler {
+	return func(ctx context.Context, service string, endpoint string, opts *client.CallOptions) (client.StreamIface[any, any], error) {
+		ctx, err := m.apply(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		return next(ctx, service, endpoint, opts)
+	}
+}
+
+// apply returns a copy of ctx with the credentials in a copy of its outgoing metadata.
+func (m *Middleware) apply(ctx context.Context) (context.Context, error) {
+	if len(m.credentials) == 0 {
+		return ctx, nil
+	}
+
+	md := metadata.New(nil)
+	if parent, ok := metadata.OutgoingMD(ctx); ok {
+		md = parent.Clone()
+	}
+
+	for _, c := range m.credentials {
+		if err := c.Apply(ctx, md); err != nil {
+			return nil, err
+		}
+	}
+
+	return metadata.NewOutgoing(ctx, md), nil
+}
@@ -0,0 +1,92 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/go-orb/go-orb/auth"
+	"github.com/go-orb/go-orb/client"
+	"github.com/go-orb/go-orb/util/metadata"
+)
+
+// outgoing runs a request through m and returns the outgoing metadata the transport gets.
+func outgoing(ctx context.Context, t *testing.T, m *Middleware) *metadata.MD {
+	t.Helper()
+
+	var md *metadata.MD
+
+	err := m.Request(func(ctx context.Context, _, _ string, _, _ any, _ *client.CallOptions) error {
+		md, _ = metadata.OutgoingMD(ctx)
+		return nil
+	})(ctx, "svc", "ep", nil, nil, &client.CallOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return md
+}
+
+func TestRequest(t *testing.T) {
+	parent := metadata.Pairs("tenant", "a")
+	ctx := metadata.NewOutgoing(context.Background(), parent)
+
+	md := outgoing(ctx, t, New(auth.BearerToken("token"), auth.APIKey("key")))
+	if md == nil {
+		t.Fatal("expected outgoing metadata")
+	}
+
+	for key, value := range map[string]string{
+		auth.AuthorizationKey: "Bearer token",
+		auth.APIKeyKey:        "key",
+		"tenant":              "a",
+	} {
+		if v, _ := md.Value(key); v != value {
+			t.Fatalf("expected %s to be %q, got %q", key, value, v)
+		}
+	}
+
+	// The callers metadata is cloned, not modified.
+	if _, ok := parent.Value(auth.AuthorizationKey); ok || parent.Len() != 1 {
+		t.Fatalf("expected the callers metadata to be untouched, got %v", parent.Map())
+	}
+
+	// Without credentials the context is passed on as is.
+	if md := outgoing(ctx, t, New()); md != parent {
+		t.Fatal("expected the callers metadata without credentials")
+	}
+}
+
+func TestCredentialsError(t *testing.T) {
+	errRefresh := errors.New("refresh failed")
+
+	m := New(auth.CredentialsFunc(func(context.Context, *metadata.MD) error {
+		return errRefresh
+	}))
+
+	called := false
+
+	err := m.Request(func(context.Context, string, string, any, any, *client.CallOptions) error {
+		called = true
+		return nil
+	})(context.Background(), "svc", "ep", nil, nil, &client.CallOptions{})
+	if !errors.Is(err, errRefresh) || called {
+		t.Fatalf("expected the request to fail with the credentials error, got %v", err)
+	}
+}
+
+func TestStream(t *testing.T) {
+	var md *metadata.MD
+
+	_, err := New(auth.BearerToken("token")).Stream(func(ctx context.Context, _, _ string, _ *client.CallOptions) (client.StreamIface[any, any], error) {
+		md, _ = metadata.OutgoingMD(ctx)
+		return nil, nil //nolint:nilnil
+	})(context.Background(), "svc", "ep", &client.CallOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if v, _ := md.Value(auth.AuthorizationKey); v != "Bearer token" {
+		t.Fatalf("expected the token on the stream, got %q", v)
+	}
+}
@@ -0,0 +1,162 @@
+package circuitbreaker
+
+import (
+	"sync"
+	"time"
+)
+
+// State is the state of a breaker.
+type State int
+
+const (
+	// StateClosed lets all requests through.
+	StateClosed State = iota
+	// StateOpen rejects all requests.
+	StateOpen
+	// StateHalfOpen lets a limited number of probes through.
+	StateHalfOpen
+)
+
+// String returns the human readable state.
+func (s State) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// breaker is the state machine for a single service or node.
+type breaker struct {
+	config *Config
+
+	mu          sync.Mutex
+	state       State
+	windowStart time.Time
+	requests    int
+	failures    int
+	openedAt    time.Time
+	probes      int
+	successes   int
+}
+
+func newBreaker(cfg *Config) *breaker {
+	return &breaker{
+		config:      cfg,
+		windowStart: time.Now(),
+	}
+}
+
+// current returns the state, an open breaker turns half-open after the OpenTimeout.
+// The caller must hold b.mu.
+func (b *breaker) current(now time.Time) State {
+	if b.state == StateOpen && now.Sub(b.openedAt) >= time.Duration(b.config.OpenTimeout) {
+		b.state = StateHalfOpen
+		b.probes = 0
+		b.successes = 0
+	}
+
+	return b.state
+}
+
+// State returns the current state.
+func (b *breaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.current(time.Now())
+}
+
+// allow returns true if a request may pass, in half-open state it takes a probe slot.
+func (b *breaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.current(time.Now()) {
+	case StateOpen:
+		return false
+	case StateHalfOpen:
+		if b.probes >= b.config.HalfOpenRequests {
+			return false
+		}
+
+		b.probes++
+
+		return true
+	default:
+		return true
+	}
+}
+
+// release gives back a probe slot taken by allow which hasn't been used for a request.
+func (b *breaker) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == StateHalfOpen && b.probes > 0 {
+		b.probes--
+	}
+}
+
+// record records the outcome of a request, it returns the state before and after.
+func (b *breaker) record(failed bool) (State, State) {
+	now := time.Now()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	from := b.current(now)
+
+	switch from {
+	case StateOpen:
+		return from, from
+	case StateHalfOpen:
+		if failed {
+			b.open(now)
+			return from, b.state
+		}
+
+		b.successes++
+		if b.successes >= b.config.HalfOpenRequests {
+			b.close(now)
+		}
+
+		return from, b.state
+	case StateClosed:
+	}
+
+	if now.Sub(b.windowStart) >= time.Duration(b.config.Window) {
+		b.windowStart = now
+		b.requests = 0
+		b.failures = 0
+	}
+
+	b.requests++
+
+	if failed {
+		b.failures++
+	}
+
+	if b.requests >= b.config.MinRequests && float64(b.failures)/float64(b.requests) >= b.config.FailureRatio {
+		b.open(now)
+	}
+
+	return from, b.state
+}
+
+func (b *breaker) open(now time.Time) {
+	b.state = StateOpen
+	b.openedAt = now
+}
+
+func (b *breaker) close(now time.Time) {
+	b.state = StateClosed
+	b.windowStart = now
+	b.requests = 0
+	b.failures = 0
+}
@@ -0,0 +1,227 @@
+// Package circuitbreaker provides a client middleware which stops sending requests
+// to failing services and nodes for a while.
+//
+// It keeps a breaker per service and per node address. While the breaker of a service
+// is open requests fail fast with orberrors.ErrUnavailable, nodes with an open breaker
+// are skipped by client.ResolveNodes. Half-open breakers let HalfOpenRequests probes
+// through at a time, for nodes the probe slot gets taken when the node is resolved.
+package circuitbreaker
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+
+	"github.com/go-orb/go-orb/client"
+	"github.com/go-orb/go-orb/config"
+	"github.com/go-orb/go-orb/log"
+	"github.com/go-orb/go-orb/registry"
+	"github.com/go-orb/go-orb/util/orberrors"
+)
+
+// Name is the name of this middleware.
+const Name = "circuitbreaker"
+
+var _ client.Middleware = (*Middleware)(nil)
+
+func init() {
+	client.Middlewares.Add(Name, Provide)
+}
+
+// Middleware is the circuit breaker middleware.
+type Middleware struct {
+	config Config
+	logger log.Logger
+
+	mu       sync.Mutex
+	breakers map[string]*breaker
+}
+
+// Provide creates a new circuit breaker middleware from the config.
+func Provide(configData map[string]any, _ client.Type, logger log.Logger) (client.Middleware, error) {
+	cfg := NewConfig()
+
+	if err := config.Parse(nil, "", configData, &cfg); err != nil && !errors.Is(err, config.ErrNoSuchKey) {
+		return nil, err
+	}
+
+	return New(cfg, logger), nil
+}
+
+// New creates a new circuit breaker middleware.
+func New(cfg Config, logger log.Logger) *Middleware {
+	return &Middleware{
+		config:   cfg,
+		logger:   logger.With("middleware", Name),
+		breakers: make(map[string]*breaker),
+	}
+}
+
+// Start is a no-op.
+func (m *Middleware) Start(_ context.Context) error {
+	return nil
+}
+
+// Stop is a no-op.
+func (m *Middleware) Stop(_ context.Context) error {
+	return nil
+}
+
+// Type returns the component type.
+func (m *Middleware) Type() string {
+	return client.MiddlewareComponentType
+}
+
+// String returns the name of this middleware.
+func (m *Middleware) String() string {
+	return Name
+}
+
+// State returns the state of the breaker for service, or for a single node of it if address is not empty.
+func (m *Middleware) State(service, address string) State {
+	b, ok := m.lookup(service, address)
+	if !ok {
+		return StateClosed
+	}
+
+	return b.State()
+}
+
+// Request fails fast while the breaker of the service is open and records the outcome of each request.
+func (m *Middleware) Request(next client.MiddlewareRequestHandler) client.MiddlewareRequestHandler {
+	return func(ctx context.Context, service string, endpoint string, req any, result any, opts *client.CallOptions) error {
+		if !m.get(service, "").allow() {
+			return orberrors.ErrUnavailable.WrapF("circuit breaker for service '%s' is open", service)
+		}
+
+		// The client fills in the address of the node, which may be a probe of a half-open breaker.
+		ctx, infos := client.ContextWithRequestInfos(ctx, service, endpoint)
+
+		p := &probes{m: m, taken: make(map[string]*breaker)}
+		ctx = client.ContextWithNodeFilter(ctx, p.filter)
+
+		err := next(ctx, service, endpoint, req, result, opts)
+		failed := IsFailure(err)
+
+		m.record(service, "", failed)
+
+		if infos.Address != "" {
+			m.record(service, infos.Address, failed)
+		}
+
+		p.release(infos.Address)
+
+		return err
+	}
+}
+
+// IsFailure returns true if err counts as failure of the service,
+// which are server errors and timeouts but not canceled requests or client errors.
+func IsFailure(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	code := orberrors.From(err).Code
+
+	return code >= http.StatusInternalServerError || code == http.StatusRequestTimeout
+}
+
+// probes is the client.NodeFilter of a request, it skips nodes with an open breaker
+// and takes the probe slot of half-open ones, so they get one probe at a time.
+type probes struct {
+	m *Middleware
+
+	mu    sync.Mutex
+	taken map[string]*breaker
+}
+
+func (p *probes) filter(service string, node registry.ServiceNode) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	// Resolved again, e.g. by a retry.
+	if _, ok := p.taken[node.Address]; ok {
+		return true
+	}
+
+	b, ok := p.m.lookup(service, node.Address)
+	if !ok {
+		return true
+	}
+
+	switch b.State() {
+	case StateOpen:
+		return false
+	case StateHalfOpen:
+		if !b.allow() {
+			return false
+		}
+
+		p.taken[node.Address] = b
+
+		return true
+	default:
+		return true
+	}
+}
+
+// release gives back the probe slots of the nodes which haven't served the request.
+func (p *probes) release(used string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for address, b := range p.taken {
+		if address != used {
+			b.release()
+		}
+	}
+}
+
+func (m *Middleware) record(service, address string, failed bool) {
+	b := m.get(service, address)
+
+	from, to := b.record(failed)
+	if from == to {
+		return
+	}
+
+	if address == "" {
+		m.logger.Warn("circuit breaker changed state", "service", service, "from", from.String(), "to", to.String())
+	} else {
+		m.logger.Warn("circuit breaker changed state", "service", service, "address", address, "from", from.String(), "to", to.String())
+	}
+}
+
+func (m *Middleware) lookup(service, address string) (*breaker, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	b, ok := m.breakers[key(service, address)]
+
+	return b, ok
+}
+
+func (m *Middleware) get(service, address string) *breaker {
+	k := key(service, address)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	b, ok := m.breakers[k]
+	if !ok {
+		b = newBreaker(&m.config)
+		m.breakers[k] = b
+	}
+
+	return b
+}
+
+func key(service, address string) string {
+	if address == "" {
+		return service
+	}
+
+	return service + "@" + address
+}
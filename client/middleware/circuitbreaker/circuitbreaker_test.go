@@ -0,0 +1,205 @@
+package circuitbreaker
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/go-orb/go-orb/client"
+	"github.com/go-orb/go-orb/log"
+	"github.com/go-orb/go-orb/registry"
+	"github.com/go-orb/go-orb/util/orberrors"
+)
+
+func TestBreaker(t *testing.T) {
+	m := New(
+		NewConfig(WithMinRequests(4), WithFailureRatio(0.5), WithOpenTimeout(50*time.Millisecond)),
+		log.Logger{Logger: slog.New(slog.NewTextHandler(io.Discard, nil))},
+	)
+
+	fail := true
+	handler := m.Request(func(_ context.Context, _, _ string, _, _ any, _ *client.CallOptions) error {
+		if fail {
+			return orberrors.ErrUnavailable
+		}
+
+		return nil
+	})
+
+	call := func() error {
+		return handler(context.Background(), "svc", "ep", nil, nil, &client.CallOptions{})
+	}
+
+	for range 4 {
+		_ = call() //nolint:errcheck
+	}
+
+	if s := m.State("svc", ""); s != StateOpen {
+		t.Fatalf("expected open breaker, got %s", s)
+	}
+
+	if err := call(); !errors.Is(err, orberrors.ErrUnavailable) {
+		t.Fatalf("expected fail fast, got %v", err)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+
+	fail = false
+
+	if err := call(); err != nil {
+		t.Fatalf("expected probe to pass, got %v", err)
+	}
+
+	if s := m.State("svc", ""); s != StateClosed {
+		t.Fatalf("expected closed breaker, got %s", s)
+	}
+
+	if IsFailure(orberrors.ErrBadRequest) || IsFailure(context.Canceled) {
+		t.Fatal("client errors must not count as failures")
+	}
+}
+
+// nodeHandler resolves the node with the address req like a client does, it fills in the
+// request infos and fails with ErrNoNodeFound if the node got filtered.
+func nodeHandler(fail func(address string) error) client.MiddlewareRequestHandler {
+	return func(ctx context.Context, service, _ string, req, _ any, _ *client.CallOptions) error {
+		address, _ := req.(string) //nolint:errcheck
+
+		if len(client.FilterNodes(ctx, service, []registry.ServiceNode{{Address: address}})) == 0 {
+			return client.ErrNoNodeFound
+		}
+
+		if infos, ok := ctx.Value(client.RequestInfosKey{}).(*client.RequestInfos); ok {
+			infos.Address = address
+		}
+
+		return fail(address)
+	}
+}
+
+// openNode opens the breaker of the node "b" and keeps the service breaker closed.
+func openNode(t *testing.T, m *Middleware) client.MiddlewareRequestHandler {
+	t.Helper()
+
+	handler := m.Request(nodeHandler(func(address string) error {
+		if address == "b" {
+			return orberrors.ErrUnavailable
+		}
+
+		return nil
+	}))
+
+	for _, address := range []string{"a", "a", "a", "b", "b"} {
+		_ = handler(context.Background(), "svc", "ep", address, nil, &client.CallOptions{}) //nolint:errcheck
+	}
+
+	if s := m.State("svc", "b"); s != StateOpen {
+		t.Fatalf("expected the node breaker to be open, got %s", s)
+	}
+
+	if s := m.State("svc", ""); s != StateClosed {
+		t.Fatalf("expected the service breaker to be closed, got %s", s)
+	}
+
+	return handler
+}
+
+func newNodeTestMiddleware() *Middleware {
+	return New(
+		NewConfig(WithMinRequests(2), WithFailureRatio(0.6), WithOpenTimeout(50*time.Millisecond)),
+		log.Logger{Logger: slog.New(slog.NewTextHandler(io.Discard, nil))},
+	)
+}
+
+func TestNodeBreaker(t *testing.T) {
+	// The context of the callers has no request infos, the middleware adds them.
+	handler := openNode(t, newNodeTestMiddleware())
+
+	if err := handler(context.Background(), "svc", "ep", "b", nil, &client.CallOptions{}); !errors.Is(err, client.ErrNoNodeFound) {
+		t.Fatalf("expected the open node to be skipped, got %v", err)
+	}
+
+	if err := handler(context.Background(), "svc", "ep", "a", nil, &client.CallOptions{}); err != nil {
+		t.Fatalf("expected the other node to be used, got %v", err)
+	}
+}
+
+func TestHalfOpenProbe(t *testing.T) {
+	m := newNodeTestMiddleware()
+	openNode(t, m)
+
+	time.Sleep(60 * time.Millisecond)
+
+	if s := m.State("svc", "b"); s != StateHalfOpen {
+		t.Fatalf("expected the node breaker to be half-open, got %s", s)
+	}
+
+	probing := make(chan struct{})
+	done := make(chan struct{})
+
+	handler := m.Request(nodeHandler(func(string) error {
+		select {
+		case probing <- struct{}{}:
+			<-done
+		default:
+		}
+
+		return nil
+	}))
+
+	probe := make(chan error)
+
+	go func() {
+		probe <- handler(context.Background(), "svc", "ep", "b", nil, &client.CallOptions{})
+	}()
+
+	<-probing
+
+	// Only one probe is in flight.
+	for range 3 {
+		if err := handler(context.Background(), "svc", "ep", "b", nil, &client.CallOptions{}); !errors.Is(err, client.ErrNoNodeFound) {
+			t.Fatalf("expected the node to be skipped while its probe is in flight, got %v", err)
+		}
+	}
+
+	close(done)
+
+	if err := <-probe; err != nil {
+		t.Fatal(err)
+	}
+
+	if s := m.State("svc", "b"); s != StateClosed {
+		t.Fatalf("expected the successful probe to close the breaker, got %s", s)
+	}
+}
+
+func TestHalfOpenRelease(t *testing.T) {
+	m := newNodeTestMiddleware()
+	openNode(t, m)
+
+	time.Sleep(60 * time.Millisecond)
+
+	// The node gets resolved, but the request fails before it's sent to it.
+	handler := m.Request(func(ctx context.Context, service, _ string, _, _ any, _ *client.CallOptions) error {
+		client.FilterNodes(ctx, service, []registry.ServiceNode{{Address: "b"}})
+		return orberrors.ErrBadRequest
+	})
+
+	if err := handler(context.Background(), "svc", "ep", nil, nil, &client.CallOptions{}); !errors.Is(err, orberrors.ErrBadRequest) {
+		t.Fatalf("expected the error of the handler, got %v", err)
+	}
+
+	// The unused probe slot has been given back.
+	handler = m.Request(nodeHandler(func(string) error { return nil }))
+
+	if err := handler(context.Background(), "svc", "ep", "b", nil, &client.CallOptions{}); err != nil {
+		t.Fatalf("expected the node to get a probe, got %v", err)
+	}
+
+	if s := m.State("svc", "b"); s != StateClosed {
+		t.Fatalf("expected the successful probe to close the breaker, got %s", s)
+	}
+}
@@ -0,0 +1,91 @@
+package circuitbreaker
+
+import (
+	"time"
+
+	"github.com/go-orb/go-orb/config"
+)
+
+//nolint:gochecknoglobals
+var (
+	// DefaultFailureRatio is the ratio of failed requests which opens the breaker.
+	DefaultFailureRatio = 0.5
+	// DefaultMinRequests is the number of requests in a window before the breaker can open.
+	DefaultMinRequests = 10
+	// DefaultWindow is the time window in which requests are counted.
+	DefaultWindow = config.Duration(10 * time.Second)
+	// DefaultOpenTimeout is the time a breaker stays open before it lets probes through.
+	DefaultOpenTimeout = config.Duration(30 * time.Second)
+	// DefaultHalfOpenRequests is the number of successful probes which close the breaker.
+	DefaultHalfOpenRequests = 1
+)
+
+// Config is the config of the circuit breaker middleware.
+type Config struct {
+	// FailureRatio is the ratio of failed requests which opens the breaker.
+	FailureRatio float64 `json:"failureRatio,omitempty" yaml:"failureRatio,omitempty"`
+	// MinRequests is the number of requests in a window before the breaker can open.
+	MinRequests int `json:"minRequests,omitempty" yaml:"minRequests,omitempty"`
+	// Window is the time window in which requests are counted.
+	Window config.Duration `json:"window,omitempty" yaml:"window,omitempty"`
+	// OpenTimeout is the time a breaker stays open before it lets probes through.
+	OpenTimeout config.Duration `json:"openTimeout,omitempty" yaml:"openTimeout,omitempty"`
+	// HalfOpenRequests is the number of concurrent probes in half-open state,
+	// that many successful probes close the breaker.
+	HalfOpenRequests int `json:"halfOpenRequests,omitempty" yaml:"halfOpenRequests,omitempty"`
+}
+
+// Option is a functional option for the circuit breaker.
+type Option func(*Config)
+
+// WithFailureRatio sets the ratio of failed requests which opens the breaker.
+func WithFailureRatio(n float64) Option {
+	return func(c *Config) {
+		c.FailureRatio = n
+	}
+}
+
+// WithMinRequests sets the number of requests in a window before the breaker can open.
+func WithMinRequests(n int) Option {
+	return func(c *Config) {
+		c.MinRequests = n
+	}
+}
+
+// WithWindow sets the time window in which requests are counted.
+func WithWindow(n time.Duration) Option {
+	return func(c *Config) {
+		c.Window = config.Duration(n)
+	}
+}
+
+// WithOpenTimeout sets the time a breaker stays open.
+func WithOpenTimeout(n time.Duration) Option {
+	return func(c *Config) {
+		c.OpenTimeout = config.Duration(n)
+	}
+}
+
+// WithHalfOpenRequests sets the number of probes in half-open state.
+func WithHalfOpenRequests(n int) Option {
+	return func(c *Config) {
+		c.HalfOpenRequests = n
+	}
+}
+
+// NewConfig creates a new config with the defaults and applies opts on top.
+func NewConfig(opts ...Option) Config {
+	cfg := Config{
+		FailureRatio:     DefaultFailureRatio,
+		MinRequests:      DefaultMinRequests,
+		Window:           DefaultWindow,
+		OpenTimeout:      DefaultOpenTimeout,
+		HalfOpenRequests: DefaultHalfOpenRequests,
+	}
+
+	for _, o := range opts {
+		o(&cfg)
+	}
+
+	return cfg
+}
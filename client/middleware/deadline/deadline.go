@@ -0,0 +1,117 @@
+// Package deadline provides a client middleware which sends the remaining time
+// of a request to the server, in the outgoing metadata key metadata.Timeout.
+//
+// The "deadline" server middleware restores it as deadline of the handlers
+// context, this way deadlines cascade through call chains.
+package deadline
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/go-orb/go-orb/client"
+	"github.com/go-orb/go-orb/log"
+	"github.com/go-orb/go-orb/util/metadata"
+	"github.com/go-orb/go-orb/util/orberrors"
+)
+
+// Name is the name of this middleware.
+const Name = "deadline"
+
+var (
+	_ client.Middleware       = (*Middleware)(nil)
+	_ client.StreamMiddleware = (*Middleware)(nil)
+)
+
+func init() {
+	client.Middlewares.Add(Name, Provide)
+}
+
+// Middleware writes the remaining time of each request into its outgoing metadata.
+type Middleware struct{}
+
+// Provide creates a new deadline middleware.
+func Provide(_ map[string]any, _ client.Type, _ log.Logger) (client.Middleware, error) {
+	return New(), nil
+}
+
+// New creates a new deadline middleware.
+func New() *Middleware {
+	return &Middleware{}
+}
+
+// Start is a no-op.
+func (m *Middleware) Start(_ context.Context) error {
+	return nil
+}
+
+// Stop is a no-op.
+func (m *Middleware) Stop(_ context.Context) error {
+	return nil
+}
+
+// Type returns the component type.
+func (m *Middleware) Type() string {
+	return client.MiddlewareComponentType
+}
+
+// String returns the name of this middleware.
+func (m *Middleware) String() string {
+	return Name
+}
+
+// Request sends the time until the deadline of ctx or the RequestTimeout, whichever comes first.
+func (m *Middleware) Request(next client.MiddlewareRequestHandler) client.MiddlewareRequestHandler {
+	return func(ctx context.Context, service string, endpoint string, req any, result any, opts *client.CallOptions) error {
+		ctx, err := withTimeout(ctx, service, opts.RequestTimeout)
+		if err != nil {
+			return err
+		}
+
+		return next(ctx, service, endpoint, req, result, opts)
+	}
+}
+
+// Stream sends the time until the deadline of ctx or the StreamTimeout, whichever comes first.
+func (m *Middleware) Stream(next client.MiddlewareStreamHandler) client.MiddlewareStreamHandler {
+	return func(ctx context.Context, service string, endpoint string, opts *client.CallOptions) (client.StreamIface[any, any], error) {
+		ctx, err := withTimeout(ctx, service, opts.StreamTimeout)
+		if err != nil {
+			return nil, err
+		}
+
+		return next(ctx, service, endpoint, opts)
+	}
+}
+
+// withTimeout returns a copy of ctx with the remaining time in its outgoing metadata.
+func withTimeout(ctx context.Context, service string, timeout time.Duration) (context.Context, error) {
+	deadline, ok := ctx.Deadline()
+
+	if timeout > 0 {
+		if t := time.Now().Add(timeout); !ok || t.Before(deadline) {
+			deadline = t
+			ok = true
+		}
+	}
+
+	if !ok {
+		return ctx, nil
+	}
+
+	remaining := time.Until(deadline)
+	if remaining <= 0 {
+		return nil, orberrors.ErrRequestTimeout.WrapF("deadline of the request to '%s' exceeded", service)
+	}
+
+	// Clone the metadata, so parallel requests with the same parent don't share it.
+	md := metadata.New(nil)
+	if parent, ok := metadata.OutgoingMD(ctx); ok {
+		md = parent.Clone()
+	}
+
+	md.Set(metadata.Timeout, strconv.FormatInt(remaining.Milliseconds(), 10))
+
+	return metadata.NewOutgoing(ctx, md), nil
+}
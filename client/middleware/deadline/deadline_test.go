@@ -0,0 +1,102 @@
+package deadline
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/go-orb/go-orb/client"
+	"github.com/go-orb/go-orb/util/metadata"
+	"github.com/go-orb/go-orb/util/orberrors"
+)
+
+// sent returns the timeout the middleware sends for a request from ctx.
+func sent(ctx context.Context, opts *client.CallOptions) (time.Duration, bool, error) {
+	var (
+		value string
+		ok    bool
+	)
+
+	err := New().Request(func(ctx context.Context, _, _ string, _, _ any, _ *client.CallOptions) error {
+		value, ok = metadata.GetOutgoing(ctx, metadata.Timeout)
+		return nil
+	})(ctx, "svc", "ep", nil, nil, opts)
+	if err != nil || !ok {
+		return 0, ok, err
+	}
+
+	ms, err := strconv.ParseInt(value, 10, 64)
+
+	return time.Duration(ms) * time.Millisecond, ok, err
+}
+
+func TestRequest(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	for _, tc := range []struct {
+		name     string
+		ctx      context.Context //nolint:containedctx
+		opts     *client.CallOptions
+		expected time.Duration
+		ok       bool
+		err      error
+	}{
+		{"deadline", ctx, &client.CallOptions{}, time.Minute, true, nil},
+		{"shorter timeout", ctx, &client.CallOptions{RequestTimeout: time.Second}, time.Second, true, nil},
+		{"longer timeout", ctx, &client.CallOptions{RequestTimeout: time.Hour}, time.Minute, true, nil},
+		{"timeout only", context.Background(), &client.CallOptions{RequestTimeout: time.Second}, time.Second, true, nil},
+		{"none", context.Background(), &client.CallOptions{}, 0, false, nil},
+	} {
+		timeout, ok, err := sent(tc.ctx, tc.opts)
+		if !errors.Is(err, tc.err) || ok != tc.ok {
+			t.Fatalf("%s: expected %t, %v, got %t, %v", tc.name, tc.ok, tc.err, ok, err)
+		}
+
+		if d := tc.expected - timeout; d < 0 || d > time.Second {
+			t.Fatalf("%s: expected about %s, got %s", tc.name, tc.expected, timeout)
+		}
+	}
+
+	expired, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+
+	if _, _, err := sent(expired, &client.CallOptions{}); !errors.Is(err, orberrors.ErrRequestTimeout) {
+		t.Fatalf("expected ErrRequestTimeout, got %v", err)
+	}
+}
+
+func TestOutgoingMetadata(t *testing.T) {
+	parent, out := metadata.WithOutgoingMD(context.Background())
+	out.Set("tenant", "a")
+
+	ctx, cancel := context.WithTimeout(parent, time.Minute)
+	defer cancel()
+
+	var md *metadata.MD
+
+	_, err := New().Stream(func(ctx context.Context, _, _ string, _ *client.CallOptions) (client.StreamIface[any, any], error) {
+		md, _ = metadata.OutgoingMD(ctx)
+		return nil, nil //nolint:nilnil
+	})(ctx, "svc", "ep", &client.CallOptions{StreamTimeout: time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The stream gets the StreamTimeout and the callers metadata.
+	value, _ := md.Value(metadata.Timeout)
+	if ms, err := strconv.ParseInt(value, 10, 64); err != nil || ms <= 0 || ms > 1000 {
+		t.Fatalf("expected a timeout of at most 1000ms, got %q", value)
+	}
+
+	if v, _ := md.Value("tenant"); v != "a" {
+		t.Fatalf("expected the callers metadata, got %v", md.Map())
+	}
+
+	// The metadata of the parent stays untouched.
+	if _, ok := out.Value(metadata.Timeout); ok || out.Len() != 1 {
+		t.Fatalf("the parents outgoing metadata has been modified: %v", out.Map())
+	}
+}
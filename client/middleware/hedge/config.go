@@ -0,0 +1,99 @@
+package hedge
+
+import (
+	"time"
+
+	"github.com/go-orb/go-orb/config"
+	"github.com/go-orb/go-orb/metrics"
+)
+
+//nolint:gochecknoglobals
+var (
+	// DefaultMaxHedges caps the number of hedged requests per call.
+	DefaultMaxHedges = 2
+	// DefaultPercentile of the latency after which a hedged request is sent.
+	DefaultPercentile = 0.95
+	// DefaultDelay is used until there are MinSamples measurements.
+	DefaultDelay = config.Duration(100 * time.Millisecond)
+	// DefaultMinDelay is the lower limit of the delay.
+	DefaultMinDelay = config.Duration(5 * time.Millisecond)
+	// DefaultMinSamples is the number of measurements before the percentile gets used.
+	DefaultMinSamples = 20
+	// DefaultSamples is the number of latest measurements per endpoint the percentile gets calculated from.
+	DefaultSamples = 128
+)
+
+// Config is the config of the hedge middleware.
+type Config struct {
+	// MaxHedges caps the number of hedged requests per call, client.WithHedging can't exceed it.
+	MaxHedges int `json:"maxHedges,omitempty" yaml:"maxHedges,omitempty"`
+	// Percentile of the latency after which a hedged request is sent, between 0 and 1.
+	Percentile float64 `json:"percentile,omitempty" yaml:"percentile,omitempty"`
+	// Delay is used until there are MinSamples measurements.
+	Delay config.Duration `json:"delay,omitempty" yaml:"delay,omitempty"`
+	// MinDelay is the lower limit of the delay.
+	MinDelay config.Duration `json:"minDelay,omitempty" yaml:"minDelay,omitempty"`
+	// MinSamples is the number of measurements before the percentile gets used.
+	MinSamples int `json:"minSamples,omitempty" yaml:"minSamples,omitempty"`
+	// Samples is the number of latest measurements per endpoint the percentile gets calculated from.
+	Samples int `json:"samples,omitempty" yaml:"samples,omitempty"`
+
+	// metrics receives the hedge counters, see WithMetrics.
+	metrics metrics.Metrics
+}
+
+// Option is a functional option for the hedge middleware.
+type Option func(*Config)
+
+// WithMaxHedges caps the number of hedged requests per call.
+func WithMaxHedges(n int) Option {
+	return func(c *Config) {
+		c.MaxHedges = n
+	}
+}
+
+// WithPercentile sets the percentile of the latency after which a hedged request is sent.
+func WithPercentile(n float64) Option {
+	return func(c *Config) {
+		c.Percentile = n
+	}
+}
+
+// WithDelay sets the delay used until there are enough measurements.
+func WithDelay(n time.Duration) Option {
+	return func(c *Config) {
+		c.Delay = config.Duration(n)
+	}
+}
+
+// WithMinDelay sets the lower limit of the delay.
+func WithMinDelay(n time.Duration) Option {
+	return func(c *Config) {
+		c.MinDelay = config.Duration(n)
+	}
+}
+
+// WithMetrics reports hedge counters to m.
+func WithMetrics(m metrics.Metrics) Option {
+	return func(c *Config) {
+		c.metrics = m
+	}
+}
+
+// NewConfig creates a new config with the defaults and applies opts on top.
+func NewConfig(opts ...Option) Config {
+	cfg := Config{
+		MaxHedges:  DefaultMaxHedges,
+		Percentile: DefaultPercentile,
+		Delay:      DefaultDelay,
+		MinDelay:   DefaultMinDelay,
+		MinSamples: DefaultMinSamples,
+		Samples:    DefaultSamples,
+	}
+
+	for _, o := range opts {
+		o(&cfg)
+	}
+
+	return cfg
+}
@@ -0,0 +1,360 @@
+// Package hedge provides a client middleware which sends hedged requests, when a
+// request hasn't been answered after a percentile of the endpoints latency it sends
+// the same request to another node. The first reply wins, the others get canceled.
+//
+// Hedging is enabled per call with client.WithHedging, only use it for read-only
+// endpoints as the server may process a request multiple times.
+package hedge
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/go-orb/go-orb/client"
+	"github.com/go-orb/go-orb/config"
+	"github.com/go-orb/go-orb/log"
+	"github.com/go-orb/go-orb/metrics"
+	"github.com/go-orb/go-orb/registry"
+)
+
+// Name is the name of this middleware.
+const Name = "hedge"
+
+var _ client.Middleware = (*Middleware)(nil)
+
+func init() {
+	client.Middlewares.Add(Name, Provide)
+}
+
+// Middleware is the hedge middleware.
+type Middleware struct {
+	config Config
+	logger log.Logger
+
+	mu        sync.Mutex
+	latencies map[string]*latency
+}
+
+// Provide creates a new hedge middleware from the config, use ProvideWithMetrics to count the hedges.
+func Provide(configData map[string]any, _ client.Type, logger log.Logger) (client.Middleware, error) {
+	return provide(configData, logger)
+}
+
+// ProvideWithMetrics returns a factory whose middlewares count sent, won and lost hedges in m.
+func ProvideWithMetrics(m metrics.Metrics) client.MiddlewareFactory {
+	return func(configData map[string]any, _ client.Type, logger log.Logger) (client.Middleware, error) {
+		return provide(configData, logger, WithMetrics(m))
+	}
+}
+
+func provide(configData map[string]any, logger log.Logger, opts ...Option) (client.Middleware, error) {
+	cfg := NewConfig(opts...)
+
+	if err := config.Parse(nil, "", configData, &cfg); err != nil && !errors.Is(err, config.ErrNoSuchKey) {
+		return nil, err
+	}
+
+	return New(cfg, logger), nil
+}
+
+// New creates a new hedge middleware.
+func New(cfg Config, logger log.Logger) *Middleware {
+	return &Middleware{
+		config:    cfg,
+		logger:    logger.With("middleware", Name),
+		latencies: make(map[string]*latency),
+	}
+}
+
+// Start is a no-op.
+func (m *Middleware) Start(_ context.Context) error {
+	return nil
+}
+
+// Stop is a no-op.
+func (m *Middleware) Stop(_ context.Context) error {
+	return nil
+}
+
+// Type returns the component type.
+func (m *Middleware) Type() string {
+	return client.MiddlewareComponentType
+}
+
+// String returns the name of this middleware.
+func (m *Middleware) String() string {
+	return Name
+}
+
+// attempt is the outcome of a single request.
+type attempt struct {
+	n   int
+	err error
+	// result is the value the attempt decodes into, the winner gets copied into the callers result.
+	result   any
+	opts     *client.CallOptions
+	infos    *client.RequestInfos
+	duration time.Duration
+}
+
+// Request sends hedged requests if the call enabled them with client.WithHedging.
+func (m *Middleware) Request(next client.MiddlewareRequestHandler) client.MiddlewareRequestHandler {
+	return func(ctx context.Context, service string, endpoint string, req any, result any, opts *client.CallOptions) error {
+		lat := m.latency(service, endpoint)
+
+		hedges := min(opts.Hedges, m.config.MaxHedges)
+		rv := reflect.ValueOf(result)
+
+		// Parallel requests need their own result, so we can only hedge into pointers.
+		if hedges <= 0 || rv.Kind() != reflect.Pointer || rv.IsNil() {
+			start := time.Now()
+
+			err := next(ctx, service, endpoint, req, result, opts)
+			if err == nil {
+				lat.add(time.Since(start))
+			}
+
+			return err
+		}
+
+		return m.hedge(ctx, next, lat, hedges, service, endpoint, req, result, opts)
+	}
+}
+
+func (m *Middleware) hedge(
+	ctx context.Context,
+	next client.MiddlewareRequestHandler,
+	lat *latency,
+	hedges int,
+	service string,
+	endpoint string,
+	req any,
+	result any,
+	opts *client.CallOptions,
+) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	rv := reflect.ValueOf(result)
+
+	// The attempts get their own copy of the request infos, the winner gets copied back.
+	infos, _ := ctx.Value(client.RequestInfosKey{}).(*client.RequestInfos) //nolint:errcheck
+
+	nodes := &usedNodes{addresses: make(map[string]struct{})}
+	results := make(chan attempt, hedges+1)
+
+	send := func(n int) {
+		// Each attempt needs its own result, the losers may still write to it after the call returned.
+		a := attempt{
+			n:      n,
+			result: reflect.New(rv.Elem().Type()).Interface(),
+			opts:   m.attemptOptions(opts, nodes),
+		}
+
+		aCtx := ctx
+		if infos != nil {
+			aInfos := *infos
+			a.infos = &aInfos
+			aCtx = context.WithValue(ctx, client.RequestInfosKey{}, a.infos)
+		}
+
+		go func() {
+			start := time.Now()
+			a.err = next(aCtx, service, endpoint, req, a.result, a.opts)
+			a.duration = time.Since(start)
+			results <- a
+		}()
+	}
+
+	send(0)
+
+	sent := 1
+	pending := 1
+
+	var firstErr error
+
+	timer := time.NewTimer(m.delay(lat))
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-timer.C:
+			if sent > hedges || nodes.exhausted() {
+				continue
+			}
+
+			send(sent)
+			sent++
+			pending++
+
+			m.incr(service, endpoint, "requests")
+			timer.Reset(m.delay(lat))
+		case a := <-results:
+			pending--
+
+			// The losers get canceled on return, results is large enough that they never block on it.
+			if a.err == nil {
+				m.finish(service, endpoint, rv, opts, infos, a, sent)
+				lat.add(a.duration)
+
+				return nil
+			}
+
+			if firstErr == nil || a.n == 0 {
+				firstErr = a.err
+			}
+
+			if errors.Is(a.err, client.ErrNoNodeFound) && a.n > 0 {
+				nodes.setExhausted()
+			}
+
+			if pending == 0 {
+				return firstErr
+			}
+		}
+	}
+}
+
+// finish copies the winning attempt into the callers result, options and request infos.
+func (m *Middleware) finish(
+	service, endpoint string,
+	rv reflect.Value,
+	opts *client.CallOptions,
+	infos *client.RequestInfos,
+	a attempt,
+	sent int,
+) {
+	rv.Elem().Set(reflect.ValueOf(a.result).Elem())
+
+	if opts.ResponseMetadata != nil {
+		maps.Copy(opts.ResponseMetadata, a.opts.ResponseMetadata)
+	}
+
+	if infos != nil && a.infos != nil {
+		*infos = *a.infos
+	}
+
+	if sent == 1 {
+		return
+	}
+
+	if a.n > 0 {
+		m.incr(service, endpoint, "wins")
+	} else {
+		m.incr(service, endpoint, "losses")
+	}
+}
+
+// attemptOptions copies opts for a single attempt, its selector skips the nodes of the other attempts.
+func (m *Middleware) attemptOptions(opts *client.CallOptions, nodes *usedNodes) *client.CallOptions {
+	aOpts := *opts
+
+	if opts.ResponseMetadata != nil {
+		aOpts.ResponseMetadata = make(map[string]string)
+	}
+
+	selector := opts.Selector
+	if selector == nil {
+		selector = client.DefaultSelector
+	}
+
+	aOpts.Selector = func(ctx context.Context, service string, all []registry.ServiceNode) (registry.ServiceNode, error) {
+		return nodes.selectDistinct(ctx, service, all, selector)
+	}
+
+	return &aOpts
+}
+
+// delay returns the time to wait before the next hedged request.
+func (m *Middleware) delay(lat *latency) time.Duration {
+	d, ok := lat.percentile(m.config.Percentile, m.config.MinSamples)
+	if !ok {
+		d = time.Duration(m.config.Delay)
+	}
+
+	return max(d, time.Duration(m.config.MinDelay))
+}
+
+func (m *Middleware) incr(service, endpoint, counter string) {
+	if m.config.metrics == nil {
+		return
+	}
+
+	m.config.metrics.IncrCounterWithLabels(
+		[]string{"client", "hedge", counter},
+		1,
+		[]metrics.Label{{Name: "service", Value: service}, {Name: "endpoint", Value: endpoint}},
+	)
+}
+
+func (m *Middleware) latency(service, endpoint string) *latency {
+	key := service + "/" + endpoint
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	l, ok := m.latencies[key]
+	if !ok {
+		l = newLatency(m.config.Samples)
+		m.latencies[key] = l
+	}
+
+	return l
+}
+
+// usedNodes are the nodes selected by the attempts of a call.
+type usedNodes struct {
+	mu        sync.Mutex
+	addresses map[string]struct{}
+	noMore    bool
+}
+
+// selectDistinct selects a node with selector which hasn't been used by another attempt.
+func (u *usedNodes) selectDistinct(
+	ctx context.Context,
+	service string,
+	all []registry.ServiceNode,
+	selector client.SelectorFunc,
+) (registry.ServiceNode, error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	nodes := make([]registry.ServiceNode, 0, len(all))
+
+	for _, node := range all {
+		if _, ok := u.addresses[node.Address]; !ok {
+			nodes = append(nodes, node)
+		}
+	}
+
+	if len(nodes) == 0 {
+		return registry.ServiceNode{}, client.ErrNoNodeFound
+	}
+
+	node, err := selector(ctx, service, nodes)
+	if err != nil {
+		return node, err
+	}
+
+	u.addresses[node.Address] = struct{}{}
+
+	return node, nil
+}
+
+func (u *usedNodes) exhausted() bool {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	return u.noMore
+}
+
+func (u *usedNodes) setExhausted() {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	u.noMore = true
+}
@@ -0,0 +1,144 @@
+package hedge
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-orb/go-orb/client"
+	"github.com/go-orb/go-orb/log"
+	"github.com/go-orb/go-orb/registry"
+	"github.com/go-orb/go-orb/util/orberrors"
+)
+
+func TestHedge(t *testing.T) {
+	m := New(NewConfig(WithDelay(10*time.Millisecond)), log.Logger{Logger: slog.New(slog.NewTextHandler(io.Discard, nil))})
+
+	nodes := []registry.ServiceNode{{Address: "slow"}, {Address: "fast"}}
+	slowDone := make(chan struct{})
+
+	handler := m.Request(func(ctx context.Context, service, _ string, _, result any, opts *client.CallOptions) error {
+		// Always prefer the slow node, the hedged request must get the other one.
+		node, err := opts.Selector(ctx, service, nodes)
+		if err != nil {
+			return err
+		}
+
+		if node.Address == "slow" {
+			defer close(slowDone)
+
+			select {
+			case <-ctx.Done():
+				// A transport which is slow to honour the cancellation, it decodes a late response.
+				time.Sleep(200 * time.Millisecond)
+				*result.(*string) = "late" //nolint:errcheck,forcetypeassert
+
+				return ctx.Err()
+			case <-time.After(time.Second):
+			}
+		}
+
+		*result.(*string) = node.Address //nolint:errcheck,forcetypeassert
+
+		return nil
+	})
+
+	var result string
+
+	start := time.Now()
+
+	err := handler(context.Background(), "svc", "ep", nil, &result, &client.CallOptions{Selector: first, Hedges: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The winner returns without waiting for the canceled attempt.
+	if time.Since(start) > 150*time.Millisecond {
+		t.Fatalf("hedged request took too long: %s", time.Since(start))
+	}
+
+	<-slowDone
+
+	if result != "fast" {
+		t.Fatalf("expected the hedged request to win, got %q", result)
+	}
+}
+
+func first(_ context.Context, _ string, nodes []registry.ServiceNode) (registry.ServiceNode, error) {
+	return nodes[0], nil
+}
+
+func TestAllFail(t *testing.T) {
+	m := New(NewConfig(WithDelay(10*time.Millisecond)), log.Logger{Logger: slog.New(slog.NewTextHandler(io.Discard, nil))})
+
+	nodes := []registry.ServiceNode{{Address: "a"}, {Address: "b"}}
+
+	var calls atomic.Int32
+
+	handler := m.Request(func(ctx context.Context, service, _ string, _, _ any, opts *client.CallOptions) error {
+		calls.Add(1)
+
+		node, err := opts.Selector(ctx, service, nodes)
+		if err != nil {
+			return err
+		}
+
+		// The hedge fails before the first attempt.
+		if node.Address == "a" {
+			time.Sleep(50 * time.Millisecond)
+			return orberrors.ErrInternalServerError
+		}
+
+		return orberrors.ErrUnavailable
+	})
+
+	var result string
+
+	err := handler(context.Background(), "svc", "ep", nil, &result, &client.CallOptions{Selector: first, Hedges: 1})
+	if !errors.Is(err, orberrors.ErrInternalServerError) {
+		t.Fatalf("expected the error of the first attempt, got %v", err)
+	}
+
+	if n := calls.Load(); n != 2 {
+		t.Fatalf("expected 2 attempts, got %d", n)
+	}
+}
+
+func TestExhausted(t *testing.T) {
+	m := New(NewConfig(WithDelay(5*time.Millisecond), WithMinDelay(5*time.Millisecond)), log.Logger{Logger: slog.New(slog.NewTextHandler(io.Discard, nil))})
+
+	nodes := []registry.ServiceNode{{Address: "only"}}
+
+	var calls atomic.Int32
+
+	handler := m.Request(func(ctx context.Context, service, _ string, _, result any, opts *client.CallOptions) error {
+		calls.Add(1)
+
+		node, err := opts.Selector(ctx, service, nodes)
+		if err != nil {
+			return err
+		}
+
+		time.Sleep(50 * time.Millisecond)
+
+		*result.(*string) = node.Address //nolint:errcheck,forcetypeassert
+
+		return nil
+	})
+
+	var result string
+
+	err := handler(context.Background(), "svc", "ep", nil, &result, &client.CallOptions{Selector: first, Hedges: 3})
+	if err != nil || result != "only" {
+		t.Fatalf("expected the first attempt to win, got %q: %v", result, err)
+	}
+
+	// The first hedge finds no other node, no more hedges get sent after it.
+	if n := calls.Load(); n != 2 {
+		t.Fatalf("expected the first attempt and one hedge, got %d attempts", n)
+	}
+}
@@ -0,0 +1,51 @@
+package hedge
+
+import (
+	"slices"
+	"sync"
+	"time"
+)
+
+// latency keeps the latest measurements of an endpoint in a ring buffer.
+type latency struct {
+	mu      sync.Mutex
+	samples []time.Duration
+	next    int
+}
+
+func newLatency(size int) *latency {
+	return &latency{
+		samples: make([]time.Duration, 0, max(size, 1)),
+	}
+}
+
+func (l *latency) add(d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if len(l.samples) < cap(l.samples) {
+		l.samples = append(l.samples, d)
+		return
+	}
+
+	l.samples[l.next] = d
+	l.next = (l.next + 1) % len(l.samples)
+}
+
+// percentile returns the p-th percentile of the measurements, false if there are less than minSamples.
+func (l *latency) percentile(p float64, minSamples int) (time.Duration, bool) {
+	l.mu.Lock()
+	sorted := slices.Clone(l.samples)
+	l.mu.Unlock()
+
+	if len(sorted) == 0 || len(sorted) < minSamples {
+		return 0, false
+	}
+
+	slices.Sort(sorted)
+
+	i := int(p * float64(len(sorted)))
+	i = max(0, min(i, len(sorted)-1))
+
+	return sorted[i], true
+}
@@ -0,0 +1,101 @@
+package limiter
+
+import (
+	"time"
+
+	"github.com/go-orb/go-orb/config"
+)
+
+//nolint:gochecknoglobals
+var (
+	// DefaultMinInFlight is the lower limit of the adaptive concurrency limit.
+	DefaultMinInFlight = 1
+	// DefaultTolerance is the factor by which the latency may rise above the latency
+	// without load before the adaptive concurrency limit gets lowered.
+	DefaultTolerance = 2.0
+	// DefaultBackoffRatio is the factor the adaptive concurrency limit gets multiplied with when it gets lowered.
+	DefaultBackoffRatio = 0.9
+)
+
+// Config is the config of the limiter middleware, all limits are per service or per endpoint.
+type Config struct {
+	// Rate is the number of requests per second, 0 disables rate limiting.
+	Rate float64 `json:"rate,omitempty" yaml:"rate,omitempty"`
+	// Burst is the number of requests which may be sent at once, defaults to 1.
+	Burst int `json:"burst,omitempty" yaml:"burst,omitempty"`
+	// MaxInFlight is the maximum number of concurrent requests, 0 disables concurrency limiting.
+	// In adaptive mode it's the upper limit and starting value.
+	MaxInFlight int `json:"maxInFlight,omitempty" yaml:"maxInFlight,omitempty"`
+	// PerEndpoint applies the limits per endpoint instead of per service.
+	PerEndpoint bool `json:"perEndpoint,omitempty" yaml:"perEndpoint,omitempty"`
+	// QueueTimeout is the maximum time a request waits when a limit has been reached,
+	// 0 rejects it immediately with orberrors.ErrTooManyRequests.
+	QueueTimeout config.Duration `json:"queueTimeout,omitempty" yaml:"queueTimeout,omitempty"`
+
+	// Adaptive lowers the concurrency limit when the latency rises (AIMD),
+	// and raises it again slowly while the latency is fine.
+	Adaptive bool `json:"adaptive,omitempty" yaml:"adaptive,omitempty"`
+	// MinInFlight is the lower limit of the adaptive concurrency limit.
+	MinInFlight int `json:"minInFlight,omitempty" yaml:"minInFlight,omitempty"`
+	// Tolerance is the factor by which the latency may rise above the latency without load
+	// before the limit gets lowered.
+	Tolerance float64 `json:"tolerance,omitempty" yaml:"tolerance,omitempty"`
+	// BackoffRatio is the factor the limit gets multiplied with when it gets lowered.
+	BackoffRatio float64 `json:"backoffRatio,omitempty" yaml:"backoffRatio,omitempty"`
+}
+
+// Option is a functional option for the limiter middleware.
+type Option func(*Config)
+
+// WithRate sets the rate limit in requests per second and the burst.
+func WithRate(rate float64, burst int) Option {
+	return func(c *Config) {
+		c.Rate = rate
+		c.Burst = burst
+	}
+}
+
+// WithMaxInFlight sets the maximum number of concurrent requests.
+func WithMaxInFlight(n int) Option {
+	return func(c *Config) {
+		c.MaxInFlight = n
+	}
+}
+
+// WithPerEndpoint applies the limits per endpoint instead of per service.
+func WithPerEndpoint() Option {
+	return func(c *Config) {
+		c.PerEndpoint = true
+	}
+}
+
+// WithQueueTimeout lets requests wait up to n for a limit, instead of rejecting them.
+func WithQueueTimeout(n time.Duration) Option {
+	return func(c *Config) {
+		c.QueueTimeout = config.Duration(n)
+	}
+}
+
+// WithAdaptive enables the adaptive concurrency limit between minInFlight and MaxInFlight.
+func WithAdaptive(minInFlight int) Option {
+	return func(c *Config) {
+		c.Adaptive = true
+		c.MinInFlight = minInFlight
+	}
+}
+
+// NewConfig creates a new config with the defaults and applies opts on top.
+func NewConfig(opts ...Option) Config {
+	cfg := Config{
+		Burst:        1,
+		MinInFlight:  DefaultMinInFlight,
+		Tolerance:    DefaultTolerance,
+		BackoffRatio: DefaultBackoffRatio,
+	}
+
+	for _, o := range opts {
+		o(&cfg)
+	}
+
+	return cfg
+}
@@ -0,0 +1,186 @@
+package limiter
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"time"
+)
+
+// errLimited is returned by acquire when a limit has been reached and there's no time to wait.
+var errLimited = errors.New("limit reached")
+
+// baselineAlpha is the weight of a new measurement in the no-load latency of the adaptive limit.
+const baselineAlpha = 0.05
+
+// limit enforces the rate and concurrency limits of a single service or endpoint.
+type limit struct {
+	config *Config
+
+	mu sync.Mutex
+
+	// Token bucket, tokens may go negative for reserved tokens.
+	tokens  float64
+	updated time.Time
+
+	inFlight int
+	// maxInFlight is the current concurrency limit, it changes in adaptive mode.
+	maxInFlight float64
+	// baseline is the latency without load in nanoseconds in adaptive mode, it only goes down.
+	baseline float64
+	// released gets closed and replaced whenever a slot has been released.
+	released chan struct{}
+}
+
+func newLimit(cfg *Config) *limit {
+	return &limit{
+		config:      cfg,
+		tokens:      float64(max(cfg.Burst, 1)),
+		updated:     time.Now(),
+		maxInFlight: float64(cfg.MaxInFlight),
+		released:    make(chan struct{}),
+	}
+}
+
+// acquire takes a concurrency slot and a token, if queue is set it waits until ctx is done.
+// It returns errLimited if it can't wait, or the error of ctx.
+func (l *limit) acquire(ctx context.Context, queue bool) error {
+	if err := l.acquireSlot(ctx, queue); err != nil {
+		return err
+	}
+
+	if err := l.acquireToken(ctx, queue); err != nil {
+		l.release(0, false)
+		return err
+	}
+
+	return nil
+}
+
+func (l *limit) acquireSlot(ctx context.Context, queue bool) error {
+	if l.config.MaxInFlight <= 0 {
+		return nil
+	}
+
+	for {
+		l.mu.Lock()
+
+		if l.inFlight < int(l.maxInFlight) {
+			l.inFlight++
+			l.mu.Unlock()
+
+			return nil
+		}
+
+		released := l.released
+		l.mu.Unlock()
+
+		if !queue {
+			return errLimited
+		}
+
+		select {
+		case <-ctx.Done():
+			return wait(ctx)
+		case <-released:
+		}
+	}
+}
+
+func (l *limit) acquireToken(ctx context.Context, queue bool) error {
+	if l.config.Rate <= 0 {
+		return nil
+	}
+
+	now := time.Now()
+
+	l.mu.Lock()
+
+	burst := float64(max(l.config.Burst, 1))
+	l.tokens = math.Min(l.tokens+now.Sub(l.updated).Seconds()*l.config.Rate, burst)
+	l.updated = now
+
+	if l.tokens >= 1 {
+		l.tokens--
+		l.mu.Unlock()
+
+		return nil
+	}
+
+	// Reserve a token if it will be there in time.
+	delay := time.Duration((1 - l.tokens) / l.config.Rate * float64(time.Second))
+
+	if deadline, ok := ctx.Deadline(); !queue || (ok && now.Add(delay).After(deadline)) {
+		l.mu.Unlock()
+		return errLimited
+	}
+
+	l.tokens--
+	l.mu.Unlock()
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		// Return the reserved token.
+		l.mu.Lock()
+		l.tokens++
+		l.mu.Unlock()
+
+		return wait(ctx)
+	case <-timer.C:
+		return nil
+	}
+}
+
+// release frees the concurrency slot, in adaptive mode it adjusts the limit
+// by the latency of the request and whether it has been overloaded.
+func (l *limit) release(latency time.Duration, overloaded bool) {
+	if l.config.MaxInFlight <= 0 {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.inFlight--
+
+	if l.config.Adaptive && latency > 0 {
+		l.adapt(float64(latency), overloaded)
+	}
+
+	close(l.released)
+	l.released = make(chan struct{})
+}
+
+// adapt is the AIMD algorithm, the caller must hold l.mu.
+func (l *limit) adapt(latency float64, overloaded bool) {
+	if l.baseline == 0 {
+		l.baseline = latency
+	}
+
+	if overloaded || latency > l.baseline*l.config.Tolerance {
+		// Multiplicative decrease.
+		l.maxInFlight = math.Max(l.maxInFlight*l.config.BackoffRatio, float64(max(l.config.MinInFlight, 1)))
+	} else {
+		// Additive increase by about one per maxInFlight requests.
+		l.maxInFlight = math.Min(l.maxInFlight+1/l.maxInFlight, float64(l.config.MaxInFlight))
+	}
+
+	// Only faster requests move the baseline, during overload it would drift up
+	// to the overloaded latency and the limit would never shrink.
+	if latency < l.baseline {
+		l.baseline += baselineAlpha * (latency - l.baseline)
+	}
+}
+
+// wait translates the error of a waiting ctx, running into the queue deadline means the limit has been reached.
+func wait(ctx context.Context) error {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return errLimited
+	}
+
+	return ctx.Err()
+}
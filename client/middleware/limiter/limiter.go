@@ -0,0 +1,198 @@
+// Package limiter provides a client middleware which limits the rate and the number
+// of concurrent requests per service or endpoint, to protect downstream services
+// from bulk jobs.
+//
+// When a limit has been reached requests wait up to QueueTimeout, or get rejected
+// with orberrors.ErrTooManyRequests. The adaptive mode lowers the concurrency
+// limit when the latency of the service rises.
+package limiter
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-orb/go-orb/client"
+	"github.com/go-orb/go-orb/config"
+	"github.com/go-orb/go-orb/log"
+	"github.com/go-orb/go-orb/util/orberrors"
+)
+
+// Name is the name of this middleware.
+const Name = "limiter"
+
+var (
+	_ client.Middleware       = (*Middleware)(nil)
+	_ client.StreamMiddleware = (*Middleware)(nil)
+)
+
+func init() {
+	client.Middlewares.Add(Name, Provide)
+}
+
+// Middleware is the limiter middleware.
+type Middleware struct {
+	config Config
+	logger log.Logger
+
+	mu     sync.Mutex
+	limits map[string]*limit
+}
+
+// Provide creates a new limiter middleware from the config.
+func Provide(configData map[string]any, _ client.Type, logger log.Logger) (client.Middleware, error) {
+	cfg := NewConfig()
+
+	if err := config.Parse(nil, "", configData, &cfg); err != nil && !errors.Is(err, config.ErrNoSuchKey) {
+		return nil, err
+	}
+
+	return New(cfg, logger), nil
+}
+
+// New creates a new limiter middleware.
+func New(cfg Config, logger log.Logger) *Middleware {
+	return &Middleware{
+		config: cfg,
+		logger: logger.With("middleware", Name),
+		limits: make(map[string]*limit),
+	}
+}
+
+// Start is a no-op.
+func (m *Middleware) Start(_ context.Context) error {
+	return nil
+}
+
+// Stop is a no-op.
+func (m *Middleware) Stop(_ context.Context) error {
+	return nil
+}
+
+// Type returns the component type.
+func (m *Middleware) Type() string {
+	return client.MiddlewareComponentType
+}
+
+// String returns the name of this middleware.
+func (m *Middleware) String() string {
+	return Name
+}
+
+// Request waits for the limits of the service or endpoint, or rejects the request.
+func (m *Middleware) Request(next client.MiddlewareRequestHandler) client.MiddlewareRequestHandler {
+	return func(ctx context.Context, service string, endpoint string, req any, result any, opts *client.CallOptions) error {
+		l, err := m.acquire(ctx, service, endpoint)
+		if err != nil {
+			return err
+		}
+
+		start := time.Now()
+		err = next(ctx, service, endpoint, req, result, opts)
+
+		l.release(time.Since(start), overloaded(err))
+
+		return err
+	}
+}
+
+// Stream waits for the limits of the service or endpoint, or rejects the stream.
+// A stream holds its concurrency slot until it ends or ctx is done, it doesn't affect the adaptive limit.
+func (m *Middleware) Stream(next client.MiddlewareStreamHandler) client.MiddlewareStreamHandler {
+	return func(ctx context.Context, service string, endpoint string, opts *client.CallOptions) (client.StreamIface[any, any], error) {
+		l, err := m.acquire(ctx, service, endpoint)
+		if err != nil {
+			return nil, err
+		}
+
+		stream, err := next(ctx, service, endpoint, opts)
+		if err != nil {
+			l.release(0, false)
+			return nil, err
+		}
+
+		var once sync.Once
+
+		release := func() { once.Do(func() { l.release(0, false) }) }
+
+		// A stream which gets abandoned without Close releases its slot with ctx.
+		stop := context.AfterFunc(ctx, release)
+
+		return client.InterceptStream(stream, client.StreamInterceptor{
+			Done: func(error) {
+				stop()
+				release()
+			},
+		}), nil
+	}
+}
+
+// acquire takes a slot and a token of the limit for service or endpoint.
+func (m *Middleware) acquire(ctx context.Context, service, endpoint string) (*limit, error) {
+	key := service
+	if m.config.PerEndpoint {
+		key = service + "/" + endpoint
+	}
+
+	l := m.limit(key)
+
+	if err := m.wait(ctx, l); err != nil {
+		if errors.Is(err, errLimited) {
+			return nil, orberrors.ErrTooManyRequests.WrapF("client limit for '%s' reached", key)
+		}
+
+		return nil, err
+	}
+
+	return l, nil
+}
+
+// wait acquires l, it queues up to QueueTimeout.
+func (m *Middleware) wait(ctx context.Context, l *limit) error {
+	if m.config.QueueTimeout <= 0 {
+		return l.acquire(ctx, false)
+	}
+
+	qCtx, cancel := context.WithTimeout(ctx, time.Duration(m.config.QueueTimeout))
+	defer cancel()
+
+	if err := l.acquire(qCtx, true); err != nil {
+		// The request itself has been canceled or timed out.
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+func (m *Middleware) limit(key string) *limit {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	l, ok := m.limits[key]
+	if !ok {
+		l = newLimit(&m.config)
+		m.limits[key] = l
+	}
+
+	return l
+}
+
+// overloaded returns true if err says the service is overloaded.
+func overloaded(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	switch orberrors.From(err).Code {
+	case http.StatusServiceUnavailable, http.StatusTooManyRequests, http.StatusRequestTimeout:
+		return true
+	default:
+		return false
+	}
+}
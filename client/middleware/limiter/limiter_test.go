@@ -0,0 +1,195 @@
+package limiter
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/go-orb/go-orb/client"
+	"github.com/go-orb/go-orb/log"
+	"github.com/go-orb/go-orb/util/orberrors"
+)
+
+func TestMaxInFlight(t *testing.T) {
+	logger := log.Logger{Logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+
+	block := make(chan struct{})
+	next := func(_ context.Context, _, _ string, _, _ any, _ *client.CallOptions) error {
+		<-block
+		return nil
+	}
+
+	reject := New(NewConfig(WithMaxInFlight(1)), logger).Request(next)
+	queue := New(NewConfig(WithMaxInFlight(1), WithQueueTimeout(time.Second)), logger).Request(next)
+
+	for _, tc := range []struct {
+		handler client.MiddlewareRequestHandler
+		queued  bool
+	}{{reject, false}, {queue, true}} {
+		handler := tc.handler
+
+		first := make(chan error)
+
+		go func() {
+			first <- handler(context.Background(), "svc", "ep", nil, nil, &client.CallOptions{})
+		}()
+
+		time.Sleep(10 * time.Millisecond)
+
+		second := make(chan error)
+
+		go func() {
+			second <- handler(context.Background(), "svc", "ep", nil, nil, &client.CallOptions{})
+		}()
+
+		time.Sleep(10 * time.Millisecond)
+		block <- struct{}{}
+
+		if err := <-first; err != nil {
+			t.Fatal(err)
+		}
+
+		select {
+		case err := <-second:
+			if tc.queued || !errors.Is(err, orberrors.ErrTooManyRequests) {
+				t.Fatalf("unexpected result of the second request, queued: %v, got %v", tc.queued, err)
+			}
+		case block <- struct{}{}:
+			// The second request has been queued.
+			if !tc.queued {
+				t.Fatal("expected the second request to be rejected")
+			}
+
+			if err := <-second; err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+}
+
+func TestRate(t *testing.T) {
+	m := New(NewConfig(WithRate(1, 1)), log.Logger{Logger: slog.New(slog.NewTextHandler(io.Discard, nil))})
+	handler := m.Request(func(_ context.Context, _, _ string, _, _ any, _ *client.CallOptions) error {
+		return nil
+	})
+
+	if err := handler(context.Background(), "svc", "ep", nil, nil, &client.CallOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	err := handler(context.Background(), "svc", "ep", nil, nil, &client.CallOptions{})
+	if !errors.Is(err, orberrors.ErrTooManyRequests) {
+		t.Fatalf("expected rate limit, got %v", err)
+	}
+}
+
+// stream is a client stream which ends on the first Recv.
+type stream struct {
+	client.StreamIface[any, any]
+}
+
+func (s *stream) Recv(_ any) error {
+	return io.EOF
+}
+
+func TestStream(t *testing.T) {
+	m := New(NewConfig(WithMaxInFlight(1)), log.Logger{Logger: slog.New(slog.NewTextHandler(io.Discard, nil))})
+	open := client.ChainStream(func(_ context.Context, _, _ string, _ *client.CallOptions) (client.StreamIface[any, any], error) {
+		return &stream{}, nil
+	}, m)
+
+	s, err := open(context.Background(), "svc", "ep", &client.CallOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The open stream holds the only slot.
+	if _, err := open(context.Background(), "svc", "ep", &client.CallOptions{}); !errors.Is(err, orberrors.ErrTooManyRequests) {
+		t.Fatalf("expected the limit, got %v", err)
+	}
+
+	if err := s.Recv(nil); !errors.Is(err, io.EOF) {
+		t.Fatalf("expected io.EOF, got %v", err)
+	}
+
+	if _, err := open(context.Background(), "svc", "ep", &client.CallOptions{}); err != nil {
+		t.Fatalf("expected the slot to be released when the stream ended, got %v", err)
+	}
+}
+
+func TestAdaptiveOverload(t *testing.T) {
+	cfg := NewConfig(WithMaxInFlight(100), WithAdaptive(1))
+	l := newLimit(&cfg)
+
+	for range 50 {
+		l.inFlight++
+		l.release(10*time.Millisecond, false)
+	}
+
+	if l.maxInFlight != 100 {
+		t.Fatalf("expected the limit to stay at 100 without load, got %v", l.maxInFlight)
+	}
+
+	// Under overload the limit keeps shrinking, the baseline doesn't follow the latency up.
+	for range 200 {
+		l.inFlight++
+		l.release(100*time.Millisecond, false)
+	}
+
+	if l.maxInFlight != 1 {
+		t.Fatalf("expected the limit to shrink to the minimum, got %v", l.maxInFlight)
+	}
+
+	if l.baseline != float64(10*time.Millisecond) {
+		t.Fatalf("expected the baseline to stay at 10ms, got %v", time.Duration(l.baseline))
+	}
+}
+
+// blockingStream is a client stream which never ends by itself.
+type blockingStream struct {
+	client.StreamIface[any, any]
+}
+
+func TestStreamAbandoned(t *testing.T) {
+	m := New(NewConfig(WithMaxInFlight(1)), log.Logger{Logger: slog.New(slog.NewTextHandler(io.Discard, nil))})
+	open := client.ChainStream(func(_ context.Context, _, _ string, _ *client.CallOptions) (client.StreamIface[any, any], error) {
+		return &blockingStream{}, nil
+	}, m)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	// The stream gets abandoned without Close.
+	if _, err := open(ctx, "svc", "ep", &client.CallOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := open(context.Background(), "svc", "ep", &client.CallOptions{}); !errors.Is(err, orberrors.ErrTooManyRequests) {
+		t.Fatalf("expected the limit, got %v", err)
+	}
+
+	cancel()
+
+	// The slot gets released by AfterFunc in its own goroutine.
+	for start := time.Now(); ; time.Sleep(time.Millisecond) {
+		_, err := open(context.Background(), "svc", "ep", &client.CallOptions{})
+		if err == nil {
+			break
+		}
+
+		if time.Since(start) > time.Second {
+			t.Fatalf("expected the slot to be released with ctx, got %v", err)
+		}
+	}
+
+	l := m.limit("svc")
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.inFlight != 1 {
+		t.Fatalf("expected only the last stream in flight, got %d", l.inFlight)
+	}
+}
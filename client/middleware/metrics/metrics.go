@@ -0,0 +1,173 @@
+// Package metrics provides a client middleware which records the rate, errors and
+// duration (RED) of requests:
+//
+//   - client.requests, a counter of all requests.
+//   - client.errors, a counter of failed requests.
+//   - client.latency, the duration of all requests.
+//
+// Streams get recorded to client.streams.requests, client.streams.errors and
+// client.streams.duration when they end.
+//
+// All of them are labeled with service, endpoint, transport and status, which is
+// "ok" or the orberrors code of the error. Bound the cardinality with the
+// allowedLabels and blockedLabels of the metrics config, e.g. block "endpoint".
+//
+// The middleware needs the metrics component of the service, register a provider
+// with it before the client gets created:
+//
+//	client.Middlewares.Set(metrics.Name, metrics.ProvideWithMetrics(m))
+package metrics
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/go-orb/go-orb/client"
+	"github.com/go-orb/go-orb/log"
+	"github.com/go-orb/go-orb/metrics"
+	"github.com/go-orb/go-orb/util/orberrors"
+)
+
+// Name is the name of this middleware.
+const Name = "metrics"
+
+// Label values.
+const (
+	StatusOK         = "ok"
+	TransportUnknown = "unknown"
+)
+
+// ErrNoMetrics is returned by Provide, the middleware needs a metrics component.
+var ErrNoMetrics = errors.New("the metrics middleware needs a metrics component, register it with metrics.ProvideWithMetrics")
+
+// keys are the metric names of requests or streams.
+type keys struct {
+	requests []string
+	errors   []string
+	duration []string
+}
+
+//nolint:gochecknoglobals
+var (
+	requestKeys = keys{
+		requests: []string{"client", "requests"},
+		errors:   []string{"client", "errors"},
+		duration: []string{"client", "latency"},
+	}
+	streamKeys = keys{
+		requests: []string{"client", "streams", "requests"},
+		errors:   []string{"client", "streams", "errors"},
+		duration: []string{"client", "streams", "duration"},
+	}
+)
+
+var (
+	_ client.Middleware       = (*Middleware)(nil)
+	_ client.StreamMiddleware = (*Middleware)(nil)
+)
+
+func init() {
+	client.Middlewares.Add(Name, Provide)
+}
+
+// Middleware is the client metrics middleware.
+type Middleware struct {
+	metrics metrics.Metrics
+}
+
+// Provide returns ErrNoMetrics, replace it with ProvideWithMetrics.
+func Provide(_ map[string]any, _ client.Type, _ log.Logger) (client.Middleware, error) {
+	return nil, ErrNoMetrics
+}
+
+// ProvideWithMetrics returns a factory whose middlewares report client requests to m,
+// usually the metrics component of the service.
+func ProvideWithMetrics(m metrics.Metrics) client.MiddlewareFactory {
+	return func(_ map[string]any, _ client.Type, _ log.Logger) (client.Middleware, error) {
+		return New(m), nil
+	}
+}
+
+// New creates a new metrics middleware.
+func New(m metrics.Metrics) *Middleware {
+	return &Middleware{metrics: m}
+}
+
+// Start is a no-op.
+func (m *Middleware) Start(_ context.Context) error {
+	return nil
+}
+
+// Stop is a no-op.
+func (m *Middleware) Stop(_ context.Context) error {
+	return nil
+}
+
+// Type returns the component type.
+func (m *Middleware) Type() string {
+	return client.MiddlewareComponentType
+}
+
+// String returns the name of this middleware.
+func (m *Middleware) String() string {
+	return Name
+}
+
+// Request records the request.
+func (m *Middleware) Request(next client.MiddlewareRequestHandler) client.MiddlewareRequestHandler {
+	return func(ctx context.Context, service string, endpoint string, req any, result any, opts *client.CallOptions) error {
+		start := time.Now()
+
+		err := next(ctx, service, endpoint, req, result, opts)
+
+		m.record(ctx, requestKeys, service, endpoint, start, err)
+
+		return err
+	}
+}
+
+// Stream records the stream when it ends.
+func (m *Middleware) Stream(next client.MiddlewareStreamHandler) client.MiddlewareStreamHandler {
+	return func(ctx context.Context, service string, endpoint string, opts *client.CallOptions) (client.StreamIface[any, any], error) {
+		start := time.Now()
+
+		stream, err := next(ctx, service, endpoint, opts)
+		if err != nil {
+			m.record(ctx, streamKeys, service, endpoint, start, err)
+			return nil, err
+		}
+
+		return client.InterceptStream(stream, client.StreamInterceptor{
+			Done: func(err error) { m.record(ctx, streamKeys, service, endpoint, start, err) },
+		}), nil
+	}
+}
+
+func (m *Middleware) record(ctx context.Context, k keys, service, endpoint string, start time.Time, err error) {
+	transport := TransportUnknown
+	if infos, ok := client.RequestInfo(ctx); ok && infos.Transport != "" {
+		transport = infos.Transport
+	}
+
+	status := StatusOK
+	if err != nil {
+		status = strconv.Itoa(orberrors.From(err).Code)
+	}
+
+	labels := []metrics.Label{
+		{Name: "service", Value: service},
+		{Name: "endpoint", Value: endpoint},
+		{Name: "transport", Value: transport},
+		{Name: "status", Value: status},
+	}
+
+	m.metrics.IncrCounterWithLabels(k.requests, 1, labels)
+
+	if err != nil {
+		m.metrics.IncrCounterWithLabels(k.errors, 1, labels)
+	}
+
+	m.metrics.MeasureSinceWithLabels(k.duration, start, labels)
+}
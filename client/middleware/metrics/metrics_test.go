@@ -0,0 +1,165 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-orb/go-orb/client"
+	"github.com/go-orb/go-orb/log"
+	"github.com/go-orb/go-orb/metrics"
+	"github.com/go-orb/go-orb/util/orberrors"
+)
+
+// recorder implements the metrics methods used by the middleware.
+type recorder struct {
+	metrics.Metrics
+
+	mu       sync.Mutex
+	counters map[string]float32
+	samples  map[string]int
+}
+
+func newRecorder() *recorder {
+	return &recorder{counters: make(map[string]float32), samples: make(map[string]int)}
+}
+
+func key(name []string, labels []metrics.Label) string {
+	parts := []string{strings.Join(name, ".")}
+	for _, l := range labels {
+		parts = append(parts, l.Name+"="+l.Value)
+	}
+
+	return strings.Join(parts, ",")
+}
+
+func (r *recorder) IncrCounterWithLabels(name []string, val float32, labels []metrics.Label) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.counters[key(name, labels)] += val
+}
+
+func (r *recorder) MeasureSinceWithLabels(name []string, _ time.Time, labels []metrics.Label) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.samples[key(name, labels)]++
+}
+
+// eofStream is a stream which ends on the first Recv.
+type eofStream struct {
+	client.StreamIface[any, any]
+}
+
+func (eofStream) Recv(any) error { return io.EOF }
+
+func TestProvide(t *testing.T) {
+	logger := log.Logger{Logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+
+	if _, err := Provide(nil, client.Type{}, logger); !errors.Is(err, ErrNoMetrics) {
+		t.Fatalf("expected ErrNoMetrics from the default provider, got %v", err)
+	}
+
+	r := newRecorder()
+
+	mw, err := ProvideWithMetrics(r)(nil, client.Type{}, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if m, ok := mw.(*Middleware); !ok || m.metrics != r {
+		t.Fatal("expected the middleware to report to the given metrics")
+	}
+}
+
+func TestRequest(t *testing.T) {
+	r := newRecorder()
+
+	handler := New(r).Request(func(ctx context.Context, _, _ string, req, _ any, _ *client.CallOptions) error {
+		if infos, ok := ctx.Value(client.RequestInfosKey{}).(*client.RequestInfos); ok {
+			infos.Transport = "grpc"
+		}
+
+		if req == "fail" {
+			return orberrors.ErrUnavailable
+		}
+
+		return nil
+	})
+
+	for _, req := range []string{"ok", "ok", "fail"} {
+		ctx := context.WithValue(context.Background(), client.RequestInfosKey{}, &client.RequestInfos{})
+		_ = handler(ctx, "svc", "Foo.Bar", req, nil, &client.CallOptions{}) //nolint:errcheck
+	}
+
+	// Without request infos the transport is unknown.
+	_ = handler(context.Background(), "svc", "Foo.Bar", "ok", nil, &client.CallOptions{}) //nolint:errcheck
+
+	labels := "service=svc,endpoint=Foo.Bar,transport=grpc,status="
+
+	expected := map[string]float32{
+		"client.requests," + labels + "ok":                                         2,
+		"client.requests," + labels + "503":                                        1,
+		"client.errors," + labels + "503":                                          1,
+		"client.requests,service=svc,endpoint=Foo.Bar,transport=unknown,status=ok": 1,
+	}
+
+	if len(r.counters) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, r.counters)
+	}
+
+	for k, v := range expected {
+		if r.counters[k] != v {
+			t.Fatalf("expected %s to be %v, got %v", k, v, r.counters[k])
+		}
+	}
+
+	if n := r.samples["client.latency,"+labels+"ok"]; n != 2 {
+		t.Fatalf("expected 2 latency samples, got %d", n)
+	}
+}
+
+func TestStream(t *testing.T) {
+	r := newRecorder()
+	m := New(r)
+
+	// A stream which fails to open gets recorded right away.
+	_, err := m.Stream(func(context.Context, string, string, *client.CallOptions) (client.StreamIface[any, any], error) {
+		return nil, orberrors.ErrUnavailable
+	})(context.Background(), "svc", "Foo.Bar", &client.CallOptions{})
+	if !errors.Is(err, orberrors.ErrUnavailable) {
+		t.Fatalf("expected the error of the handler, got %v", err)
+	}
+
+	labels := "service=svc,endpoint=Foo.Bar,transport=unknown,status="
+
+	if r.counters["client.streams.errors,"+labels+"503"] != 1 {
+		t.Fatalf("expected the failed stream to be recorded, got %v", r.counters)
+	}
+
+	// An open stream gets recorded when it ends.
+	stream, err := m.Stream(func(context.Context, string, string, *client.CallOptions) (client.StreamIface[any, any], error) {
+		return eofStream{}, nil
+	})(context.Background(), "svc", "Foo.Bar", &client.CallOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if r.counters["client.streams.requests,"+labels+"ok"] != 0 {
+		t.Fatal("expected the stream not to be recorded before it ended")
+	}
+
+	if err := stream.Recv(nil); !errors.Is(err, io.EOF) {
+		t.Fatalf("expected io.EOF, got %v", err)
+	}
+
+	if r.counters["client.streams.requests,"+labels+"ok"] != 1 || r.samples["client.streams.duration,"+labels+"ok"] != 1 {
+		t.Fatalf("expected the ended stream to be recorded, got %v and %v", r.counters, r.samples)
+	}
+}
@@ -0,0 +1,88 @@
+// Package propagation provides a client middleware which forwards incoming metadata
+// to outgoing requests, see metadata.Propagation.
+//
+// It forwards what the "propagation" server middleware has taken from the incoming
+// request, and the incoming metadata selected by its own config.
+package propagation
+
+import (
+	"context"
+	"errors"
+
+	"github.com/go-orb/go-orb/client"
+	"github.com/go-orb/go-orb/config"
+	"github.com/go-orb/go-orb/log"
+	"github.com/go-orb/go-orb/util/metadata"
+)
+
+// Name is the name of this middleware.
+const Name = "propagation"
+
+var (
+	_ client.Middleware       = (*Middleware)(nil)
+	_ client.StreamMiddleware = (*Middleware)(nil)
+)
+
+func init() {
+	client.Middlewares.Add(Name, Provide)
+}
+
+// Config is the config of the propagation middleware.
+type Config struct {
+	metadata.Propagation `yaml:",inline"`
+}
+
+// Middleware is the client propagation middleware.
+type Middleware struct {
+	config Config
+}
+
+// Provide creates a new propagation middleware from the config.
+func Provide(configData map[string]any, _ client.Type, _ log.Logger) (client.Middleware, error) {
+	cfg := Config{}
+
+	if err := config.Parse(nil, "", configData, &cfg); err != nil && !errors.Is(err, config.ErrNoSuchKey) {
+		return nil, err
+	}
+
+	return New(cfg), nil
+}
+
+// New creates a new propagation middleware.
+func New(cfg Config) *Middleware {
+	return &Middleware{config: cfg}
+}
+
+// Start is a no-op.
+func (m *Middleware) Start(_ context.Context) error {
+	return nil
+}
+
+// Stop is a no-op.
+func (m *Middleware) Stop(_ context.Context) error {
+	return nil
+}
+
+// Type returns the component type.
+func (m *Middleware) Type() string {
+	return client.MiddlewareComponentType
+}
+
+// String returns the name of this middleware.
+func (m *Middleware) String() string {
+	return Name
+}
+
+// Request adds the propagated metadata to a copy of the outgoing metadata.
+func (m *Middleware) Request(next client.MiddlewareRequestHandler) client.MiddlewareRequestHandler {
+	return func(ctx context.Context, service string, endpoint string, req any, result any, opts *client.CallOptions) error {
+		return next(metadata.WithPropagated(ctx, m.config.Propagation), service, endpoint, req, result, opts)
+	}
+}
+
+// Stream adds the propagated metadata to a copy of the outgoing metadata of the stream.
+func (m *Middleware) Stream(next client.MiddlewareStreamHandler) client.MiddlewareStreamHandler {
+	return func(ctx context.Context, service string, endpoint string, opts *client.CallOptions) (client.StreamIface[any, any], error) {
+		return next(metadata.WithPropagated(ctx, m.config.Propagation), service, endpoint, opts)
+	}
+}
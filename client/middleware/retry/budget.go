@@ -0,0 +1,43 @@
+package retry
+
+import "sync"
+
+// budget is a token bucket which limits retries to a fraction of the requests.
+// Each request deposits ratio tokens, each retry withdraws one token.
+type budget struct {
+	ratio     float64
+	maxTokens float64
+
+	mu     sync.Mutex
+	tokens float64
+}
+
+func newBudget(ratio, maxTokens float64) *budget {
+	return &budget{
+		ratio:     ratio,
+		maxTokens: maxTokens,
+		tokens:    maxTokens,
+	}
+}
+
+// deposit is called for each request.
+func (b *budget) deposit() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.tokens = min(b.tokens+b.ratio, b.maxTokens)
+}
+
+// withdraw takes a token for a retry, it returns false if the budget has been exhausted.
+func (b *budget) withdraw() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.tokens < 1 {
+		return false
+	}
+
+	b.tokens--
+
+	return true
+}
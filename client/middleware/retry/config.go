@@ -0,0 +1,98 @@
+package retry
+
+import (
+	"time"
+
+	"github.com/go-orb/go-orb/config"
+)
+
+//nolint:gochecknoglobals
+var (
+	// DefaultRetries is the number of retries after the first attempt, when the call doesn't set client.WithRetries.
+	DefaultRetries = 3
+	// DefaultInitialBackoff is the backoff before the first retry.
+	DefaultInitialBackoff = config.Duration(50 * time.Millisecond)
+	// DefaultMaxBackoff is the upper limit of the backoff.
+	DefaultMaxBackoff = config.Duration(2 * time.Second)
+	// DefaultMultiplier is the factor the backoff grows by on each retry.
+	DefaultMultiplier = 2.0
+	// DefaultJitter is the fraction of the backoff which gets randomized.
+	DefaultJitter = 0.2
+	// DefaultBudgetRatio is the number of retry tokens each request adds to the budget,
+	// 0.1 allows on average one retry for every 10 requests.
+	DefaultBudgetRatio = 0.1
+	// DefaultBudgetMax is the maximum number of retry tokens in the budget of a service.
+	DefaultBudgetMax = 10.0
+)
+
+// Config is the config of the retry middleware.
+type Config struct {
+	// Retries is the number of retries after the first attempt, when the call doesn't set client.WithRetries.
+	Retries int `json:"retries,omitempty" yaml:"retries,omitempty"`
+	// InitialBackoff is the backoff before the first retry.
+	InitialBackoff config.Duration `json:"initialBackoff,omitempty" yaml:"initialBackoff,omitempty"`
+	// MaxBackoff is the upper limit of the backoff.
+	MaxBackoff config.Duration `json:"maxBackoff,omitempty" yaml:"maxBackoff,omitempty"`
+	// Multiplier is the factor the backoff grows by on each retry.
+	Multiplier float64 `json:"multiplier,omitempty" yaml:"multiplier,omitempty"`
+	// Jitter is the fraction of the backoff which gets randomized, between 0 and 1.
+	Jitter float64 `json:"jitter,omitempty" yaml:"jitter,omitempty"`
+	// BudgetRatio is the number of retry tokens each request adds to the budget of its service,
+	// each retry takes one token. A negative value disables the budget.
+	BudgetRatio float64 `json:"budgetRatio,omitempty" yaml:"budgetRatio,omitempty"`
+	// BudgetMax is the maximum number of retry tokens in the budget of a service, the budget starts full.
+	BudgetMax float64 `json:"budgetMax,omitempty" yaml:"budgetMax,omitempty"`
+}
+
+// Option is a functional option for the retry middleware.
+type Option func(*Config)
+
+// WithRetries sets the default number of retries.
+func WithRetries(n int) Option {
+	return func(c *Config) {
+		c.Retries = n
+	}
+}
+
+// WithBackoff sets the initial and the maximum backoff and the multiplier.
+func WithBackoff(initial, maxBackoff time.Duration, multiplier float64) Option {
+	return func(c *Config) {
+		c.InitialBackoff = config.Duration(initial)
+		c.MaxBackoff = config.Duration(maxBackoff)
+		c.Multiplier = multiplier
+	}
+}
+
+// WithJitter sets the fraction of the backoff which gets randomized.
+func WithJitter(n float64) Option {
+	return func(c *Config) {
+		c.Jitter = n
+	}
+}
+
+// WithBudget sets the retry budget, a negative ratio disables it.
+func WithBudget(ratio float64, maxTokens float64) Option {
+	return func(c *Config) {
+		c.BudgetRatio = ratio
+		c.BudgetMax = maxTokens
+	}
+}
+
+// NewConfig creates a new config with the defaults and applies opts on top.
+func NewConfig(opts ...Option) Config {
+	cfg := Config{
+		Retries:        DefaultRetries,
+		InitialBackoff: DefaultInitialBackoff,
+		MaxBackoff:     DefaultMaxBackoff,
+		Multiplier:     DefaultMultiplier,
+		Jitter:         DefaultJitter,
+		BudgetRatio:    DefaultBudgetRatio,
+		BudgetMax:      DefaultBudgetMax,
+	}
+
+	for _, o := range opts {
+		o(&cfg)
+	}
+
+	return cfg
+}
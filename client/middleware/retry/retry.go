@@ -0,0 +1,202 @@
+// Package retry provides a client middleware which retries failed requests
+// with exponential backoff and jitter.
+//
+// The number of retries is taken from client.CallOptions.Retries, the decision
+// whether to retry from client.CallOptions.RetryFunc. Without them the config
+// of this middleware and DefaultRetryFunc are used.
+//
+// A retry budget per service limits retries to a fraction of the requests,
+// so retries cannot multiply the load on a service which is already down.
+package retry
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/go-orb/go-orb/client"
+	"github.com/go-orb/go-orb/config"
+	"github.com/go-orb/go-orb/log"
+	"github.com/go-orb/go-orb/util/orberrors"
+)
+
+// Name is the name of this middleware.
+const Name = "retry"
+
+var _ client.Middleware = (*Middleware)(nil)
+
+func init() {
+	client.Middlewares.Add(Name, Provide)
+}
+
+// DefaultRetryFunc retries requests which failed with 503 Service Unavailable,
+// 408 Request Timeout or a connection error. Other errors may have had side-effects
+// on the server, so they are not safe to retry.
+func DefaultRetryFunc(ctx context.Context, err error, _ *client.CallOptions) (bool, error) {
+	if err == nil || ctx.Err() != nil {
+		return false, nil
+	}
+
+	if IsConnectionError(err) {
+		return true, nil
+	}
+
+	switch orberrors.From(err).Code {
+	case http.StatusServiceUnavailable, http.StatusRequestTimeout:
+		return true, nil
+	default:
+		return false, nil
+	}
+}
+
+// IsConnectionError returns true if err happened while connecting to a node.
+func IsConnectionError(err error) bool {
+	if errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+
+	var opErr *net.OpError
+
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// Middleware is the retry middleware.
+type Middleware struct {
+	config Config
+	logger log.Logger
+
+	mu      sync.Mutex
+	budgets map[string]*budget
+}
+
+// Provide creates a new retry middleware from the config.
+func Provide(configData map[string]any, _ client.Type, logger log.Logger) (client.Middleware, error) {
+	cfg := NewConfig()
+
+	if err := config.Parse(nil, "", configData, &cfg); err != nil && !errors.Is(err, config.ErrNoSuchKey) {
+		return nil, err
+	}
+
+	return New(cfg, logger), nil
+}
+
+// New creates a new retry middleware.
+func New(cfg Config, logger log.Logger) *Middleware {
+	return &Middleware{
+		config:  cfg,
+		logger:  logger.With("middleware", Name),
+		budgets: make(map[string]*budget),
+	}
+}
+
+// Start is a no-op.
+func (m *Middleware) Start(_ context.Context) error {
+	return nil
+}
+
+// Stop is a no-op.
+func (m *Middleware) Stop(_ context.Context) error {
+	return nil
+}
+
+// Type returns the component type.
+func (m *Middleware) Type() string {
+	return client.MiddlewareComponentType
+}
+
+// String returns the name of this middleware.
+func (m *Middleware) String() string {
+	return Name
+}
+
+// Request retries next while the RetryFunc allows it and the budget of the service has tokens left.
+func (m *Middleware) Request(next client.MiddlewareRequestHandler) client.MiddlewareRequestHandler {
+	return func(ctx context.Context, service string, endpoint string, req any, result any, opts *client.CallOptions) error {
+		retries := opts.Retries
+		if retries <= 0 {
+			retries = m.config.Retries
+		}
+
+		retryFunc := opts.RetryFunc
+		if retryFunc == nil {
+			retryFunc = DefaultRetryFunc
+		}
+
+		b := m.budget(service)
+		if b != nil {
+			b.deposit()
+		}
+
+		var err error
+
+		for attempt := 0; ; attempt++ {
+			err = next(ctx, service, endpoint, req, result, opts)
+			if err == nil || attempt >= retries {
+				return err
+			}
+
+			retry, rErr := retryFunc(ctx, err, opts)
+			if rErr != nil {
+				return rErr
+			}
+
+			if !retry {
+				return err
+			}
+
+			if b != nil && !b.withdraw() {
+				m.logger.Debug("retry budget exhausted", "service", service, "endpoint", endpoint, "error", err)
+				return err
+			}
+
+			timer := time.NewTimer(m.backoff(attempt))
+
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return err
+			case <-timer.C:
+			}
+		}
+	}
+}
+
+// backoff returns the time to wait before retry number attempt+1.
+func (m *Middleware) backoff(attempt int) time.Duration {
+	d := float64(m.config.InitialBackoff) * math.Pow(m.config.Multiplier, float64(attempt))
+	d = min(d, float64(m.config.MaxBackoff))
+
+	if m.config.Jitter > 0 {
+		jitter := min(m.config.Jitter, 1)
+		d -= d * jitter * rand.Float64() //nolint:gosec
+	}
+
+	return time.Duration(d)
+}
+
+// budget returns the retry budget of service, nil if budgets are disabled.
+func (m *Middleware) budget(service string) *budget {
+	if m.config.BudgetRatio < 0 {
+		return nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	b, ok := m.budgets[service]
+	if !ok {
+		b = newBudget(m.config.BudgetRatio, m.config.BudgetMax)
+		m.budgets[service] = b
+	}
+
+	return b
+}
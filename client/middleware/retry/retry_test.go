@@ -0,0 +1,157 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/go-orb/go-orb/client"
+	"github.com/go-orb/go-orb/log"
+	"github.com/go-orb/go-orb/util/orberrors"
+)
+
+func newTestMiddleware(opts ...Option) *Middleware {
+	opts = append([]Option{WithBackoff(time.Microsecond, time.Millisecond, 2)}, opts...)
+	return New(NewConfig(opts...), log.Logger{Logger: slog.New(slog.NewTextHandler(io.Discard, nil))})
+}
+
+// failing returns a handler which fails n times with err and counts its calls.
+func failing(n int, err error, calls *int) client.MiddlewareRequestHandler {
+	return func(_ context.Context, _ string, _ string, _ any, _ any, _ *client.CallOptions) error {
+		*calls++
+		if *calls <= n {
+			return err
+		}
+
+		return nil
+	}
+}
+
+func TestRequest(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		failures int
+		err      error
+		config   []Option
+		opts     client.CallOptions
+		calls    int
+		fails    bool
+	}{
+		{name: "success", failures: 0, err: orberrors.ErrUnavailable, calls: 1},
+		{name: "retried", failures: 2, err: orberrors.ErrUnavailable, calls: 3},
+		{name: "retries exhausted", failures: 5, err: orberrors.ErrUnavailable, calls: 4, fails: true},
+		{name: "not retryable", failures: 5, err: orberrors.ErrBadRequest, calls: 1, fails: true},
+		{name: "call options retries", failures: 5, err: orberrors.ErrUnavailable, opts: client.CallOptions{Retries: 1}, calls: 2, fails: true},
+		{name: "config retries", failures: 5, err: orberrors.ErrUnavailable, config: []Option{WithRetries(4)}, calls: 5, fails: true},
+		{
+			name: "call options retry func", failures: 5, err: orberrors.ErrBadRequest, calls: 4, fails: true,
+			opts: client.CallOptions{RetryFunc: func(context.Context, error, *client.CallOptions) (bool, error) { return true, nil }},
+		},
+		{name: "budget exhausted", failures: 5, err: orberrors.ErrUnavailable, config: []Option{WithBudget(0.1, 1)}, calls: 2, fails: true},
+		{name: "budget disabled", failures: 3, err: orberrors.ErrUnavailable, config: []Option{WithBudget(-1, 0)}, calls: 4},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			calls := 0
+			handler := newTestMiddleware(tc.config...).Request(failing(tc.failures, tc.err, &calls))
+
+			err := handler(context.Background(), "svc", "ep", nil, nil, &tc.opts)
+			if (err != nil) != tc.fails {
+				t.Fatalf("expected failure %t, got %v", tc.fails, err)
+			}
+
+			if calls != tc.calls {
+				t.Fatalf("expected %d calls, got %d", tc.calls, calls)
+			}
+		})
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	m := newTestMiddleware(WithBackoff(10*time.Millisecond, 50*time.Millisecond, 2), WithJitter(0.5))
+
+	for attempt, expected := range []time.Duration{10, 20, 40, 50, 50} {
+		expected *= time.Millisecond
+
+		for range 100 {
+			if d := m.backoff(attempt); d > expected || d < expected/2 {
+				t.Fatalf("attempt %d: expected a backoff between %s and %s, got %s", attempt, expected/2, expected, d)
+			}
+		}
+	}
+
+	m = newTestMiddleware(WithBackoff(10*time.Millisecond, 50*time.Millisecond, 2), WithJitter(0))
+	if d := m.backoff(1); d != 20*time.Millisecond {
+		t.Fatalf("expected no jitter, got %s", d)
+	}
+}
+
+func TestBudget(t *testing.T) {
+	b := newBudget(0.5, 2)
+
+	for range 2 {
+		if !b.withdraw() {
+			t.Fatal("expected the full budget to allow a retry")
+		}
+	}
+
+	if b.withdraw() {
+		t.Fatal("expected the budget to be exhausted")
+	}
+
+	b.deposit()
+
+	if b.withdraw() {
+		t.Fatal("expected half a token not to allow a retry")
+	}
+
+	b.deposit()
+
+	if !b.withdraw() {
+		t.Fatal("expected two requests to allow a retry")
+	}
+
+	for range 10 {
+		b.deposit()
+	}
+
+	if b.tokens != 2 {
+		t.Fatalf("expected the budget to be capped at 2 tokens, got %f", b.tokens)
+	}
+}
+
+func TestDefaultRetryFunc(t *testing.T) {
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	for _, tc := range []struct {
+		ctx      context.Context //nolint:containedctx
+		err      error
+		expected bool
+	}{
+		{context.Background(), nil, false},
+		{context.Background(), orberrors.ErrUnavailable, true},
+		{context.Background(), orberrors.ErrRequestTimeout, true},
+		{context.Background(), orberrors.ErrBadRequest, false},
+		{context.Background(), orberrors.ErrInternalServerError, false},
+		{context.Background(), fmt.Errorf("send: %w", syscall.ECONNREFUSED), true},
+		{context.Background(), io.ErrUnexpectedEOF, true},
+		{context.Background(), &net.OpError{Op: "dial", Err: errors.New("no route")}, true},
+		{context.Background(), &net.OpError{Op: "read", Err: errors.New("timeout")}, false},
+		{canceled, orberrors.ErrUnavailable, false},
+	} {
+		retry, err := DefaultRetryFunc(tc.ctx, tc.err, &client.CallOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if retry != tc.expected {
+			t.Fatalf("%v: expected retry %t, got %t", tc.err, tc.expected, retry)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+// Package selector provides a client middleware which reports the outcome
+// of requests back to stateful selectors, see client.SelectorReporter.
+//
+// Add it as last middleware, so it measures a single request to a single node.
+// Nodes selected more than once within it, e.g. by retries of the transport,
+// all get the outcome of the whole request.
+package selector
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-orb/go-orb/client"
+	"github.com/go-orb/go-orb/log"
+)
+
+// Name is the name of this middleware.
+const Name = "selector"
+
+var _ client.Middleware = (*Middleware)(nil)
+
+func init() {
+	client.Middlewares.Add(Name, Provide)
+}
+
+// Middleware reports the duration and error of each request to the selector which selected its node.
+type Middleware struct{}
+
+// Provide creates a new selector middleware.
+func Provide(_ map[string]any, _ client.Type, _ log.Logger) (client.Middleware, error) {
+	return New(), nil
+}
+
+// New creates a new selector middleware.
+func New() *Middleware {
+	return &Middleware{}
+}
+
+// Start is a no-op.
+func (m *Middleware) Start(_ context.Context) error {
+	return nil
+}
+
+// Stop is a no-op.
+func (m *Middleware) Stop(_ context.Context) error {
+	return nil
+}
+
+// Type returns the component type.
+func (m *Middleware) Type() string {
+	return client.MiddlewareComponentType
+}
+
+// String returns the name of this middleware.
+func (m *Middleware) String() string {
+	return Name
+}
+
+// Request measures the request and reports it to the selectors which selected a node for it.
+func (m *Middleware) Request(next client.MiddlewareRequestHandler) client.MiddlewareRequestHandler {
+	return func(ctx context.Context, service string, endpoint string, req any, result any, opts *client.CallOptions) error {
+		ctx, reports := client.WithSelectorReports(ctx)
+
+		start := time.Now()
+		err := next(ctx, service, endpoint, req, result, opts)
+
+		reports.Report(time.Since(start), err)
+
+		return err
+	}
+}
@@ -0,0 +1,160 @@
+// Package tracing provides a client middleware which traces requests and streams,
+// it sends the trace context to the server in the W3C traceparent and tracestate
+// metadata keys.
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/go-orb/go-orb/client"
+	"github.com/go-orb/go-orb/config"
+	"github.com/go-orb/go-orb/log"
+	"github.com/go-orb/go-orb/tracing"
+)
+
+// Name is the name of this middleware.
+const Name = "tracing"
+
+var (
+	_ client.Middleware       = (*Middleware)(nil)
+	_ client.StreamMiddleware = (*Middleware)(nil)
+)
+
+func init() {
+	client.Middlewares.Add(Name, Provide)
+}
+
+// Middleware is the client tracing middleware.
+type Middleware struct {
+	tracer *tracing.Tracer
+	// owned is set if the middleware created the tracer, it starts and stops it then.
+	owned bool
+}
+
+// Provide creates a tracing middleware with a tracer from its config, which takes the
+// options of the "tracing" section. It fails with tracing.ErrNoExporter if there's no
+// exporter in the config, use ProvideWithTracer to share the tracer of the service.
+func Provide(configData map[string]any, _ client.Type, logger log.Logger) (client.Middleware, error) {
+	cfg := tracing.NewConfig()
+
+	if err := config.Parse(nil, "", configData, &cfg); err != nil && !errors.Is(err, config.ErrNoSuchKey) {
+		return nil, err
+	}
+
+	if cfg.Exporter == "" {
+		return nil, fmt.Errorf("client middleware %s: %w", Name, tracing.ErrNoExporter)
+	}
+
+	tracer, err := tracing.NewFromConfig(cfg, configData, logger.With("middleware", Name))
+	if err != nil {
+		return nil, err
+	}
+
+	return &Middleware{tracer: tracer, owned: true}, nil
+}
+
+// ProvideWithTracer returns a factory whose middlewares record spans with t instead of
+// a tracer of their own, for example the component from tracing.Provide. They don't start or stop t.
+func ProvideWithTracer(t *tracing.Tracer) client.MiddlewareFactory {
+	return func(_ map[string]any, _ client.Type, _ log.Logger) (client.Middleware, error) {
+		return New(t), nil
+	}
+}
+
+// New creates a new tracing middleware, tracer may be nil to only forward the trace context of the caller.
+func New(tracer *tracing.Tracer) *Middleware {
+	return &Middleware{tracer: tracer}
+}
+
+// Start starts the tracer if the middleware created it.
+func (m *Middleware) Start(ctx context.Context) error {
+	if !m.owned {
+		return nil
+	}
+
+	return m.tracer.Start(ctx)
+}
+
+// Stop exports the buffered spans of the tracer if the middleware created it.
+func (m *Middleware) Stop(ctx context.Context) error {
+	if !m.owned {
+		return nil
+	}
+
+	return m.tracer.Stop(ctx)
+}
+
+// Type returns the component type.
+func (m *Middleware) Type() string {
+	return client.MiddlewareComponentType
+}
+
+// String returns the name of this middleware.
+func (m *Middleware) String() string {
+	return Name
+}
+
+// Request records a client span around the request.
+func (m *Middleware) Request(next client.MiddlewareRequestHandler) client.MiddlewareRequestHandler {
+	return func(ctx context.Context, service string, endpoint string, req any, result any, opts *client.CallOptions) error {
+		ctx, span := m.start(ctx, service, endpoint)
+		defer span.End()
+
+		err := next(tracing.InjectOutgoing(ctx), service, endpoint, req, result, opts)
+
+		annotate(ctx, span)
+		span.SetError(err)
+
+		return err
+	}
+}
+
+// Stream records a client span, which ends when the stream does.
+func (m *Middleware) Stream(next client.MiddlewareStreamHandler) client.MiddlewareStreamHandler {
+	return func(ctx context.Context, service string, endpoint string, opts *client.CallOptions) (client.StreamIface[any, any], error) {
+		ctx, span := m.start(ctx, service, endpoint)
+
+		end := func(err error) {
+			annotate(ctx, span)
+			span.SetError(err)
+			span.End()
+		}
+
+		stream, err := next(tracing.InjectOutgoing(ctx), service, endpoint, opts)
+		if err != nil {
+			end(err)
+			return nil, err
+		}
+
+		return client.InterceptStream(stream, client.StreamInterceptor{Done: end}), nil
+	}
+}
+
+func (m *Middleware) start(ctx context.Context, service, endpoint string) (context.Context, *tracing.Span) {
+	return m.tracer.StartSpan(ctx, service+"/"+endpoint,
+		tracing.WithKind(tracing.KindClient),
+		tracing.WithAttribute(tracing.AttrService, service),
+		tracing.WithAttribute(tracing.AttrEndpoint, endpoint),
+	)
+}
+
+// annotate adds the request infos the client has filled in to span.
+func annotate(ctx context.Context, span *tracing.Span) {
+	infos, ok := client.RequestInfo(ctx)
+	if !ok {
+		return
+	}
+
+	for key, value := range map[string]string{
+		tracing.AttrTransport: infos.Transport,
+		tracing.AttrAddress:   infos.Address,
+		tracing.AttrTier:      infos.Tier,
+		tracing.AttrRegion:    infos.Region,
+	} {
+		if value != "" {
+			span.SetAttribute(key, value)
+		}
+	}
+}
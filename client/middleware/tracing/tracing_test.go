@@ -0,0 +1,162 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/go-orb/go-orb/client"
+	"github.com/go-orb/go-orb/codecs"
+	"github.com/go-orb/go-orb/log"
+	"github.com/go-orb/go-orb/tracing"
+	"github.com/go-orb/go-orb/tracing/memory"
+	"github.com/go-orb/go-orb/util/metadata"
+	"github.com/go-orb/go-orb/util/orberrors"
+)
+
+// jsonCodec is a minimal JSON codec, config.Parse needs one.
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v any) ([]byte, error)         { return json.Marshal(v) }
+func (jsonCodec) Unmarshal(data []byte, v any) error    { return json.Unmarshal(data, v) }
+func (jsonCodec) Marshals(any) bool                     { return true }
+func (jsonCodec) Unmarshals(any) bool                   { return true }
+func (jsonCodec) NewDecoder(r io.Reader) codecs.Decoder { return json.NewDecoder(r) }
+func (jsonCodec) NewEncoder(w io.Writer) codecs.Encoder { return json.NewEncoder(w) }
+func (jsonCodec) ContentTypes() []string                { return []string{codecs.MimeJSON} }
+func (jsonCodec) Name() string                          { return "json" }
+func (jsonCodec) Exts() []string                        { return []string{".json"} }
+
+func init() {
+	codecs.Register("json", jsonCodec{})
+}
+
+func testLogger() log.Logger {
+	return log.Logger{Logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+}
+
+func TestProvide(t *testing.T) {
+	if _, err := Provide(map[string]any{"name": Name}, client.Type{}, testLogger()); !errors.Is(err, tracing.ErrNoExporter) {
+		t.Fatalf("expected ErrNoExporter, got %v", err)
+	}
+
+	if _, err := Provide(map[string]any{"name": Name, "exporter": "unknown"}, client.Type{}, testLogger()); err == nil {
+		t.Fatal("expected an unknown exporter to fail")
+	}
+
+	mw, err := Provide(map[string]any{"name": Name, "exporter": memory.Name, "sampleRatio": 0}, client.Type{}, testLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := mw.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	m, ok := mw.(*Middleware)
+	if !ok || m.tracer == nil {
+		t.Fatal("expected a middleware with a tracer")
+	}
+
+	// Not sampled spans still get propagated.
+	ctx, span := m.start(context.Background(), "svc", "ep")
+	if !span.SpanContext().IsValid() || span.SpanContext().IsSampled() {
+		t.Fatalf("expected a valid span which isn't sampled, got %+v", span.SpanContext())
+	}
+
+	if _, ok := tracing.SpanContextFromContext(ctx); !ok {
+		t.Fatal("expected the span in the context")
+	}
+
+	if err := mw.Stop(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRequest(t *testing.T) {
+	exporter := memory.New()
+	m := New(tracing.New(tracing.NewConfig(), exporter, testLogger()))
+
+	parentCtx, parent := m.tracer.StartSpan(context.Background(), "parent")
+
+	var traceparent string
+
+	err := m.Request(func(ctx context.Context, _, _ string, _, _ any, _ *client.CallOptions) error {
+		traceparent, _ = metadata.GetOutgoing(ctx, tracing.TraceparentKey)
+
+		if infos, ok := ctx.Value(client.RequestInfosKey{}).(*client.RequestInfos); ok {
+			infos.Transport = "grpc"
+		}
+
+		return orberrors.ErrUnavailable
+	})(context.WithValue(parentCtx, client.RequestInfosKey{}, &client.RequestInfos{}), "svc", "ep", nil, nil, &client.CallOptions{})
+	if !errors.Is(err, orberrors.ErrUnavailable) {
+		t.Fatalf("expected the error of the handler, got %v", err)
+	}
+
+	spans := exporter.Spans()
+	if len(spans) != 1 {
+		t.Fatalf("expected 1 span, got %d", len(spans))
+	}
+
+	span := spans[0]
+	if span.Name != "svc/ep" || span.Kind != tracing.KindClient || span.Parent != parent.SpanContext().SpanID {
+		t.Fatalf("expected a client span below the parent, got %+v", span)
+	}
+
+	if traceparent != span.SpanContext.Traceparent() {
+		t.Fatalf("expected the span to be sent to the server, got %q", traceparent)
+	}
+
+	if span.Attributes[tracing.AttrTransport] != "grpc" || !errors.Is(span.Err, orberrors.ErrUnavailable) {
+		t.Fatalf("expected the transport and the error on the span, got %+v", span)
+	}
+
+	// The parents outgoing metadata doesn't change.
+	if _, ok := metadata.GetOutgoing(parentCtx, tracing.TraceparentKey); ok {
+		t.Fatal("expected the parents metadata to be untouched")
+	}
+}
+
+func TestStream(t *testing.T) {
+	exporter := memory.New()
+	m := New(tracing.New(tracing.NewConfig(), exporter, testLogger()))
+
+	_, err := m.Stream(func(context.Context, string, string, *client.CallOptions) (client.StreamIface[any, any], error) {
+		return nil, orberrors.ErrUnavailable
+	})(context.Background(), "svc", "ep", &client.CallOptions{})
+	if !errors.Is(err, orberrors.ErrUnavailable) {
+		t.Fatalf("expected the error of the handler, got %v", err)
+	}
+
+	spans := exporter.Spans()
+	if len(spans) != 1 || !errors.Is(spans[0].Err, orberrors.ErrUnavailable) {
+		t.Fatalf("expected the failed stream to end its span, got %+v", spans)
+	}
+}
+
+func TestNilTracer(t *testing.T) {
+	ctx := tracing.ContextWithRemoteSpanContext(context.Background(), tracing.SpanContext{
+		TraceID: tracing.TraceID{1},
+		SpanID:  tracing.SpanID{1},
+		Flags:   tracing.FlagSampled,
+	})
+
+	var traceparent string
+
+	err := New(nil).Request(func(ctx context.Context, _, _ string, _, _ any, _ *client.CallOptions) error {
+		traceparent, _ = metadata.GetOutgoing(ctx, tracing.TraceparentKey)
+		return nil
+	})(ctx, "svc", "ep", nil, nil, &client.CallOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sc, _ := tracing.SpanContextFromContext(ctx)
+	if traceparent != sc.Traceparent() {
+		t.Fatalf("expected the callers trace context to be forwarded, got %q", traceparent)
+	}
+}
@@ -3,11 +3,23 @@ package client
 import (
 	"context"
 	"crypto/rand"
+	"math"
 	"math/big"
+	"strconv"
+	"sync"
+	"time"
 
 	"github.com/go-orb/go-orb/registry"
 )
 
+//nolint:gochecknoglobals
+var (
+	// DefaultWeightMetadataKey is the ServiceNode.Metadata key SelectWeightedRandomNode reads the weight from.
+	DefaultWeightMetadataKey = "weight"
+	// DefaultNodeWeight is the weight of nodes without a valid weight.
+	DefaultNodeWeight = 1.0
+)
+
 // SelectorFunc get's executed by client.SelectNode which get it's info's from client.ResolveService.
 type SelectorFunc func(
 	ctx context.Context,
@@ -15,6 +27,67 @@ type SelectorFunc func(
 	nodes []registry.ServiceNode,
 ) (registry.ServiceNode, error)
 
+// SelectorReporter is implemented by stateful selectors which learn from completed requests.
+//
+// Selectors register each selection with SetSelectorReporter, the "selector"
+// middleware reports the outcome with SelectorReports.Report.
+type SelectorReporter interface {
+	// Report is called once a request to address has been completed.
+	Report(service string, address string, duration time.Duration, err error)
+}
+
+type selectorReportsKey struct{}
+
+// selection is a node selected by a SelectorReporter.
+type selection struct {
+	reporter SelectorReporter
+	service  string
+	address  string
+}
+
+// SelectorReports collects the selections of stateful selectors for a request.
+type SelectorReports struct {
+	mu         sync.Mutex
+	selections []selection
+}
+
+// WithSelectorReports returns a copy of ctx which collects the selections of the request,
+// call Report once it's done.
+func WithSelectorReports(ctx context.Context) (context.Context, *SelectorReports) {
+	r := &SelectorReports{}
+	return context.WithValue(ctx, selectorReportsKey{}, r), r
+}
+
+// Report reports the outcome of the request to each selector which selected a node for it.
+// Selections get reported once, later calls only report new selections.
+func (r *SelectorReports) Report(duration time.Duration, err error) {
+	r.mu.Lock()
+	selections := r.selections
+	r.selections = nil
+	r.mu.Unlock()
+
+	for _, s := range selections {
+		s.reporter.Report(s.service, s.address, duration, err)
+	}
+}
+
+// SetSelectorReporter registers the selection of address by reporter for the request in ctx.
+//
+// It returns false if ctx doesn't collect selections, the outcome will never be
+// reported then, so the selector must not count the request as outstanding.
+func SetSelectorReporter(ctx context.Context, reporter SelectorReporter, service, address string) bool {
+	r, ok := ctx.Value(selectorReportsKey{}).(*SelectorReports)
+	if !ok || r == nil {
+		return false
+	}
+
+	r.mu.Lock()
+	r.selections = append(r.selections, selection{reporter: reporter, service: service, address: address})
+	r.mu.Unlock()
+
+	return true
+}
+
 // SelectRandomNode selects a random node.
 func SelectRandomNode(
 	_ context.Context,
@@ -28,3 +101,58 @@ func SelectRandomNode(
 
 	return nodes[rInt.Int64()], nil
 }
+
+// SelectWeightedRandomNode selects a random node, the chance of a node is proportional
+// to the weight in its metadata key DefaultWeightMetadataKey.
+func SelectWeightedRandomNode(
+	ctx context.Context,
+	service string,
+	nodes []registry.ServiceNode,
+) (registry.ServiceNode, error) {
+	if len(nodes) == 0 {
+		return registry.ServiceNode{}, ErrNoNodeFound
+	}
+
+	weights := make([]float64, len(nodes))
+	total := 0.0
+
+	for i, node := range nodes {
+		weights[i] = nodeWeight(node)
+		total += weights[i]
+	}
+
+	if total <= 0 {
+		return SelectRandomNode(ctx, service, nodes)
+	}
+
+	rInt, err := rand.Int(rand.Reader, big.NewInt(1<<53))
+	if err != nil {
+		return registry.ServiceNode{}, err
+	}
+
+	pick := float64(rInt.Int64()) / (1 << 53) * total
+
+	for i, w := range weights {
+		pick -= w
+		if pick < 0 {
+			return nodes[i], nil
+		}
+	}
+
+	return nodes[len(nodes)-1], nil
+}
+
+// nodeWeight returns the weight of a node, invalid, negative, infinite and missing weights are DefaultNodeWeight.
+func nodeWeight(node registry.ServiceNode) float64 {
+	v, ok := node.Metadata[DefaultWeightMetadataKey]
+	if !ok {
+		return DefaultNodeWeight
+	}
+
+	w, err := strconv.ParseFloat(v, 64)
+	if err != nil || w < 0 || math.IsNaN(w) || math.IsInf(w, 0) {
+		return DefaultNodeWeight
+	}
+
+	return w
+}
@@ -0,0 +1,88 @@
+package client
+
+import (
+	"context"
+	"hash/fnv"
+
+	"github.com/go-orb/go-orb/registry"
+	"github.com/go-orb/go-orb/util/metadata"
+)
+
+//nolint:gochecknoglobals
+var (
+	// DefaultHashKeyMetadataKey is the outgoing metadata key SelectHashNode reads the hash key from.
+	DefaultHashKeyMetadataKey = "hash-key"
+)
+
+type hashKeyKey struct{}
+
+// ContextWithHashKey returns a context which makes SelectHashNode select nodes by key.
+func ContextWithHashKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, hashKeyKey{}, key)
+}
+
+// HashKey returns the hash key of ctx, it's either set by ContextWithHashKey or
+// in the outgoing metadata with the key DefaultHashKeyMetadataKey.
+func HashKey(ctx context.Context) (string, bool) {
+	if key, ok := ctx.Value(hashKeyKey{}).(string); ok && key != "" {
+		return key, true
+	}
+
+	if key, ok := metadata.GetOutgoing(ctx, DefaultHashKeyMetadataKey); ok && key != "" {
+		return key, true
+	}
+
+	return "", false
+}
+
+// SelectHashNode selects the node by rendezvous hashing of the key from HashKey,
+// requests with the same key land on the same node. When a node leaves only the keys
+// of that node move, when a node joins it only takes keys from the others.
+//
+// Without a hash key it selects a random node.
+func SelectHashNode(
+	ctx context.Context,
+	service string,
+	nodes []registry.ServiceNode,
+) (registry.ServiceNode, error) {
+	if len(nodes) == 0 {
+		return registry.ServiceNode{}, ErrNoNodeFound
+	}
+
+	key, ok := HashKey(ctx)
+	if !ok {
+		return SelectRandomNode(ctx, service, nodes)
+	}
+
+	keyHash := hashString(key)
+	best := 0
+	bestScore := uint64(0)
+
+	for i, node := range nodes {
+		score := mix64(keyHash ^ hashString(node.ID()))
+		if i == 0 || score > bestScore {
+			best = i
+			bestScore = score
+		}
+	}
+
+	return nodes[best], nil
+}
+
+func hashString(s string) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(s)) //nolint:errcheck
+
+	return h.Sum64()
+}
+
+// mix64 is the splitmix64 finalizer, it spreads similar inputs over the whole range.
+func mix64(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+
+	return x
+}
@@ -0,0 +1,144 @@
+package client
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/go-orb/go-orb/registry"
+	"github.com/go-orb/go-orb/util/metadata"
+)
+
+// assign selects a node for each key and returns the address per key.
+func assign(t *testing.T, nodes []registry.ServiceNode, keys int) map[string]string {
+	t.Helper()
+
+	result := make(map[string]string, keys)
+
+	for i := range keys {
+		key := "key-" + strconv.Itoa(i)
+
+		node, err := SelectHashNode(ContextWithHashKey(context.Background(), key), "svc", nodes)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		result[key] = node.Address
+	}
+
+	return result
+}
+
+func TestSelectHashNode(t *testing.T) {
+	nodes := testNodes("a", "b", "c", "d")
+	first := assign(t, nodes, 1000)
+
+	// The same key lands on the same node, regardless of the order of the nodes.
+	again := assign(t, testNodes("d", "c", "b", "a"), 1000)
+
+	for k, v := range first {
+		if again[k] != v {
+			t.Fatalf("key %s moved from %s to %s", k, v, again[k])
+		}
+	}
+
+	// All nodes get a share of the keys.
+	counts := make(map[string]int)
+	for _, v := range first {
+		counts[v]++
+	}
+
+	for _, n := range nodes {
+		if counts[n.Address] < 150 {
+			t.Fatalf("expected about 250 keys on %s, got %d", n.Address, counts[n.Address])
+		}
+	}
+
+	// The key may come from the outgoing metadata.
+	ctx := metadata.NewOutgoing(context.Background(), metadata.Pairs(DefaultHashKeyMetadataKey, "key-1"))
+
+	node, err := SelectHashNode(ctx, "svc", nodes)
+	if err != nil || node.Address != first["key-1"] {
+		t.Fatalf("expected %s for the metadata key, got %s: %v", first["key-1"], node.Address, err)
+	}
+
+	if _, err := SelectHashNode(context.Background(), "svc", nil); err == nil {
+		t.Fatal("expected ErrNoNodeFound")
+	}
+}
+
+func TestSelectHashNodeMoves(t *testing.T) {
+	const keys = 2000
+
+	before := assign(t, testNodes("a", "b", "c", "d"), keys)
+
+	// Removing a node only moves its own keys, about 1/4 of them.
+	removed := assign(t, testNodes("a", "b", "c"), keys)
+	moved := 0
+
+	for k, v := range before {
+		if removed[k] == v {
+			continue
+		}
+
+		if v != "d" {
+			t.Fatalf("key %s moved from %s although its node is still there", k, v)
+		}
+
+		moved++
+	}
+
+	if moved < keys/4-keys/10 || moved > keys/4+keys/10 {
+		t.Fatalf("expected about %d keys to move, got %d", keys/4, moved)
+	}
+
+	// Adding a node only takes keys from the others, about 1/5 of them.
+	added := assign(t, testNodes("a", "b", "c", "d", "e"), keys)
+	moved = 0
+
+	for k, v := range before {
+		if added[k] == v {
+			continue
+		}
+
+		if added[k] != "e" {
+			t.Fatalf("key %s moved from %s to %s, not to the new node", k, v, added[k])
+		}
+
+		moved++
+	}
+
+	if moved < keys/5-keys/10 || moved > keys/5+keys/10 {
+		t.Fatalf("expected about %d keys to move, got %d", keys/5, moved)
+	}
+}
+
+func TestWithHashKey(t *testing.T) {
+	nodes := testNodes("a", "b", "c")
+
+	want, err := SelectHashNode(ContextWithHashKey(context.Background(), "user-1"), "svc", nodes)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	opts := &CallOptions{}
+	WithHashKey("user-1")(opts)
+
+	for range 5 {
+		node, err := opts.Selector(context.Background(), "svc", nodes)
+		if err != nil || node.Address != want.Address {
+			t.Fatalf("expected %s, got %s: %v", want.Address, node.Address, err)
+		}
+	}
+
+	// Like WithSelector, the last of both wins.
+	last := func(context.Context, string, []registry.ServiceNode) (registry.ServiceNode, error) {
+		return nodes[2], nil
+	}
+
+	WithSelector(last)(opts)
+
+	if node, _ := opts.Selector(context.Background(), "svc", nodes); node.Address != "c" { //nolint:errcheck
+		t.Fatalf("expected the later selector to win, got %s", node.Address)
+	}
+}
@@ -0,0 +1,283 @@
+package client
+
+import (
+	"context"
+	"math"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/go-orb/go-orb/registry"
+)
+
+//nolint:gochecknoglobals
+var (
+	// DefaultPeakEWMADecay is the time after which the latency of a node has mostly been forgotten.
+	DefaultPeakEWMADecay = 10 * time.Second
+	// DefaultPeakEWMAPenalty is the latency assumed for failed requests and busy nodes without measurements.
+	DefaultPeakEWMAPenalty = time.Second
+)
+
+// peakEWMAPruneDecays is the number of decays after which the stats of a node which
+// hasn't been a candidate get dropped, like the ones of nodes which left.
+const peakEWMAPruneDecays = 10
+
+// RoundRobinSelector selects the nodes of each service in turn.
+//
+// Use it with WithClientSelector(NewRoundRobinSelector().Select).
+type RoundRobinSelector struct {
+	mu       sync.Mutex
+	counters map[string]uint64
+}
+
+// NewRoundRobinSelector creates a new round-robin selector.
+func NewRoundRobinSelector() *RoundRobinSelector {
+	return &RoundRobinSelector{
+		counters: make(map[string]uint64),
+	}
+}
+
+// Select is the SelectorFunc of the round-robin selector.
+func (s *RoundRobinSelector) Select(
+	_ context.Context,
+	service string,
+	nodes []registry.ServiceNode,
+) (registry.ServiceNode, error) {
+	if len(nodes) == 0 {
+		return registry.ServiceNode{}, ErrNoNodeFound
+	}
+
+	s.mu.Lock()
+	counter := s.counters[service]
+	s.counters[service] = counter + 1
+	s.mu.Unlock()
+
+	return nodes[counter%uint64(len(nodes))], nil
+}
+
+// LeastOutstandingSelector selects the node with the fewest requests in flight.
+//
+// It needs the "selector" middleware to learn when requests have been completed,
+// requests without it don't get counted.
+type LeastOutstandingSelector struct {
+	mu          sync.Mutex
+	outstanding map[string]int
+}
+
+var _ SelectorReporter = (*LeastOutstandingSelector)(nil)
+
+// NewLeastOutstandingSelector creates a new least-outstanding-requests selector.
+func NewLeastOutstandingSelector() *LeastOutstandingSelector {
+	return &LeastOutstandingSelector{
+		outstanding: make(map[string]int),
+	}
+}
+
+// Select is the SelectorFunc of the least-outstanding-requests selector.
+func (s *LeastOutstandingSelector) Select(
+	ctx context.Context,
+	service string,
+	nodes []registry.ServiceNode,
+) (registry.ServiceNode, error) {
+	if len(nodes) == 0 {
+		return registry.ServiceNode{}, ErrNoNodeFound
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	best := []int{}
+	bestCount := math.MaxInt
+
+	for i, node := range nodes {
+		count := s.outstanding[node.Address]
+
+		switch {
+		case count < bestCount:
+			best = append(best[:0], i)
+			bestCount = count
+		case count == bestCount:
+			best = append(best, i)
+		}
+	}
+
+	node := nodes[best[rand.IntN(len(best))]] //nolint:gosec
+
+	if SetSelectorReporter(ctx, s, service, node.Address) {
+		s.outstanding[node.Address]++
+	}
+
+	return node, nil
+}
+
+// Report marks the request to address as completed.
+func (s *LeastOutstandingSelector) Report(_ string, address string, _ time.Duration, _ error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.outstanding[address] <= 1 {
+		delete(s.outstanding, address)
+		return
+	}
+
+	s.outstanding[address]--
+}
+
+// peakEWMAStats are the statistics of a single node.
+type peakEWMAStats struct {
+	// ewma is the weighted moving average of the latency in nanoseconds.
+	ewma        float64
+	updated     time.Time
+	outstanding int
+	// used is the last time the node was a candidate or reported.
+	used time.Time
+}
+
+// cost returns the expected latency of a new request, higher is worse.
+func (st *peakEWMAStats) cost(penalty time.Duration) float64 {
+	if st.ewma == 0 {
+		if st.outstanding == 0 {
+			return 0
+		}
+
+		return float64(penalty) * float64(st.outstanding)
+	}
+
+	return st.ewma * float64(st.outstanding+1)
+}
+
+// PeakEWMASelector selects nodes by their latency, it prefers nodes which answer fast
+// and have few requests in flight. Latency spikes are taken over immediately, while
+// improvements decay in slowly.
+//
+// Two random nodes are compared on each selection ("power of two choices"), so a
+// single fast node doesn't get all the load.
+//
+// It needs the "selector" middleware to learn the latency of requests, requests
+// without it don't get counted.
+type PeakEWMASelector struct {
+	decay   time.Duration
+	penalty time.Duration
+
+	mu     sync.Mutex
+	stats  map[string]*peakEWMAStats
+	pruned time.Time
+}
+
+var _ SelectorReporter = (*PeakEWMASelector)(nil)
+
+// NewPeakEWMASelector creates a new peak-EWMA selector, decay is the time after which a
+// measurement has mostly been forgotten, penalty is the latency assumed for failed requests.
+func NewPeakEWMASelector(decay time.Duration, penalty time.Duration) *PeakEWMASelector {
+	if decay <= 0 {
+		decay = DefaultPeakEWMADecay
+	}
+
+	if penalty <= 0 {
+		penalty = DefaultPeakEWMAPenalty
+	}
+
+	return &PeakEWMASelector{
+		decay:   decay,
+		penalty: penalty,
+		stats:   make(map[string]*peakEWMAStats),
+	}
+}
+
+// Select is the SelectorFunc of the peak-EWMA selector.
+func (s *PeakEWMASelector) Select(
+	ctx context.Context,
+	service string,
+	nodes []registry.ServiceNode,
+) (registry.ServiceNode, error) {
+	if len(nodes) == 0 {
+		return registry.ServiceNode{}, ErrNoNodeFound
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.prune(time.Now())
+
+	node := nodes[0]
+
+	if len(nodes) > 1 {
+		a := rand.IntN(len(nodes))     //nolint:gosec
+		b := rand.IntN(len(nodes) - 1) //nolint:gosec
+
+		if b >= a {
+			b++
+		}
+
+		node = nodes[a]
+		if s.get(nodes[b].Address).cost(s.penalty) < s.get(node.Address).cost(s.penalty) {
+			node = nodes[b]
+		}
+	}
+
+	if SetSelectorReporter(ctx, s, service, node.Address) {
+		s.get(node.Address).outstanding++
+	}
+
+	return node, nil
+}
+
+// Report updates the latency of address.
+func (s *PeakEWMASelector) Report(_ string, address string, duration time.Duration, err error) {
+	if err != nil && duration < s.penalty {
+		duration = s.penalty
+	}
+
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	st := s.get(address)
+	if st.outstanding > 0 {
+		st.outstanding--
+	}
+
+	rtt := float64(duration)
+
+	if rtt > st.ewma {
+		// Take over peaks immediately.
+		st.ewma = rtt
+	} else {
+		w := math.Exp(-float64(now.Sub(st.updated)) / float64(s.decay))
+		st.ewma = st.ewma*w + rtt*(1-w)
+	}
+
+	st.updated = now
+}
+
+// get returns the stats of address, the caller must hold s.mu.
+func (s *PeakEWMASelector) get(address string) *peakEWMAStats {
+	now := time.Now()
+
+	st, ok := s.stats[address]
+	if !ok {
+		st = &peakEWMAStats{updated: now}
+		s.stats[address] = st
+	}
+
+	st.used = now
+
+	return st
+}
+
+// prune drops the stats of nodes which haven't been used for peakEWMAPruneDecays decays,
+// it runs at most once per decay. The caller must hold s.mu.
+func (s *PeakEWMASelector) prune(now time.Time) {
+	if now.Sub(s.pruned) < s.decay {
+		return
+	}
+
+	s.pruned = now
+
+	for address, st := range s.stats {
+		if now.Sub(st.used) > peakEWMAPruneDecays*s.decay {
+			delete(s.stats, address)
+		}
+	}
+}
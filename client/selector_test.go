@@ -0,0 +1,185 @@
+package client
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/go-orb/go-orb/registry"
+)
+
+func testNodes(addresses ...string) []registry.ServiceNode {
+	nodes := make([]registry.ServiceNode, len(addresses))
+	for i, a := range addresses {
+		nodes[i] = registry.ServiceNode{Address: a}
+	}
+
+	return nodes
+}
+
+// distribution selects n times and returns the share of each address.
+func distribution(t *testing.T, selector SelectorFunc, nodes []registry.ServiceNode, n int) map[string]float64 {
+	t.Helper()
+
+	counts := make(map[string]float64)
+
+	for range n {
+		node, err := selector(context.Background(), "svc", nodes)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		counts[node.Address]++
+	}
+
+	for k, v := range counts {
+		counts[k] = v / float64(n)
+	}
+
+	return counts
+}
+
+func TestRoundRobinSelector(t *testing.T) {
+	s := NewRoundRobinSelector()
+	nodes := testNodes("a", "b", "c")
+
+	for i := range 6 {
+		node, err := s.Select(context.Background(), "svc", nodes)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if node.Address != nodes[i%3].Address {
+			t.Fatalf("selection %d: expected %s, got %s", i, nodes[i%3].Address, node.Address)
+		}
+	}
+
+	if _, err := s.Select(context.Background(), "svc", nil); err == nil {
+		t.Fatal("expected ErrNoNodeFound")
+	}
+}
+
+func TestWeightedRandomSelector(t *testing.T) {
+	nodes := testNodes("light", "heavy", "off")
+	nodes[1].Metadata = map[string]string{DefaultWeightMetadataKey: "3"}
+	nodes[2].Metadata = map[string]string{DefaultWeightMetadataKey: "0"}
+
+	shares := distribution(t, SelectWeightedRandomNode, nodes, 4000)
+
+	if math.Abs(shares["heavy"]-0.75) > 0.05 || shares["off"] != 0 {
+		t.Fatalf("expected shares of 0.25, 0.75 and 0, got %v", shares)
+	}
+
+	// Weights which aren't finite and positive count as DefaultNodeWeight.
+	for _, weight := range []string{"NaN", "Inf", "+Inf", "-Inf", "-1", "heavy"} {
+		nodes := testNodes("a", "b")
+		nodes[0].Metadata = map[string]string{DefaultWeightMetadataKey: weight}
+
+		if w := nodeWeight(nodes[0]); w != DefaultNodeWeight {
+			t.Fatalf("expected weight %q to be the default, got %v", weight, w)
+		}
+
+		if shares := distribution(t, SelectWeightedRandomNode, nodes, 2000); math.Abs(shares["a"]-0.5) > 0.05 {
+			t.Fatalf("expected weight %q to get half of the requests, got %v", weight, shares)
+		}
+	}
+}
+
+func TestLeastOutstandingSelector(t *testing.T) {
+	s := NewLeastOutstandingSelector()
+	nodes := testNodes("a", "b")
+
+	ctx, reports := WithSelectorReports(context.Background())
+
+	first, err := s.Select(ctx, "svc", nodes)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A node with a request in flight doesn't get selected again.
+	for range 10 {
+		otherCtx, otherReports := WithSelectorReports(context.Background())
+
+		node, err := s.Select(otherCtx, "svc", nodes)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if node.Address == first.Address {
+			t.Fatalf("expected the idle node, got %s", node.Address)
+		}
+
+		otherReports.Report(time.Millisecond, nil)
+	}
+
+	// A second selection for the same request, e.g. by a retry of the transport.
+	if _, err := s.Select(ctx, "svc", nodes); err != nil {
+		t.Fatal(err)
+	}
+
+	// Both selections of ctx are released by a single report.
+	reports.Report(time.Millisecond, nil)
+
+	if len(s.outstanding) != 0 {
+		t.Fatalf("expected all requests to be released, got %v", s.outstanding)
+	}
+
+	// Without reports selections don't get counted.
+	if distribution(t, s.Select, nodes, 100); len(s.outstanding) != 0 {
+		t.Fatalf("expected unreported selections not to be counted, got %v", s.outstanding)
+	}
+}
+
+func TestPeakEWMASelector(t *testing.T) {
+	s := NewPeakEWMASelector(time.Minute, time.Second)
+	nodes := testNodes("slow", "fast")
+
+	for _, tc := range []struct {
+		address  string
+		duration time.Duration
+	}{{"slow", 100 * time.Millisecond}, {"fast", time.Millisecond}} {
+		s.Report("svc", tc.address, tc.duration, nil)
+	}
+
+	for range 100 {
+		ctx, reports := WithSelectorReports(context.Background())
+
+		node, err := s.Select(ctx, "svc", nodes)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if node.Address != "fast" {
+			t.Fatalf("expected the fast node, got %s", node.Address)
+		}
+
+		reports.Report(time.Millisecond, nil)
+	}
+
+	distribution(t, s.Select, nodes, 100)
+
+	for address, st := range s.stats {
+		if st.outstanding != 0 {
+			t.Fatalf("expected all requests to %s to be released, got %d", address, st.outstanding)
+		}
+	}
+}
+
+func TestPeakEWMAPrune(t *testing.T) {
+	s := NewPeakEWMASelector(time.Millisecond, time.Second)
+
+	distribution(t, s.Select, testNodes("a", "b"), 10)
+
+	// a and b left, their stats get dropped after 10 decays.
+	for start := time.Now(); time.Since(start) < 20*time.Millisecond; time.Sleep(time.Millisecond) {
+		distribution(t, s.Select, testNodes("c", "d"), 1)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.stats["a"]; ok || len(s.stats) != 2 {
+		t.Fatalf("expected only the stats of c and d, got %v", s.stats)
+	}
+}
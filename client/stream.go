@@ -4,6 +4,8 @@ package client
 import (
 	"context"
 	"errors"
+	"io"
+	"sync"
 )
 
 // StreamIface is the interface for handling streaming operations.
@@ -104,3 +106,91 @@ func (s *StreamAdapter[TReq, TResp]) Context() context.Context {
 
 // ErrStreamNotSupported is returned when the client does not support streaming.
 var ErrStreamNotSupported = errors.New("client does not support streaming")
+
+// StreamInterceptor intercepts the calls to a stream, see InterceptStream.
+// Each func gets the call to the wrapped stream as next, nil funcs call it directly.
+type StreamInterceptor struct {
+	Send      func(msg any, next func(msg any) error) error
+	Recv      func(msg any, next func(msg any) error) error
+	CloseSend func(next func() error) error
+	Close     func(next func() error) error
+
+	// Done gets called once when the stream has ended: on Close, when Recv returns
+	// an error or Send an error other than io.EOF. err is nil if Recv returned io.EOF.
+	Done func(err error)
+}
+
+// InterceptStream wraps stream with the funcs of i, stream middlewares use it to
+// see the messages of a stream. The result can be used with NewStreamAdapter.
+func InterceptStream(stream StreamIface[any, any], i StreamInterceptor) StreamIface[any, any] {
+	return &interceptedStream{StreamIface: stream, i: i}
+}
+
+type interceptedStream struct {
+	StreamIface[any, any]
+
+	i    StreamInterceptor
+	once sync.Once
+}
+
+func (s *interceptedStream) Send(msg any) error {
+	var err error
+	if s.i.Send == nil {
+		err = s.StreamIface.Send(msg)
+	} else {
+		err = s.i.Send(msg, s.StreamIface.Send)
+	}
+
+	if err != nil && !errors.Is(err, io.EOF) {
+		s.done(err)
+	}
+
+	return err
+}
+
+func (s *interceptedStream) Recv(msg any) error {
+	var err error
+	if s.i.Recv == nil {
+		err = s.StreamIface.Recv(msg)
+	} else {
+		err = s.i.Recv(msg, s.StreamIface.Recv)
+	}
+
+	switch {
+	case errors.Is(err, io.EOF):
+		s.done(nil)
+	case err != nil:
+		s.done(err)
+	}
+
+	return err
+}
+
+func (s *interceptedStream) CloseSend() error {
+	if s.i.CloseSend == nil {
+		return s.StreamIface.CloseSend()
+	}
+
+	return s.i.CloseSend(s.StreamIface.CloseSend)
+}
+
+func (s *interceptedStream) Close() error {
+	var err error
+	if s.i.Close == nil {
+		err = s.StreamIface.Close()
+	} else {
+		err = s.i.Close(s.StreamIface.Close)
+	}
+
+	s.done(err)
+
+	return err
+}
+
+func (s *interceptedStream) done(err error) {
+	if s.i.Done == nil {
+		return
+	}
+
+	s.once.Do(func() { s.i.Done(err) })
+}
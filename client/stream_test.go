@@ -0,0 +1,149 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"slices"
+	"testing"
+)
+
+var errStream = errors.New("stream failed")
+
+// fakeStream returns the configured errors from Send, Recv and Close.
+type fakeStream struct {
+	StreamIface[any, any]
+
+	sendErr  error
+	recvErr  error
+	closeErr error
+}
+
+func (s *fakeStream) Send(any) error { return s.sendErr }
+func (s *fakeStream) Recv(any) error { return s.recvErr }
+func (s *fakeStream) Close() error   { return s.closeErr }
+
+func TestInterceptStreamDone(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		stream   *fakeStream
+		use      func(s StreamIface[any, any])
+		calls    int
+		expected error
+	}{
+		{"recv eof", &fakeStream{recvErr: io.EOF}, func(s StreamIface[any, any]) {
+			_ = s.Recv(nil) //nolint:errcheck
+			_ = s.Recv(nil) //nolint:errcheck
+			_ = s.Close()   //nolint:errcheck
+		}, 1, nil},
+		{"recv error", &fakeStream{recvErr: errStream}, func(s StreamIface[any, any]) {
+			_ = s.Recv(nil) //nolint:errcheck
+			_ = s.Close()   //nolint:errcheck
+		}, 1, errStream},
+		{"send eof", &fakeStream{sendErr: io.EOF}, func(s StreamIface[any, any]) {
+			_ = s.Send(nil) //nolint:errcheck
+		}, 0, nil},
+		{"send error", &fakeStream{sendErr: errStream}, func(s StreamIface[any, any]) {
+			_ = s.Send(nil) //nolint:errcheck
+			_ = s.Close()   //nolint:errcheck
+		}, 1, errStream},
+		{"close", &fakeStream{closeErr: errStream}, func(s StreamIface[any, any]) {
+			_ = s.Close() //nolint:errcheck
+			_ = s.Close() //nolint:errcheck
+		}, 1, errStream},
+		{"messages", &fakeStream{}, func(s StreamIface[any, any]) {
+			_ = s.Send(nil) //nolint:errcheck
+			_ = s.Recv(nil) //nolint:errcheck
+		}, 0, nil},
+	} {
+		calls := 0
+
+		var got error
+
+		stream := InterceptStream(tc.stream, StreamInterceptor{Done: func(err error) {
+			calls++
+			got = err
+		}})
+
+		tc.use(stream)
+
+		if calls != tc.calls {
+			t.Fatalf("%s: expected Done to be called %d times, got %d", tc.name, tc.calls, calls)
+		}
+
+		if !errors.Is(got, tc.expected) {
+			t.Fatalf("%s: expected Done with %v, got %v", tc.name, tc.expected, got)
+		}
+	}
+}
+
+func TestInterceptStream(t *testing.T) {
+	calls := []string{}
+
+	record := func(name string) func(msg any, next func(msg any) error) error {
+		return func(msg any, next func(msg any) error) error {
+			calls = append(calls, name)
+			return next(msg)
+		}
+	}
+
+	stream := InterceptStream(&fakeStream{recvErr: errStream}, StreamInterceptor{
+		Send: record("send"),
+		Recv: record("recv"),
+		Close: func(next func() error) error {
+			calls = append(calls, "close")
+			return next()
+		},
+	})
+
+	_ = stream.Send(nil) //nolint:errcheck
+
+	// The error of the wrapped stream is returned by the interceptor.
+	if err := stream.Recv(nil); !errors.Is(err, errStream) {
+		t.Fatalf("expected %v, got %v", errStream, err)
+	}
+
+	_ = stream.Close() //nolint:errcheck
+
+	if expected := []string{"send", "recv", "close"}; !slices.Equal(calls, expected) {
+		t.Fatalf("expected %v, got %v", expected, calls)
+	}
+}
+
+// streamTag records its name when a stream is opened.
+type streamTag struct {
+	Middleware
+
+	name  string
+	order *[]string
+}
+
+func (t *streamTag) Stream(next MiddlewareStreamHandler) MiddlewareStreamHandler {
+	return func(ctx context.Context, service string, endpoint string, opts *CallOptions) (StreamIface[any, any], error) {
+		*t.order = append(*t.order, t.name)
+		return next(ctx, service, endpoint, opts)
+	}
+}
+
+func TestChainStream(t *testing.T) {
+	order := []string{}
+
+	open := ChainStream(
+		func(context.Context, string, string, *CallOptions) (StreamIface[any, any], error) {
+			order = append(order, "handler")
+			return &fakeStream{}, nil
+		},
+		&streamTag{name: "first", order: &order},
+		// Middlewares without Stream are skipped.
+		&tag{name: "request-only"},
+		&streamTag{name: "second", order: &order},
+	)
+
+	if _, err := open(context.Background(), "svc", "Foo.Bar", &CallOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := []string{"first", "second", "handler"}; !slices.Equal(order, expected) {
+		t.Fatalf("expected %v, got %v", expected, order)
+	}
+}
@@ -3,8 +3,10 @@ package config
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"net/url"
+	"slices"
 	"strconv"
 
 	"dario.cat/mergo"
@@ -95,6 +97,10 @@ func Read(url *url.URL) (map[string]any, error) {
 
 // Parse parses the config from config.Read into the given struct.
 // Param target should be a pointer to the config to parse into.
+//
+// A numeric key is the index in the list at the end of sections, e.g. sections
+// []string{"server", "middlewares"} with key "0" parses the first middleware.
+// It's a map key if the last section is a map.
 func Parse[TMap any](sections []string, key string, config map[string]any, target TMap) error {
 	if config == nil {
 		return nil
@@ -105,6 +111,17 @@ func Parse[TMap any](sections []string, key string, config map[string]any, targe
 		err  error
 	)
 
+	if len(sections) > 0 && key != "" && isNumeric(key) {
+		data, err = WalkMap(append(slices.Clone(sections), key), config)
+		if !errors.Is(err, ErrTypesDontMatch) {
+			if err != nil {
+				return err
+			}
+
+			return decode(data, target)
+		}
+	}
+
 	if len(sections) > 0 {
 		data, err = WalkMap(sections, config)
 		if err != nil {
@@ -121,6 +138,11 @@ func Parse[TMap any](sections []string, key string, config map[string]any, targe
 		}
 	}
 
+	return decode(data, target)
+}
+
+// decode decodes data into target through JSON.
+func decode(data any, target any) error {
 	codec, err := codecs.GetMime(codecs.MimeJSON)
 	if err != nil {
 		return err
@@ -131,11 +153,7 @@ func Parse[TMap any](sections []string, key string, config map[string]any, targe
 		return err
 	}
 
-	if err := codec.Unmarshal(b, target); err != nil {
-		return err
-	}
-
-	return nil
+	return codec.Unmarshal(b, target)
 }
 
 // ParseSlice parses the config from config.Read into the given slice.
@@ -0,0 +1,74 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/go-orb/go-orb/codecs"
+)
+
+// jsonCodec is a minimal JSON codec, Parse needs one.
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v any) ([]byte, error)         { return json.Marshal(v) }
+func (jsonCodec) Unmarshal(data []byte, v any) error    { return json.Unmarshal(data, v) }
+func (jsonCodec) Marshals(any) bool                     { return true }
+func (jsonCodec) Unmarshals(any) bool                   { return true }
+func (jsonCodec) NewDecoder(r io.Reader) codecs.Decoder { return json.NewDecoder(r) }
+func (jsonCodec) NewEncoder(w io.Writer) codecs.Encoder { return json.NewEncoder(w) }
+func (jsonCodec) ContentTypes() []string                { return []string{codecs.MimeJSON} }
+func (jsonCodec) Name() string                          { return "json" }
+func (jsonCodec) Exts() []string                        { return []string{".json"} }
+
+func init() {
+	codecs.Register("json", jsonCodec{})
+}
+
+type pluginConfig struct {
+	Plugin string `json:"plugin"`
+}
+
+func TestParse(t *testing.T) {
+	data := map[string]any{
+		"server": map[string]any{
+			"middlewares": []any{
+				map[string]any{"plugin": "limiter"},
+				map[string]any{"plugin": "tracing"},
+			},
+			"ports": map[string]any{
+				"8080": map[string]any{"plugin": "http"},
+			},
+			"registry": map[string]any{"plugin": "memory"},
+		},
+	}
+
+	for _, tc := range []struct {
+		sections []string
+		key      string
+		expected string
+		err      error
+	}{
+		// A numeric key indexes the list at the end of sections.
+		{[]string{"server", "middlewares"}, "0", "limiter", nil},
+		{[]string{"server", "middlewares"}, "1", "tracing", nil},
+		{[]string{"server", "middlewares"}, "2", "", ErrNoSuchKey},
+		// A numeric key of a map is a map key.
+		{[]string{"server", "ports"}, "8080", "http", nil},
+		{[]string{"server", "ports"}, "9090", "", ErrNoSuchKey},
+		{[]string{"server"}, "registry", "memory", nil},
+		{nil, "server", "", nil},
+	} {
+		var cfg pluginConfig
+
+		err := Parse(tc.sections, tc.key, data, &cfg)
+		if !errors.Is(err, tc.err) {
+			t.Fatalf("%v %s: expected %v, got %v", tc.sections, tc.key, tc.err, err)
+		}
+
+		if cfg.Plugin != tc.expected {
+			t.Fatalf("%v %s: expected %q, got %q", tc.sections, tc.key, tc.expected, cfg.Plugin)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+// Package tracing wraps an event client to trace Publish, Request and HandleRequest,
+// Receive traces the handling of consumed events.
+//
+// The trace context travels in the W3C traceparent and tracestate keys, of the
+// event metadata for Publish/Consume and of the outgoing metadata for Request.
+package tracing
+
+import (
+	"context"
+	"maps"
+
+	"github.com/go-orb/go-orb/event"
+	"github.com/go-orb/go-orb/tracing"
+	"github.com/go-orb/go-orb/util/metadata"
+)
+
+var _ event.Client = (*Client)(nil)
+
+// Client is an event client which records spans with a tracer.
+type Client struct {
+	event.Client
+
+	tracer *tracing.Tracer
+}
+
+// Wrap returns c with tracing, use it in place of c.
+func Wrap(c event.Type, tracer *tracing.Tracer) event.Type {
+	return event.Type{Client: &Client{Client: c.Client, tracer: tracer}}
+}
+
+// Clone clones the wrapped client and wraps the clone.
+func (c *Client) Clone() event.Type {
+	return Wrap(c.Client.Clone(), c.tracer)
+}
+
+// Publish records a producer span and adds its trace context to the event metadata.
+func (c *Client) Publish(ctx context.Context, topic string, ev any, opts ...event.PublishOption) error {
+	ctx, span := c.tracer.StartSpan(ctx, topic+" publish",
+		tracing.WithKind(tracing.KindProducer),
+		tracing.WithAttribute(tracing.AttrTopic, topic),
+	)
+	defer span.End()
+
+	md := metadata.New(nil)
+	tracing.Inject(ctx, md)
+
+	// Runs after the callers options, the callers map is copied and not modified.
+	opts = append(opts, func(o *event.PublishOptions) {
+		o.Metadata = maps.Clone(o.Metadata)
+		if o.Metadata == nil {
+			o.Metadata = make(map[string]string)
+		}
+
+		maps.Copy(o.Metadata, md.Map())
+	})
+
+	err := c.Client.Publish(ctx, topic, ev, opts...)
+	span.SetError(err)
+
+	return err
+}
+
+// Request records a client span and sends its trace context in the outgoing metadata.
+func (c *Client) Request(ctx context.Context, req *event.Req[[]byte, any], opts ...event.RequestOption) ([]byte, error) {
+	ctx, span := c.tracer.StartSpan(ctx, req.Topic+" request",
+		tracing.WithKind(tracing.KindClient),
+		tracing.WithAttribute(tracing.AttrTopic, req.Topic),
+	)
+	defer span.End()
+
+	reply, err := c.Client.Request(tracing.InjectOutgoing(ctx), req, opts...)
+	span.SetError(err)
+
+	return reply, err
+}
+
+// HandleRequest records a server span around each call of cb, as child of the
+// trace context in the incoming metadata.
+func (c *Client) HandleRequest(ctx context.Context, topic string, cb func(context.Context, *event.Req[[]byte, []byte])) {
+	c.Client.HandleRequest(ctx, topic, func(ctx context.Context, req *event.Req[[]byte, []byte]) {
+		ctx, span := c.tracer.StartSpan(tracing.ExtractIncoming(ctx), topic+" handle",
+			tracing.WithKind(tracing.KindServer),
+			tracing.WithAttribute(tracing.AttrTopic, topic),
+		)
+		defer span.End()
+
+		cb(ctx, req)
+	})
+}
+
+// Receive runs handler for ev in a consumer span, as child of the trace context of ev.
+// Spans started from the ctx handler gets are children of the consumer span.
+//
+// c is the client which consumed ev, if it has no tracing handler runs with ContextFromEvent.
+func Receive(ctx context.Context, c event.Client, ev event.Event, handler func(ctx context.Context) error) error {
+	ctx = ContextFromEvent(ctx, ev)
+
+	tc, ok := c.(*Client)
+	if !ok {
+		return handler(ctx)
+	}
+
+	ctx, span := tc.tracer.StartSpan(ctx, ev.Topic+" receive",
+		tracing.WithKind(tracing.KindConsumer),
+		tracing.WithAttribute(tracing.AttrTopic, ev.Topic),
+	)
+	defer span.End()
+
+	err := handler(ctx)
+	span.SetError(err)
+
+	return err
+}
+
+// ContextFromEvent returns a copy of ctx with the trace context of ev as remote parent.
+func ContextFromEvent(ctx context.Context, ev event.Event) context.Context {
+	return tracing.Extract(ctx, metadata.New(ev.Metadata))
+}
@@ -0,0 +1,163 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/go-orb/go-orb/event"
+	"github.com/go-orb/go-orb/log"
+	"github.com/go-orb/go-orb/tracing"
+	"github.com/go-orb/go-orb/tracing/memory"
+	"github.com/go-orb/go-orb/util/metadata"
+)
+
+var errFailed = errors.New("failed")
+
+// loopback is an event client which delivers published events to its consumer
+// and requests to its request handler, with the metadata a broker would carry.
+type loopback struct {
+	event.Client
+
+	events  chan event.Event
+	handler func(context.Context, *event.Req[[]byte, []byte])
+}
+
+func (l *loopback) Publish(_ context.Context, topic string, _ any, opts ...event.PublishOption) error {
+	options := event.PublishOptions{}
+	for _, o := range opts {
+		o(&options)
+	}
+
+	l.events <- event.Event{Topic: topic, Metadata: options.Metadata}
+
+	return nil
+}
+
+func (l *loopback) Consume(string, ...event.ConsumeOption) (<-chan event.Event, error) {
+	return l.events, nil
+}
+
+func (l *loopback) Request(ctx context.Context, req *event.Req[[]byte, any], _ ...event.RequestOption) ([]byte, error) {
+	md, _ := metadata.OutgoingMD(ctx)
+	if md == nil {
+		md = metadata.New(nil)
+	}
+
+	l.handler(metadata.NewIncoming(context.Background(), md.Clone()), &event.Req[[]byte, []byte]{Topic: req.Topic})
+
+	return nil, nil
+}
+
+func (l *loopback) HandleRequest(_ context.Context, _ string, cb func(context.Context, *event.Req[[]byte, []byte])) {
+	l.handler = cb
+}
+
+func newTestClient() (event.Type, *memory.Exporter) {
+	exporter := memory.New()
+	tracer := tracing.New(tracing.NewConfig(), exporter, log.Logger{Logger: slog.New(slog.NewTextHandler(io.Discard, nil))})
+
+	return Wrap(event.Type{Client: &loopback{events: make(chan event.Event, 1)}}, tracer), exporter
+}
+
+func TestPublishConsume(t *testing.T) {
+	c, exporter := newTestClient()
+
+	events, err := c.Consume("topic")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	callerMD := map[string]string{"tenant": "a"}
+
+	if err := c.Publish(context.Background(), "topic", nil, event.WithPublishMetadata(callerMD)); err != nil {
+		t.Fatal(err)
+	}
+
+	ev := <-events
+
+	if _, ok := callerMD[tracing.TraceparentKey]; ok || len(callerMD) != 1 {
+		t.Fatalf("expected the callers metadata to be untouched, got %v", callerMD)
+	}
+
+	if ev.Metadata["tenant"] != "a" || ev.Metadata[tracing.TraceparentKey] == "" {
+		t.Fatalf("expected the callers metadata and the trace context on the event, got %v", ev.Metadata)
+	}
+
+	sc, ok := tracing.SpanContextFromContext(ContextFromEvent(context.Background(), ev))
+	if !ok {
+		t.Fatal("expected a span context from the event")
+	}
+
+	var handlerSpan tracing.SpanContext
+
+	err = Receive(context.Background(), c.Client, ev, func(ctx context.Context) error {
+		handlerSpan = tracing.SpanFromContext(ctx).SpanContext()
+		return errFailed
+	})
+	if !errors.Is(err, errFailed) {
+		t.Fatalf("expected the handlers error, got %v", err)
+	}
+
+	spans := exporter.Spans()
+	if len(spans) != 2 {
+		t.Fatalf("expected a producer and a consumer span, got %d", len(spans))
+	}
+
+	producer, consumer := spans[0], spans[1]
+
+	if producer.Kind != tracing.KindProducer || producer.SpanContext.SpanID != sc.SpanID {
+		t.Fatalf("expected the event to carry the producer span, got %+v", producer)
+	}
+
+	if consumer.Kind != tracing.KindConsumer || consumer.Parent != producer.SpanContext.SpanID ||
+		consumer.SpanContext.TraceID != producer.SpanContext.TraceID {
+		t.Fatalf("expected the consumer span below the producer span, got %+v", consumer)
+	}
+
+	if handlerSpan != consumer.SpanContext || !errors.Is(consumer.Err, errFailed) {
+		t.Fatalf("expected the handler to run in the consumer span and its error on it, got %+v", consumer)
+	}
+
+	// Without tracing the handler continues the trace of the event.
+	_ = Receive(context.Background(), &loopback{}, ev, func(ctx context.Context) error { //nolint:errcheck
+		handlerSpan, _ = tracing.SpanContextFromContext(ctx)
+		return nil
+	})
+
+	if handlerSpan.SpanID != sc.SpanID {
+		t.Fatalf("expected the producer span as parent, got %+v", handlerSpan)
+	}
+}
+
+func TestRequest(t *testing.T) {
+	c, exporter := newTestClient()
+
+	var handlerSpan tracing.SpanContext
+
+	c.HandleRequest(context.Background(), "topic", func(ctx context.Context, _ *event.Req[[]byte, []byte]) {
+		handlerSpan = tracing.SpanFromContext(ctx).SpanContext()
+	})
+
+	if _, err := c.Request(context.Background(), &event.Req[[]byte, any]{Topic: "topic"}); err != nil {
+		t.Fatal(err)
+	}
+
+	spans := exporter.Spans()
+	if len(spans) != 2 {
+		t.Fatalf("expected a client and a server span, got %d", len(spans))
+	}
+
+	// The server span ends first.
+	server, client := spans[0], spans[1]
+
+	if server.Kind != tracing.KindServer || client.Kind != tracing.KindClient {
+		t.Fatalf("expected a server and a client span, got %s and %s", server.Kind, client.Kind)
+	}
+
+	if server.Parent != client.SpanContext.SpanID || handlerSpan != server.SpanContext {
+		t.Fatalf("expected the handler to run in a span below the request, got %+v", server)
+	}
+}
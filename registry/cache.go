@@ -0,0 +1,366 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-orb/go-orb/log"
+)
+
+//nolint:gochecknoglobals
+var (
+	// DefaultCacheRewatchInterval is the time the cache waits before it recreates a failed watcher.
+	DefaultCacheRewatchInterval = 5 * time.Second
+)
+
+var _ Registry = (*Cache)(nil)
+
+// cacheKey identifies a GetService or ListServices query, name is empty for ListServices.
+type cacheKey struct {
+	namespace string
+	region    string
+	name      string
+	schemes   string
+}
+
+type cacheEntry struct {
+	schemes []string
+	nodes   []ServiceNode
+	expires time.Time
+}
+
+// matches checks if the node would have been returned by the query of this entry.
+func (e *cacheEntry) matches(key cacheKey, node ServiceNode) bool {
+	if key.namespace != node.Namespace || key.region != node.Region {
+		return false
+	}
+
+	if key.name != "" && key.name != node.Name {
+		return false
+	}
+
+	return len(e.schemes) == 0 || slices.Contains(e.schemes, node.Scheme)
+}
+
+// Cache is a Registry which caches the results of GetService and ListServices
+// of another Registry.
+//
+// The cache is kept fresh by the Watcher of the wrapped Registry, entries expire
+// after the TTL or when the Watcher fails. When the wrapped Registry fails, stale
+// entries are returned.
+type Cache struct {
+	backend Registry
+	ttl     time.Duration
+	logger  log.Logger
+
+	mu       sync.RWMutex
+	services map[cacheKey]*cacheEntry
+	lists    map[cacheKey]*cacheEntry
+	// generations are incremented by every change of a service, the key of a service
+	// has its name, the one of its namespace and region has none. Queries don't store
+	// their results if the generation of their key or epoch changed while they were
+	// fetching them.
+	generations map[cacheKey]uint64
+	// epoch is incremented when all entries get flushed or expired.
+	epoch uint64
+
+	cancel context.CancelFunc
+}
+
+// NewCache wraps backend with a cache, entries live for ttl.
+func NewCache(backend Registry, ttl time.Duration, logger log.Logger) *Cache {
+	return &Cache{
+		backend:     backend,
+		ttl:         ttl,
+		logger:      logger,
+		services:    make(map[cacheKey]*cacheEntry),
+		lists:       make(map[cacheKey]*cacheEntry),
+		generations: make(map[cacheKey]uint64),
+	}
+}
+
+// Start starts the wrapped registry and the cache watcher.
+func (c *Cache) Start(ctx context.Context) error {
+	if err := c.backend.Start(ctx); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.cancel != nil {
+		return nil
+	}
+
+	wCtx, cancel := context.WithCancel(context.Background())
+	c.cancel = cancel
+
+	go c.watch(wCtx)
+
+	return nil
+}
+
+// Stop stops the cache watcher and the wrapped registry.
+func (c *Cache) Stop(ctx context.Context) error {
+	c.mu.Lock()
+	if c.cancel != nil {
+		c.cancel()
+		c.cancel = nil
+	}
+	c.mu.Unlock()
+
+	c.flush()
+
+	return c.backend.Stop(ctx)
+}
+
+// String returns the plugin name of the wrapped registry.
+func (c *Cache) String() string {
+	return c.backend.String()
+}
+
+// Type returns the component type.
+func (c *Cache) Type() string {
+	return ComponentType
+}
+
+// Register registers the node in the wrapped registry.
+func (c *Cache) Register(ctx context.Context, srv ServiceNode) error {
+	defer c.invalidate(srv)
+
+	return c.backend.Register(ctx, srv)
+}
+
+// Deregister deregisters the node in the wrapped registry.
+func (c *Cache) Deregister(ctx context.Context, srv ServiceNode) error {
+	defer c.invalidate(srv)
+
+	return c.backend.Deregister(ctx, srv)
+}
+
+// GetService returns the nodes of a service from the cache or the wrapped registry.
+func (c *Cache) GetService(ctx context.Context, namespace, region, name string, schemes []string) ([]ServiceNode, error) {
+	key := newCacheKey(namespace, region, name, schemes)
+
+	return c.query(false, key, schemes, func() ([]ServiceNode, error) {
+		return c.backend.GetService(ctx, namespace, region, name, schemes)
+	})
+}
+
+// ListServices returns the nodes in namespace and region from the cache or the wrapped registry.
+func (c *Cache) ListServices(ctx context.Context, namespace, region string, schemes []string) ([]ServiceNode, error) {
+	key := newCacheKey(namespace, region, "", schemes)
+
+	return c.query(true, key, schemes, func() ([]ServiceNode, error) {
+		return c.backend.ListServices(ctx, namespace, region, schemes)
+	})
+}
+
+// Watch returns a Watcher of the wrapped registry.
+func (c *Cache) Watch(ctx context.Context, opts ...WatchOption) (Watcher, error) {
+	return c.backend.Watch(ctx, opts...)
+}
+
+// entries returns the entries of ListServices when list is true, else the ones of GetService.
+// The caller must hold c.mu.
+func (c *Cache) entries(list bool) map[cacheKey]*cacheEntry {
+	if list {
+		return c.lists
+	}
+
+	return c.services
+}
+
+func (c *Cache) query(
+	list bool,
+	key cacheKey,
+	schemes []string,
+	fetch func() ([]ServiceNode, error),
+) ([]ServiceNode, error) {
+	genKey := cacheKey{namespace: key.namespace, region: key.region, name: key.name}
+
+	c.mu.RLock()
+	entry, ok := c.entries(list)[key]
+	generation, epoch := c.generations[genKey], c.epoch
+
+	if ok && time.Now().Before(entry.expires) {
+		nodes := slices.Clone(entry.nodes)
+		c.mu.RUnlock()
+
+		return nodes, nil
+	}
+	c.mu.RUnlock()
+
+	nodes, err := fetch()
+
+	switch {
+	case errors.Is(err, ErrNotFound):
+		c.mu.Lock()
+		delete(c.entries(list), key)
+		c.mu.Unlock()
+
+		return nil, err
+	case err != nil && ok:
+		c.logger.Warn("while querying the registry, serving a stale cache entry", "error", err)
+
+		c.mu.RLock()
+		nodes = slices.Clone(entry.nodes)
+		c.mu.RUnlock()
+
+		return nodes, nil
+	case err != nil:
+		return nil, err
+	}
+
+	c.mu.Lock()
+	// The watcher may have applied newer results while we fetched these, the next query fetches again.
+	if c.generations[genKey] == generation && c.epoch == epoch {
+		c.entries(list)[key] = &cacheEntry{
+			schemes: slices.Clone(schemes),
+			nodes:   slices.Clone(nodes),
+			expires: time.Now().Add(c.ttl),
+		}
+	}
+	c.mu.Unlock()
+
+	return nodes, nil
+}
+
+// watch applies the results of the wrapped registries watcher until ctx is done.
+func (c *Cache) watch(ctx context.Context) {
+	for {
+		watcher, err := c.backend.Watch(ctx)
+		if err == nil {
+			err = c.consume(ctx, watcher)
+		}
+
+		if ctx.Err() != nil {
+			return
+		}
+
+		// Without a working watcher the entries may miss changes, they get fetched again
+		// but are kept to be served while the registry is down.
+		c.logger.Warn("the registry watcher stopped, expiring the cache", "error", err)
+		c.expire()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(DefaultCacheRewatchInterval):
+		}
+	}
+}
+
+// consume applies the results of watcher until it fails or ctx is done, it returns the error of the watcher.
+func (c *Cache) consume(ctx context.Context, watcher Watcher) error {
+	defer watcher.Stop() //nolint:errcheck
+
+	// Unblock Next when ctx is done.
+	stop := context.AfterFunc(ctx, func() { _ = watcher.Stop() }) //nolint:errcheck
+	defer stop()
+
+	for {
+		result, err := watcher.Next()
+		if err != nil {
+			return err
+		}
+
+		c.apply(result)
+	}
+}
+
+// apply updates all cache entries the result belongs to.
+func (c *Cache) apply(result *Result) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.changed(result.Node)
+
+	for _, entries := range []map[cacheKey]*cacheEntry{c.services, c.lists} {
+		for key, entry := range entries {
+			if !entry.matches(key, result.Node) {
+				continue
+			}
+
+			idx := slices.IndexFunc(entry.nodes, func(n ServiceNode) bool { return n.ID() == result.Node.ID() })
+
+			switch {
+			case result.Action == Delete && idx >= 0:
+				entry.nodes = slices.Delete(entry.nodes, idx, idx+1)
+			case result.Action != Delete && idx >= 0:
+				entry.nodes[idx] = result.Node
+			case result.Action != Delete:
+				entry.nodes = append(entry.nodes, result.Node)
+			}
+
+			// Let GetService ask the registry again, so it can return ErrNotFound.
+			if len(entry.nodes) == 0 && key.name != "" {
+				delete(entries, key)
+			}
+		}
+	}
+}
+
+// invalidate removes all entries the node belongs to.
+func (c *Cache) invalidate(node ServiceNode) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.changed(node)
+
+	for _, entries := range []map[cacheKey]*cacheEntry{c.services, c.lists} {
+		for key, entry := range entries {
+			if entry.matches(key, node) {
+				delete(entries, key)
+			}
+		}
+	}
+}
+
+// changed increments the generations of the service of node and of its namespace and region.
+// The caller must hold c.mu.
+func (c *Cache) changed(node ServiceNode) {
+	c.generations[cacheKey{namespace: node.Namespace, region: node.Region, name: node.Name}]++
+	c.generations[cacheKey{namespace: node.Namespace, region: node.Region}]++
+}
+
+// expire lets all entries expire, they get served until a query fetched them again.
+func (c *Cache) expire() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.epoch++
+
+	for _, entries := range []map[cacheKey]*cacheEntry{c.services, c.lists} {
+		for _, entry := range entries {
+			entry.expires = time.Time{}
+		}
+	}
+}
+
+func (c *Cache) flush() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.epoch++
+
+	c.services = make(map[cacheKey]*cacheEntry)
+	c.lists = make(map[cacheKey]*cacheEntry)
+	c.generations = make(map[cacheKey]uint64)
+}
+
+func newCacheKey(namespace, region, name string, schemes []string) cacheKey {
+	sorted := slices.Clone(schemes)
+	slices.Sort(sorted)
+
+	return cacheKey{
+		namespace: namespace,
+		region:    region,
+		name:      name,
+		schemes:   strings.Join(sorted, ","),
+	}
+}
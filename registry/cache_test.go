@@ -0,0 +1,256 @@
+package registry_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-orb/go-orb/log"
+	"github.com/go-orb/go-orb/registry"
+	"github.com/go-orb/go-orb/registry/memory"
+)
+
+var errDown = errors.New("down")
+
+// backend counts the GetService calls of the cache and fails them while down is set.
+type backend struct {
+	*memory.Registry
+
+	calls atomic.Int32
+	down  atomic.Bool
+	// fetched is called after the nodes have been fetched.
+	fetched func()
+	// watcher is the last watcher of the cache.
+	watcher atomic.Pointer[registry.Watcher]
+}
+
+func (b *backend) Watch(ctx context.Context, opts ...registry.WatchOption) (registry.Watcher, error) {
+	w, err := b.Registry.Watch(ctx, opts...)
+	if err == nil {
+		b.watcher.Store(&w)
+	}
+
+	return w, err
+}
+
+func (b *backend) GetService(ctx context.Context, namespace, region, name string, schemes []string) ([]registry.ServiceNode, error) {
+	b.calls.Add(1)
+
+	if b.down.Load() {
+		return nil, errDown
+	}
+
+	nodes, err := b.Registry.GetService(ctx, namespace, region, name, schemes)
+
+	if b.fetched != nil {
+		b.fetched()
+	}
+
+	return nodes, err
+}
+
+func testNode(address string) registry.ServiceNode {
+	return registry.ServiceNode{Name: "svc", Version: "v1", Node: "grpc", Scheme: "grpc", Address: address}
+}
+
+func newTestCache(t *testing.T) (*registry.Cache, *backend) {
+	t.Helper()
+
+	logger := log.Logger{Logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+	b := &backend{Registry: memory.New(memory.NewConfig(), logger)}
+	c := registry.NewCache(b, time.Minute, logger)
+
+	if err := c.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { _ = c.Stop(context.Background()) }) //nolint:errcheck
+
+	// Let the cache create its watcher.
+	time.Sleep(20 * time.Millisecond)
+
+	return c, b
+}
+
+// eventually retries fn until it returns true or a second has passed.
+func eventually(t *testing.T, fn func() bool) {
+	t.Helper()
+
+	for start := time.Now(); time.Since(start) < time.Second; time.Sleep(5 * time.Millisecond) {
+		if fn() {
+			return
+		}
+	}
+
+	t.Fatal("condition not met within a second")
+}
+
+func TestCache(t *testing.T) {
+	ctx := context.Background()
+	c, b := newTestCache(t)
+
+	if err := b.Registry.Register(ctx, testNode("127.0.0.1:1")); err != nil {
+		t.Fatal(err)
+	}
+
+	for range 3 {
+		if nodes, err := c.GetService(ctx, "", "", "svc", nil); err != nil || len(nodes) != 1 {
+			t.Fatalf("expected 1 node, got %d: %v", len(nodes), err)
+		}
+	}
+
+	if n := b.calls.Load(); n != 1 {
+		t.Fatalf("expected 1 call to the backend, got %d", n)
+	}
+
+	// Changes in the backend get applied by the watcher.
+	if err := b.Registry.Register(ctx, testNode("127.0.0.1:2")); err != nil {
+		t.Fatal(err)
+	}
+
+	eventually(t, func() bool {
+		nodes, err := c.GetService(ctx, "", "", "svc", nil)
+		return err == nil && len(nodes) == 2
+	})
+
+	if n := b.calls.Load(); n != 1 {
+		t.Fatalf("expected the watcher to update the entry, got %d calls to the backend", n)
+	}
+
+	// Register invalidates the entry, with the backend down the cache has nothing to serve.
+	b.down.Store(true)
+
+	if err := c.Register(ctx, testNode("127.0.0.1:3")); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := c.GetService(ctx, "", "", "svc", nil); !errors.Is(err, errDown) {
+		t.Fatalf("expected the backend error, got %v", err)
+	}
+}
+
+func TestCacheStaleFetch(t *testing.T) {
+	ctx := context.Background()
+	c, b := newTestCache(t)
+
+	if err := b.Registry.Register(ctx, testNode("127.0.0.1:1")); err != nil {
+		t.Fatal(err)
+	}
+
+	// A node gets registered and applied by the watcher after the first fetch, before its result gets stored.
+	once := atomic.Bool{}
+	b.fetched = func() {
+		if once.Swap(true) {
+			return
+		}
+
+		if err := b.Registry.Register(ctx, testNode("127.0.0.1:2")); err != nil {
+			t.Error(err)
+		}
+
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	if nodes, err := c.GetService(ctx, "", "", "svc", nil); err != nil || len(nodes) != 1 {
+		t.Fatalf("expected the fetched node, got %d: %v", len(nodes), err)
+	}
+
+	if nodes, err := c.GetService(ctx, "", "", "svc", nil); err != nil || len(nodes) != 2 {
+		t.Fatalf("expected the stale result not to be cached, got %d nodes: %v", len(nodes), err)
+	}
+}
+
+func TestCacheWatcherDown(t *testing.T) {
+	interval := registry.DefaultCacheRewatchInterval
+	registry.DefaultCacheRewatchInterval = 50 * time.Millisecond
+
+	t.Cleanup(func() { registry.DefaultCacheRewatchInterval = interval })
+
+	ctx := context.Background()
+	c, b := newTestCache(t)
+
+	if err := b.Registry.Register(ctx, testNode("127.0.0.1:1")); err != nil {
+		t.Fatal(err)
+	}
+
+	if nodes, err := c.GetService(ctx, "", "", "svc", nil); err != nil || len(nodes) != 1 {
+		t.Fatalf("expected 1 node, got %d: %v", len(nodes), err)
+	}
+
+	// A backend outage kills the watcher, the entry gets stale but is still served.
+	first := b.watcher.Load()
+	b.down.Store(true)
+
+	if err := (*first).Stop(); err != nil {
+		t.Fatal(err)
+	}
+
+	eventually(t, func() bool {
+		calls := b.calls.Load()
+
+		nodes, err := c.GetService(ctx, "", "", "svc", nil)
+		if err != nil || len(nodes) != 1 {
+			t.Fatalf("expected the stale node, got %d: %v", len(nodes), err)
+		}
+
+		// The stale entry gets fetched again.
+		return b.calls.Load() > calls
+	})
+
+	// The cache watches again.
+	eventually(t, func() bool { return b.watcher.Load() != first })
+
+	b.down.Store(false)
+
+	if err := b.Registry.Register(ctx, testNode("127.0.0.1:2")); err != nil {
+		t.Fatal(err)
+	}
+
+	eventually(t, func() bool {
+		nodes, err := c.GetService(ctx, "", "", "svc", nil)
+		return err == nil && len(nodes) == 2
+	})
+}
+
+func TestCacheOtherServiceChanged(t *testing.T) {
+	ctx := context.Background()
+	c, b := newTestCache(t)
+
+	if err := b.Registry.Register(ctx, testNode("127.0.0.1:1")); err != nil {
+		t.Fatal(err)
+	}
+
+	// Let the watcher apply the registration, it would drop the first fetch.
+	time.Sleep(20 * time.Millisecond)
+
+	// Another service changes while svc gets fetched.
+	once := atomic.Bool{}
+	b.fetched = func() {
+		if once.Swap(true) {
+			return
+		}
+
+		other := testNode("127.0.0.1:9")
+		other.Name = "other"
+
+		if err := b.Registry.Register(ctx, other); err != nil {
+			t.Error(err)
+		}
+
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	for range 2 {
+		if nodes, err := c.GetService(ctx, "", "", "svc", nil); err != nil || len(nodes) != 1 {
+			t.Fatalf("expected 1 node, got %d: %v", len(nodes), err)
+		}
+	}
+
+	if n := b.calls.Load(); n != 1 {
+		t.Fatalf("expected the result to be cached, got %d calls to the backend", n)
+	}
+}
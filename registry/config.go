@@ -16,6 +16,9 @@ var (
 
 	// DefaultTimeout is the default timeout for the registry.
 	DefaultTimeout = config.Duration(500 * time.Millisecond)
+
+	// DefaultCacheTTL is the default time to live of a cache entry.
+	DefaultCacheTTL = config.Duration(time.Minute)
 )
 
 var _ (ConfigType) = (*Config)(nil)
@@ -48,10 +51,19 @@ type ConfigType interface {
 
 // TODO(jochumdev): this config misses things compared to v4, should they be added here?
 
+// CacheConfig is the configuration of the registry cache.
+type CacheConfig struct {
+	// Enabled wraps the registry plugin with a cache.
+	Enabled bool `json:"enabled,omitempty" yaml:"enabled,omitempty"`
+	// TTL is the time after which a cached entry gets refreshed from the registry.
+	TTL config.Duration `json:"ttl,omitempty" yaml:"ttl,omitempty"`
+}
+
 // Config is the configuration that can be used in a registry.
 type Config struct {
 	Plugin  string          `json:"plugin,omitempty"  yaml:"plugin,omitempty"`
 	Timeout config.Duration `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+	Cache   CacheConfig     `json:"cache,omitempty"   yaml:"cache,omitempty"`
 }
 
 func (c *Config) config() *Config {
@@ -74,11 +86,23 @@ func WithTimeout(n time.Duration) Option {
 	}
 }
 
+// WithCache enables the registry cache with the given TTL.
+func WithCache(ttl time.Duration) Option {
+	return func(cfg ConfigType) {
+		c := cfg.config()
+		c.Cache.Enabled = true
+		c.Cache.TTL = config.Duration(ttl)
+	}
+}
+
 // NewConfig creates a config to use with a registry.
 func NewConfig(opts ...Option) Config {
 	cfg := Config{
 		Plugin:  DefaultRegistry,
 		Timeout: DefaultTimeout,
+		Cache: CacheConfig{
+			TTL: DefaultCacheTTL,
+		},
 	}
 
 	// Apply options.
@@ -0,0 +1,64 @@
+package memory
+
+import (
+	"time"
+
+	"github.com/go-orb/go-orb/config"
+	"github.com/go-orb/go-orb/registry"
+)
+
+//nolint:gochecknoglobals
+var (
+	// DefaultExpiryInterval is the interval in which expired nodes get removed.
+	DefaultExpiryInterval = config.Duration(time.Second)
+
+	// DefaultWatcherBuffer is the number of results a watcher buffers, one which falls further behind gets stopped.
+	DefaultWatcherBuffer = 64
+)
+
+// Config is the configuration of the memory registry.
+type Config struct {
+	registry.Config `yaml:",inline"`
+
+	// ExpiryInterval is the interval in which nodes with an expired TTL get removed.
+	ExpiryInterval config.Duration `json:"expiryInterval,omitempty" yaml:"expiryInterval,omitempty"`
+
+	// WatcherBuffer is the number of results a watcher buffers, one which falls further behind gets stopped.
+	WatcherBuffer int `json:"watcherBuffer,omitempty" yaml:"watcherBuffer,omitempty"`
+}
+
+// NewConfig creates a new config with the defaults and applies opts on top.
+func NewConfig(opts ...registry.Option) Config {
+	cfg := Config{
+		Config:         registry.NewConfig(registry.WithPlugin(Name)),
+		ExpiryInterval: DefaultExpiryInterval,
+		WatcherBuffer:  DefaultWatcherBuffer,
+	}
+
+	// Apply options.
+	for _, o := range opts {
+		o(&cfg)
+	}
+
+	return cfg
+}
+
+// WithExpiryInterval sets the interval in which expired nodes get removed.
+func WithExpiryInterval(n time.Duration) registry.Option {
+	return func(c registry.ConfigType) {
+		cfg, ok := c.(*Config)
+		if ok {
+			cfg.ExpiryInterval = config.Duration(n)
+		}
+	}
+}
+
+// WithWatcherBuffer sets the number of results a watcher buffers.
+func WithWatcherBuffer(n int) registry.Option {
+	return func(c registry.ConfigType) {
+		cfg, ok := c.(*Config)
+		if ok {
+			cfg.WatcherBuffer = n
+		}
+	}
+}
@@ -0,0 +1,292 @@
+// Package memory provides an in-process registry, it's useful for tests and
+// for services which run all their components inside a single process.
+package memory
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/go-orb/go-orb/config"
+	"github.com/go-orb/go-orb/log"
+	"github.com/go-orb/go-orb/registry"
+	"github.com/go-orb/go-orb/types"
+)
+
+// Name is the name of this registry implementation.
+const Name = "memory"
+
+var _ registry.Registry = (*Registry)(nil)
+
+func init() {
+	registry.Plugins.Add(Name, Provide)
+}
+
+// record is a registered node with its expiry time.
+type record struct {
+	node    registry.ServiceNode
+	expires time.Time
+}
+
+func (r *record) expired(now time.Time) bool {
+	return !r.expires.IsZero() && now.After(r.expires)
+}
+
+// Registry is an in-memory registry.
+type Registry struct {
+	config Config
+	logger log.Logger
+
+	mu    sync.RWMutex
+	nodes map[string]*record
+
+	watchersMu sync.RWMutex
+	watchers   map[*Watcher]struct{}
+
+	cancel context.CancelFunc
+}
+
+// Provide creates a new memory registry.
+func Provide(
+	configData map[string]any,
+	_ *types.Components,
+	logger log.Logger,
+	opts ...registry.Option,
+) (registry.Type, error) {
+	cfg := NewConfig(opts...)
+
+	if err := config.Parse(nil, registry.DefaultConfigSection, configData, &cfg); err != nil && !errors.Is(err, config.ErrNoSuchKey) {
+		return registry.Type{}, err
+	}
+
+	return registry.Type{Registry: New(cfg, logger)}, nil
+}
+
+// New creates a new memory registry without side-effects.
+func New(cfg Config, logger log.Logger) *Registry {
+	return &Registry{
+		config:   cfg,
+		logger:   logger,
+		nodes:    make(map[string]*record),
+		watchers: make(map[*Watcher]struct{}),
+	}
+}
+
+// Start starts the expiry loop.
+func (r *Registry) Start(_ context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.cancel != nil || r.config.ExpiryInterval <= 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+
+	go r.expiryLoop(ctx, time.Duration(r.config.ExpiryInterval))
+
+	return nil
+}
+
+// Stop stops the expiry loop and all watchers.
+func (r *Registry) Stop(_ context.Context) error {
+	r.mu.Lock()
+	if r.cancel != nil {
+		r.cancel()
+		r.cancel = nil
+	}
+	r.mu.Unlock()
+
+	r.watchersMu.Lock()
+	watchers := slices.Collect(maps.Keys(r.watchers))
+	r.watchersMu.Unlock()
+
+	for _, w := range watchers {
+		_ = w.Stop() //nolint:errcheck
+	}
+
+	return nil
+}
+
+// String returns the plugin name.
+func (r *Registry) String() string {
+	return Name
+}
+
+// Type returns the component type.
+func (r *Registry) Type() string {
+	return registry.ComponentType
+}
+
+// Register registers a node, registering an existing node updates it.
+func (r *Registry) Register(_ context.Context, node registry.ServiceNode) error {
+	if err := node.Valid(); err != nil {
+		return err
+	}
+
+	rec := &record{node: node}
+	if node.TTL > 0 {
+		rec.expires = time.Now().Add(node.TTL)
+	}
+
+	r.mu.Lock()
+	_, exists := r.nodes[node.ID()]
+	r.nodes[node.ID()] = rec
+	r.mu.Unlock()
+
+	action := registry.Create
+	if exists {
+		action = registry.Update
+	}
+
+	r.notify(registry.Result{Action: action, Node: node})
+
+	return nil
+}
+
+// Deregister removes a node, unknown nodes are ignored.
+func (r *Registry) Deregister(_ context.Context, node registry.ServiceNode) error {
+	r.mu.Lock()
+	rec, exists := r.nodes[node.ID()]
+	delete(r.nodes, node.ID())
+	r.mu.Unlock()
+
+	if exists {
+		r.notify(registry.Result{Action: registry.Delete, Node: rec.node})
+	}
+
+	return nil
+}
+
+// GetService returns all nodes of a service.
+// Leave schemes empty to get all schemes.
+func (r *Registry) GetService(
+	_ context.Context,
+	namespace, region, name string,
+	schemes []string,
+) ([]registry.ServiceNode, error) {
+	result := r.filter(func(node registry.ServiceNode) bool {
+		return node.Name == name && matches(node, namespace, region, schemes)
+	})
+
+	if len(result) == 0 {
+		return nil, registry.ErrNotFound
+	}
+
+	return result, nil
+}
+
+// ListServices returns all nodes in the given namespace and region.
+// Leave schemes empty to get all schemes.
+func (r *Registry) ListServices(
+	_ context.Context,
+	namespace, region string,
+	schemes []string,
+) ([]registry.ServiceNode, error) {
+	return r.filter(func(node registry.ServiceNode) bool {
+		return matches(node, namespace, region, schemes)
+	}), nil
+}
+
+// Watch returns a watcher that receives all changes after its creation.
+// The watcher stops when ctx is done.
+func (r *Registry) Watch(ctx context.Context, opts ...registry.WatchOption) (registry.Watcher, error) {
+	w := &Watcher{
+		ctx:     ctx,
+		options: registry.NewWatchOptions(opts...),
+		results: make(chan registry.Result, r.config.WatcherBuffer),
+		exit:    make(chan struct{}),
+		reg:     r,
+	}
+
+	r.watchersMu.Lock()
+	r.watchers[w] = struct{}{}
+	r.watchersMu.Unlock()
+
+	return w, nil
+}
+
+// filter returns a sorted list of all living nodes for which fn returns true.
+func (r *Registry) filter(fn func(node registry.ServiceNode) bool) []registry.ServiceNode {
+	now := time.Now()
+	result := []registry.ServiceNode{}
+
+	r.mu.RLock()
+	for _, rec := range r.nodes {
+		if rec.expired(now) || !fn(rec.node) {
+			continue
+		}
+
+		result = append(result, rec.node)
+	}
+	r.mu.RUnlock()
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].ID() < result[j].ID()
+	})
+
+	return result
+}
+
+func (r *Registry) expiryLoop(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			r.expire(now)
+		}
+	}
+}
+
+// expire removes all expired nodes and notifies the watchers.
+func (r *Registry) expire(now time.Time) {
+	expired := []registry.ServiceNode{}
+
+	r.mu.Lock()
+	for id, rec := range r.nodes {
+		if rec.expired(now) {
+			expired = append(expired, rec.node)
+			delete(r.nodes, id)
+		}
+	}
+	r.mu.Unlock()
+
+	for _, node := range expired {
+		r.logger.Debug("Node expired", "node", node.String())
+		r.notify(registry.Result{Action: registry.Delete, Node: node})
+	}
+}
+
+func (r *Registry) notify(result registry.Result) {
+	r.watchersMu.RLock()
+	watchers := slices.Collect(maps.Keys(r.watchers))
+	r.watchersMu.RUnlock()
+
+	for _, w := range watchers {
+		w.send(result)
+	}
+}
+
+func (r *Registry) removeWatcher(w *Watcher) {
+	r.watchersMu.Lock()
+	delete(r.watchers, w)
+	r.watchersMu.Unlock()
+}
+
+// matches checks if node is in namespace and region and has one of the schemes.
+func matches(node registry.ServiceNode, namespace, region string, schemes []string) bool {
+	if node.Namespace != namespace || node.Region != region {
+		return false
+	}
+
+	return len(schemes) == 0 || slices.Contains(schemes, node.Scheme)
+}
@@ -0,0 +1,191 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/go-orb/go-orb/log"
+	"github.com/go-orb/go-orb/registry"
+)
+
+func newTestRegistry(t *testing.T, opts ...registry.Option) *Registry {
+	t.Helper()
+
+	reg := New(NewConfig(opts...), log.Logger{Logger: slog.New(slog.NewTextHandler(io.Discard, nil))})
+	if err := reg.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { _ = reg.Stop(context.Background()) }) //nolint:errcheck
+
+	return reg
+}
+
+func testNode(name, address string) registry.ServiceNode {
+	return registry.ServiceNode{
+		Name:    name,
+		Version: "v1",
+		Node:    "grpc",
+		Scheme:  "grpc",
+		Address: address,
+	}
+}
+
+func TestGetService(t *testing.T) {
+	reg := newTestRegistry(t)
+	ctx := context.Background()
+
+	if err := reg.Register(ctx, testNode("svc", "127.0.0.1:1")); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := reg.Register(ctx, testNode("other", "127.0.0.1:2")); err != nil {
+		t.Fatal(err)
+	}
+
+	nodes, err := reg.GetService(ctx, "", "", "svc", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := len(nodes), 1; got != want {
+		t.Fatalf("got: %d nodes, want: %d", got, want)
+	}
+
+	if _, err := reg.GetService(ctx, "", "", "svc", []string{"http"}); !errors.Is(err, registry.ErrNotFound) {
+		t.Errorf("got: %v, want: %v", err, registry.ErrNotFound)
+	}
+
+	if _, err := reg.GetService(ctx, "other-namespace", "", "svc", nil); !errors.Is(err, registry.ErrNotFound) {
+		t.Errorf("got: %v, want: %v", err, registry.ErrNotFound)
+	}
+
+	nodes, err = reg.ListServices(ctx, "", "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := len(nodes), 2; got != want {
+		t.Fatalf("got: %d nodes, want: %d", got, want)
+	}
+}
+
+func TestWatchAndExpiry(t *testing.T) {
+	reg := newTestRegistry(t, WithExpiryInterval(10*time.Millisecond))
+	ctx := context.Background()
+
+	watcher, err := reg.Watch(ctx, registry.WatchService("svc"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	node := testNode("svc", "127.0.0.1:1")
+	node.TTL = 50 * time.Millisecond
+
+	if err := reg.Register(ctx, node); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := reg.Register(ctx, node); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []registry.EventType{registry.Create, registry.Update, registry.Delete} {
+		result, err := watcher.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if result.Action != want {
+			t.Errorf("got: %s, want: %s", result.Action, want)
+		}
+	}
+
+	if _, err := reg.GetService(ctx, "", "", "svc", nil); !errors.Is(err, registry.ErrNotFound) {
+		t.Errorf("got: %v, want: %v", err, registry.ErrNotFound)
+	}
+}
+
+func TestSlowWatcher(t *testing.T) {
+	reg := newTestRegistry(t, WithWatcherBuffer(2))
+	ctx := context.Background()
+
+	// The watcher never gets read, it must not block the registry.
+	slow, err := reg.Watch(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		for i := range 10 {
+			node := testNode("svc", fmt.Sprintf("127.0.0.1:%d", i))
+			if err := reg.Register(ctx, node); err != nil {
+				t.Error(err)
+			}
+		}
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("the registry has been blocked by a slow watcher")
+	}
+
+	if _, err := slow.Next(); !errors.Is(err, registry.ErrWatcherStopped) {
+		t.Fatalf("expected the slow watcher to be stopped, got %v", err)
+	}
+}
+
+func TestWatcherStop(t *testing.T) {
+	reg := newTestRegistry(t)
+
+	w, err := reg.Watch(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	errs := make(chan error, 1)
+
+	go func() {
+		_, err := w.Next()
+		errs <- err
+	}()
+
+	// Let Next block.
+	time.Sleep(20 * time.Millisecond)
+
+	if err := w.Stop(); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case err := <-errs:
+		if !errors.Is(err, registry.ErrWatcherStopped) {
+			t.Fatalf("expected ErrWatcherStopped, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Stop didn't unblock Next")
+	}
+
+	// A second Stop is safe, so is registering after the watcher stopped.
+	if err := w.Stop(); err != nil {
+		t.Fatalf("expected a second Stop to succeed, got %v", err)
+	}
+
+	if err := reg.Register(context.Background(), testNode("svc", "127.0.0.1:1")); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := w.Next(); !errors.Is(err, registry.ErrWatcherStopped) {
+		t.Fatalf("expected ErrWatcherStopped after Stop, got %v", err)
+	}
+}
@@ -0,0 +1,81 @@
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"github.com/go-orb/go-orb/registry"
+)
+
+var _ registry.Watcher = (*Watcher)(nil)
+
+// Watcher receives the results of a memory registry.
+type Watcher struct {
+	ctx     context.Context //nolint:containedctx
+	options registry.WatchOptions
+
+	results chan registry.Result
+	exit    chan struct{}
+	once    sync.Once
+
+	reg *Registry
+}
+
+// Next blocks until a result arrives or the watcher has been stopped.
+//
+// A watcher which falls more than the WatcherBuffer behind gets stopped, the
+// caller has to create a new one and fetch the current nodes.
+func (w *Watcher) Next() (*registry.Result, error) {
+	// Don't hand out buffered results of a stopped watcher, they may be incomplete.
+	select {
+	case <-w.exit:
+		return nil, registry.ErrWatcherStopped
+	default:
+	}
+
+	select {
+	case result := <-w.results:
+		return &result, nil
+	case <-w.exit:
+		return nil, registry.ErrWatcherStopped
+	case <-w.ctx.Done():
+		_ = w.Stop() //nolint:errcheck
+		return nil, registry.ErrWatcherStopped
+	}
+}
+
+// send forwards result to the watcher if it matches its options, it never blocks.
+// It stops the watcher if its buffer is full, so a slow reader can't stall the registry.
+func (w *Watcher) send(result registry.Result) {
+	if !w.options.Match(result.Node) {
+		return
+	}
+
+	if w.ctx.Err() != nil {
+		_ = w.Stop() //nolint:errcheck
+		return
+	}
+
+	select {
+	case <-w.exit:
+		return
+	default:
+	}
+
+	select {
+	case w.results <- result:
+	default:
+		w.reg.logger.Warn("stopping a watcher which fell behind", "buffer", cap(w.results))
+		_ = w.Stop() //nolint:errcheck
+	}
+}
+
+// Stop stops the watcher, Next returns registry.ErrWatcherStopped afterwards.
+func (w *Watcher) Stop() error {
+	w.once.Do(func() {
+		close(w.exit)
+		w.reg.removeWatcher(w)
+	})
+
+	return nil
+}
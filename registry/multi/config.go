@@ -0,0 +1,102 @@
+package multi
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/go-orb/go-orb/registry"
+)
+
+// ErrUnknownPolicy is returned for a policy other than PolicyFailFast and PolicyBestEffort.
+var ErrUnknownPolicy = errors.New("unknown policy")
+
+// Policy defines how errors of a child registry are handled.
+type Policy string
+
+const (
+	// PolicyFailFast returns the error of a child registry to the caller.
+	PolicyFailFast Policy = "failfast"
+	// PolicyBestEffort logs the error of a child registry and continues with the others.
+	PolicyBestEffort Policy = "besteffort"
+)
+
+// validate returns ErrUnknownPolicy when p isn't one of the known policies.
+func (p Policy) validate() error {
+	switch p {
+	case PolicyFailFast, PolicyBestEffort:
+		return nil
+	default:
+		return fmt.Errorf("%w: %q", ErrUnknownPolicy, p)
+	}
+}
+
+//nolint:gochecknoglobals
+var (
+	// DefaultPolicy is the policy used for children without a policy.
+	DefaultPolicy = PolicyBestEffort
+)
+
+// child is a child registry with its error policy.
+type child struct {
+	registry registry.Registry
+	policy   Policy
+}
+
+// Config is the configuration of the multi registry.
+type Config struct {
+	registry.Config `yaml:",inline"`
+
+	// Registries contains the configs of the child registries, each needs at least a "plugin".
+	// Set "policy" in a child config to override the default policy for it.
+	//
+	// Example:
+	//
+	//	registry:
+	//	  plugin: multi
+	//	  registries:
+	//	    - plugin: mdns
+	//	    - plugin: consul
+	//	      policy: failfast
+	Registries []map[string]any `json:"registries,omitempty" yaml:"registries,omitempty"`
+
+	// Policy is the default error policy for the child registries.
+	Policy Policy `json:"policy,omitempty" yaml:"policy,omitempty"`
+
+	children []child
+}
+
+// NewConfig creates a new config with the defaults and applies opts on top.
+func NewConfig(opts ...registry.Option) Config {
+	cfg := Config{
+		Config: registry.NewConfig(registry.WithPlugin(Name)),
+		Policy: DefaultPolicy,
+	}
+
+	// Apply options.
+	for _, o := range opts {
+		o(&cfg)
+	}
+
+	return cfg
+}
+
+// WithPolicy sets the default error policy for the child registries.
+func WithPolicy(p Policy) registry.Option {
+	return func(c registry.ConfigType) {
+		cfg, ok := c.(*Config)
+		if ok {
+			cfg.Policy = p
+		}
+	}
+}
+
+// WithRegistry adds an already created child registry with the given policy.
+// Children added with this option come before the configured ones.
+func WithRegistry(reg registry.Registry, p Policy) registry.Option {
+	return func(c registry.ConfigType) {
+		cfg, ok := c.(*Config)
+		if ok {
+			cfg.children = append(cfg.children, child{registry: reg, policy: p})
+		}
+	}
+}
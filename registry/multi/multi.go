@@ -0,0 +1,293 @@
+// Package multi provides a registry which combines several registries,
+// this is useful while migrating from one registry to another.
+package multi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/hashicorp/go-multierror"
+
+	"github.com/go-orb/go-orb/config"
+	"github.com/go-orb/go-orb/log"
+	"github.com/go-orb/go-orb/registry"
+	"github.com/go-orb/go-orb/types"
+)
+
+// Name is the name of this registry implementation.
+const Name = "multi"
+
+var _ registry.Registry = (*Registry)(nil)
+
+func init() {
+	registry.Plugins.Add(Name, Provide)
+}
+
+// Registry fans out registrations to all its children and merges their results.
+type Registry struct {
+	config   Config
+	logger   log.Logger
+	children []child
+}
+
+// Provide creates a new multi registry and all its configured children.
+func Provide(
+	configData map[string]any,
+	components *types.Components,
+	logger log.Logger,
+	opts ...registry.Option,
+) (registry.Type, error) {
+	cfg := NewConfig(opts...)
+
+	if err := config.Parse(nil, registry.DefaultConfigSection, configData, &cfg); err != nil && !errors.Is(err, config.ErrNoSuchKey) {
+		return registry.Type{}, err
+	}
+
+	if err := cfg.Policy.validate(); err != nil {
+		return registry.Type{}, err
+	}
+
+	for _, c := range cfg.children {
+		if err := c.policy.validate(); err != nil {
+			return registry.Type{}, fmt.Errorf("child registry %s: %w", c.registry.String(), err)
+		}
+	}
+
+	for idx, childData := range cfg.Registries {
+		policy := cfg.Policy
+		if p, ok := childData["policy"].(string); ok && p != "" {
+			policy = Policy(p)
+		}
+
+		if err := policy.validate(); err != nil {
+			return registry.Type{}, fmt.Errorf("child registry %d: %w", idx, err)
+		}
+
+		reg, err := registry.New(map[string]any{registry.DefaultConfigSection: childData}, components, logger)
+		if err != nil {
+			return registry.Type{}, fmt.Errorf("while creating child registry %d: %w", idx, err)
+		}
+
+		cfg.children = append(cfg.children, child{registry: reg.Registry, policy: policy})
+	}
+
+	return registry.Type{Registry: New(cfg, logger)}, nil
+}
+
+// New creates a new multi registry from the children of cfg.
+func New(cfg Config, logger log.Logger) *Registry {
+	return &Registry{
+		config:   cfg,
+		logger:   logger,
+		children: cfg.children,
+	}
+}
+
+// Start starts all children. When it fails, the children which
+// already started are stopped again.
+func (r *Registry) Start(ctx context.Context) error {
+	started := []child{}
+
+	err := r.each(func(c child) error {
+		if err := c.registry.Start(ctx); err != nil {
+			return err
+		}
+
+		started = append(started, c)
+
+		return nil
+	})
+	if err == nil {
+		return nil
+	}
+
+	for _, c := range started {
+		if sErr := c.registry.Stop(ctx); sErr != nil {
+			r.logger.Warn("while rolling back a start", "registry", c.registry.String(), "error", sErr)
+		}
+	}
+
+	return err
+}
+
+// Stop stops all children regardless of their policy.
+func (r *Registry) Stop(ctx context.Context) error {
+	var result error
+
+	for _, c := range r.children {
+		if err := c.registry.Stop(ctx); err != nil {
+			result = multierror.Append(result, fmt.Errorf("registry %s: %w", c.registry.String(), err))
+		}
+	}
+
+	return result
+}
+
+// String returns the plugin name.
+func (r *Registry) String() string {
+	return Name
+}
+
+// Type returns the component type.
+func (r *Registry) Type() string {
+	return registry.ComponentType
+}
+
+// Register registers the node in all children. When it fails, the children
+// which already registered the node deregister it again.
+func (r *Registry) Register(ctx context.Context, srv registry.ServiceNode) error {
+	registered := []child{}
+
+	err := r.each(func(c child) error {
+		if err := c.registry.Register(ctx, srv); err != nil {
+			return err
+		}
+
+		registered = append(registered, c)
+
+		return nil
+	})
+	if err == nil {
+		return nil
+	}
+
+	for _, c := range registered {
+		if dErr := c.registry.Deregister(ctx, srv); dErr != nil {
+			r.logger.Warn("while rolling back a registration", "registry", c.registry.String(), "error", dErr)
+		}
+	}
+
+	return err
+}
+
+// Deregister deregisters the node in all children.
+func (r *Registry) Deregister(ctx context.Context, srv registry.ServiceNode) error {
+	return r.each(func(c child) error {
+		return c.registry.Deregister(ctx, srv)
+	})
+}
+
+// GetService returns the merged nodes of a service from all children.
+func (r *Registry) GetService(
+	ctx context.Context,
+	namespace, region, name string,
+	schemes []string,
+) ([]registry.ServiceNode, error) {
+	nodes, err := r.merge(func(c child) ([]registry.ServiceNode, error) {
+		return c.registry.GetService(ctx, namespace, region, name, schemes)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(nodes) == 0 {
+		return nil, registry.ErrNotFound
+	}
+
+	return nodes, nil
+}
+
+// ListServices returns the merged nodes of all children.
+func (r *Registry) ListServices(
+	ctx context.Context,
+	namespace, region string,
+	schemes []string,
+) ([]registry.ServiceNode, error) {
+	return r.merge(func(c child) ([]registry.ServiceNode, error) {
+		return c.registry.ListServices(ctx, namespace, region, schemes)
+	})
+}
+
+// Watch returns a Watcher which multiplexes the watchers of all children.
+func (r *Registry) Watch(ctx context.Context, opts ...registry.WatchOption) (registry.Watcher, error) {
+	watchers := []registry.Watcher{}
+
+	err := r.each(func(c child) error {
+		w, err := c.registry.Watch(ctx, opts...)
+		if err != nil {
+			return err
+		}
+
+		watchers = append(watchers, w)
+
+		return nil
+	})
+	if err != nil {
+		for _, w := range watchers {
+			_ = w.Stop() //nolint:errcheck
+		}
+
+		return nil, err
+	}
+
+	return newWatcher(ctx, watchers), nil
+}
+
+// each runs fn for every child and applies the childs policy on errors.
+//
+// With PolicyFailFast the first error is returned, with PolicyBestEffort it's
+// logged. When all children failed, the last error is returned.
+func (r *Registry) each(fn func(c child) error) error {
+	var lastErr error
+
+	failed := 0
+
+	for _, c := range r.children {
+		err := fn(c)
+		if err == nil {
+			continue
+		}
+
+		err = fmt.Errorf("registry %s: %w", c.registry.String(), err)
+
+		if c.policy == PolicyFailFast {
+			return err
+		}
+
+		r.logger.Warn("a child registry failed, continuing with the others", "error", err)
+
+		failed++
+		lastErr = err
+	}
+
+	if len(r.children) > 0 && failed == len(r.children) {
+		return lastErr
+	}
+
+	return nil
+}
+
+// merge collects the nodes returned by fn from all children,
+// nodes are de-duplicated by their ID, the first child wins.
+func (r *Registry) merge(fn func(c child) ([]registry.ServiceNode, error)) ([]registry.ServiceNode, error) {
+	result := []registry.ServiceNode{}
+	seen := make(map[string]struct{})
+
+	err := r.each(func(c child) error {
+		nodes, err := fn(c)
+		if errors.Is(err, registry.ErrNotFound) {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		for _, node := range nodes {
+			if _, ok := seen[node.ID()]; ok {
+				continue
+			}
+
+			seen[node.ID()] = struct{}{}
+			result = append(result, node)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
@@ -0,0 +1,260 @@
+package multi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/go-orb/go-orb/codecs"
+	"github.com/go-orb/go-orb/log"
+	"github.com/go-orb/go-orb/registry"
+	"github.com/go-orb/go-orb/registry/memory"
+)
+
+var errBroken = errors.New("broken")
+
+// jsonCodec is a minimal JSON codec, config.Parse needs one.
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v any) ([]byte, error)         { return json.Marshal(v) }
+func (jsonCodec) Unmarshal(data []byte, v any) error    { return json.Unmarshal(data, v) }
+func (jsonCodec) Marshals(any) bool                     { return true }
+func (jsonCodec) Unmarshals(any) bool                   { return true }
+func (jsonCodec) NewDecoder(r io.Reader) codecs.Decoder { return json.NewDecoder(r) }
+func (jsonCodec) NewEncoder(w io.Writer) codecs.Encoder { return json.NewEncoder(w) }
+func (jsonCodec) ContentTypes() []string                { return []string{codecs.MimeJSON} }
+func (jsonCodec) Name() string                          { return "json" }
+func (jsonCodec) Exts() []string                        { return []string{".json"} }
+
+func init() {
+	codecs.Register("json", jsonCodec{})
+}
+
+// broken is a registry which fails all registrations.
+type broken struct {
+	*memory.Registry
+}
+
+func (b *broken) Register(context.Context, registry.ServiceNode) error {
+	return errBroken
+}
+
+// lifecycle is a registry which records Start and Stop, it fails to start when fail is set.
+type lifecycle struct {
+	*memory.Registry
+
+	fail    bool
+	running bool
+}
+
+func (l *lifecycle) Start(context.Context) error {
+	if l.fail {
+		return errBroken
+	}
+
+	l.running = true
+
+	return nil
+}
+
+func (l *lifecycle) Stop(context.Context) error {
+	l.running = false
+	return nil
+}
+
+func testLogger() log.Logger {
+	return log.Logger{Logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+}
+
+func newTestChild(t *testing.T) *memory.Registry {
+	t.Helper()
+
+	reg := memory.New(memory.NewConfig(), testLogger())
+	if err := reg.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { _ = reg.Stop(context.Background()) }) //nolint:errcheck
+
+	return reg
+}
+
+func testNode(name, address string) registry.ServiceNode {
+	return registry.ServiceNode{Name: name, Version: "v1", Node: "grpc", Scheme: "grpc", Address: address}
+}
+
+func TestMerge(t *testing.T) {
+	ctx := context.Background()
+	a, b := newTestChild(t), newTestChild(t)
+
+	reg := New(NewConfig(WithRegistry(a, PolicyFailFast), WithRegistry(b, PolicyFailFast)), testLogger())
+
+	for _, tc := range []struct {
+		child *memory.Registry
+		node  registry.ServiceNode
+	}{
+		{a, testNode("svc", "127.0.0.1:1")},
+		{a, testNode("svc", "127.0.0.1:2")},
+		{b, testNode("svc", "127.0.0.1:2")},
+		{b, testNode("other", "127.0.0.1:3")},
+	} {
+		if err := tc.child.Register(ctx, tc.node); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	nodes, err := reg.GetService(ctx, "", "", "svc", nil)
+	if err != nil || len(nodes) != 2 {
+		t.Fatalf("expected 2 de-duplicated nodes, got %d: %v", len(nodes), err)
+	}
+
+	nodes, err = reg.ListServices(ctx, "", "", nil)
+	if err != nil || len(nodes) != 3 {
+		t.Fatalf("expected 3 de-duplicated nodes, got %d: %v", len(nodes), err)
+	}
+
+	if _, err := reg.GetService(ctx, "", "", "unknown", nil); !errors.Is(err, registry.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestRegisterRollback(t *testing.T) {
+	ctx := context.Background()
+	a := newTestChild(t)
+
+	reg := New(NewConfig(WithRegistry(a, PolicyFailFast), WithRegistry(&broken{newTestChild(t)}, PolicyFailFast)), testLogger())
+
+	if err := reg.Register(ctx, testNode("svc", "127.0.0.1:1")); !errors.Is(err, errBroken) {
+		t.Fatalf("expected the broken child to fail, got %v", err)
+	}
+
+	if _, err := a.GetService(ctx, "", "", "svc", nil); !errors.Is(err, registry.ErrNotFound) {
+		t.Fatalf("expected the registration to be rolled back, got %v", err)
+	}
+
+	// Best effort keeps the registration in the working children.
+	reg = New(NewConfig(WithRegistry(a, PolicyBestEffort), WithRegistry(&broken{newTestChild(t)}, PolicyBestEffort)), testLogger())
+
+	if err := reg.Register(ctx, testNode("svc", "127.0.0.1:1")); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := a.GetService(ctx, "", "", "svc", nil); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestProvidePolicy(t *testing.T) {
+	for _, tc := range []struct {
+		policy string
+		err    error
+	}{
+		{"failfast", nil},
+		{"besteffort", nil},
+		{"", nil},
+		{"fail-fast", ErrUnknownPolicy},
+	} {
+		data := map[string]any{
+			registry.DefaultConfigSection: map[string]any{
+				"plugin":     Name,
+				"registries": []any{map[string]any{"plugin": memory.Name, "policy": tc.policy}},
+			},
+		}
+
+		if _, err := Provide(data, nil, testLogger()); !errors.Is(err, tc.err) {
+			t.Fatalf("policy %q: expected %v, got %v", tc.policy, tc.err, err)
+		}
+	}
+
+	if _, err := Provide(nil, nil, testLogger(), WithPolicy("fail-fast")); !errors.Is(err, ErrUnknownPolicy) {
+		t.Fatalf("expected ErrUnknownPolicy for the default policy, got %v", err)
+	}
+
+	if _, err := Provide(nil, nil, testLogger(), WithRegistry(newTestChild(t), "fail-fast")); !errors.Is(err, ErrUnknownPolicy) {
+		t.Fatalf("expected ErrUnknownPolicy for WithRegistry, got %v", err)
+	}
+}
+
+func TestStartRollback(t *testing.T) {
+	ctx := context.Background()
+	a, b := &lifecycle{}, &lifecycle{fail: true}
+
+	reg := New(NewConfig(WithRegistry(a, PolicyFailFast), WithRegistry(b, PolicyFailFast)), testLogger())
+
+	if err := reg.Start(ctx); !errors.Is(err, errBroken) {
+		t.Fatalf("expected the broken child to fail, got %v", err)
+	}
+
+	if a.running {
+		t.Fatal("expected the started child to be stopped again")
+	}
+
+	// Best effort keeps the working children running.
+	reg = New(NewConfig(WithRegistry(a, PolicyBestEffort), WithRegistry(b, PolicyBestEffort)), testLogger())
+
+	if err := reg.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if !a.running {
+		t.Fatal("expected the working child to keep running")
+	}
+}
+
+func TestWatcher(t *testing.T) {
+	ctx := context.Background()
+	a, b := newTestChild(t), newTestChild(t)
+
+	reg := New(NewConfig(WithRegistry(a, PolicyFailFast), WithRegistry(b, PolicyFailFast)), testLogger())
+
+	watcher, err := reg.Watch(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer watcher.Stop() //nolint:errcheck
+
+	results := registry.WatchChan(ctx, watcher)
+	node := testNode("svc", "127.0.0.1:1")
+
+	expect := func(action registry.EventType) {
+		t.Helper()
+
+		select {
+		case result := <-results:
+			if result.Action != action {
+				t.Fatalf("expected %s, got %s", action, result.Action)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("expected %s, got nothing", action)
+		}
+	}
+
+	expectNothing := func() {
+		t.Helper()
+
+		select {
+		case result := <-results:
+			t.Fatalf("expected nothing, got %s", result.Action)
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+
+	// The node is created once, no matter how many children know it.
+	_ = a.Register(ctx, node) //nolint:errcheck
+	expect(registry.Create)
+
+	_ = b.Register(ctx, node) //nolint:errcheck
+	expectNothing()
+
+	// It gets deleted once the last child removed it.
+	_ = a.Deregister(ctx, node) //nolint:errcheck
+	expectNothing()
+
+	_ = b.Deregister(ctx, node) //nolint:errcheck
+	expect(registry.Delete)
+}
@@ -0,0 +1,152 @@
+package multi
+
+import (
+	"context"
+	"sync"
+
+	"github.com/go-orb/go-orb/registry"
+)
+
+var _ registry.Watcher = (*Watcher)(nil)
+
+// Watcher multiplexes the watchers of the child registries.
+//
+// A node known by multiple children is reported once, it gets created
+// by the first child reporting it and deleted once the last child removed it.
+type Watcher struct {
+	watchers []registry.Watcher
+
+	results chan registry.Result
+	exit    chan struct{}
+	once    sync.Once
+
+	mu sync.Mutex
+	// owners contains the indexes of the children which know a node ID.
+	owners map[string]map[int]struct{}
+}
+
+func newWatcher(ctx context.Context, watchers []registry.Watcher) *Watcher {
+	w := &Watcher{
+		watchers: watchers,
+		results:  make(chan registry.Result),
+		exit:     make(chan struct{}),
+		owners:   make(map[string]map[int]struct{}),
+	}
+
+	wg := sync.WaitGroup{}
+
+	for idx, cw := range watchers {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			w.forward(idx, cw)
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(w.results)
+	}()
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			_ = w.Stop() //nolint:errcheck
+		case <-w.exit:
+		}
+	}()
+
+	return w
+}
+
+// Next blocks until a child reports a result or all children have stopped.
+func (w *Watcher) Next() (*registry.Result, error) {
+	select {
+	case result, ok := <-w.results:
+		if !ok {
+			return nil, registry.ErrWatcherStopped
+		}
+
+		return &result, nil
+	case <-w.exit:
+		return nil, registry.ErrWatcherStopped
+	}
+}
+
+// Stop stops the watchers of all children.
+func (w *Watcher) Stop() error {
+	w.once.Do(func() {
+		close(w.exit)
+
+		for _, cw := range w.watchers {
+			_ = cw.Stop() //nolint:errcheck
+		}
+	})
+
+	return nil
+}
+
+// forward sends the results of the watcher of child idx to w.results.
+func (w *Watcher) forward(idx int, cw registry.Watcher) {
+	for {
+		result, err := cw.Next()
+		if err != nil {
+			return
+		}
+
+		if !w.dedupe(idx, result) {
+			continue
+		}
+
+		select {
+		case w.results <- *result:
+		case <-w.exit:
+			return
+		}
+	}
+}
+
+// dedupe tracks which child knows which node, it returns false if the result
+// should not be forwarded and rewrites the action of the result if required.
+func (w *Watcher) dedupe(idx int, result *registry.Result) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	id := result.Node.ID()
+	owners, ok := w.owners[id]
+
+	if result.Action == registry.Delete {
+		delete(owners, idx)
+
+		if len(owners) > 0 {
+			return false
+		}
+
+		delete(w.owners, id)
+
+		return true
+	}
+
+	if !ok {
+		owners = make(map[int]struct{})
+		w.owners[id] = owners
+	}
+
+	_, known := owners[idx]
+	first := len(owners) == 0
+	owners[idx] = struct{}{}
+
+	switch {
+	case first:
+		result.Action = registry.Create
+	case !known && result.Action == registry.Create:
+		// Another child already reported this node.
+		return false
+	default:
+		result.Action = registry.Update
+	}
+
+	return true
+}
@@ -1,18 +1,117 @@
 package registry
 
+import "slices"
+
 // WatchOptions are the options used by the registry watcher.
 type WatchOptions struct {
 	// Specify a service to watch
 	// If blank, the watch is for all services
 	Service string
+
+	// Namespace limits the watch to a namespace, if not blank.
+	Namespace string
+
+	// Region limits the watch to a region, if not blank.
+	Region string
+
+	// Version limits the watch to a service version, if not blank.
+	Version string
+
+	// Schemes limits the watch to nodes with one of these schemes, if not empty.
+	Schemes []string
+
+	// Metadata limits the watch to nodes which contain all of these metadata key/values.
+	Metadata map[string]string
 }
 
 // WatchOption is functional option type for the watch config.
 type WatchOption func(*WatchOptions)
 
+// NewWatchOptions creates WatchOptions from the given opts.
+func NewWatchOptions(opts ...WatchOption) WatchOptions {
+	options := WatchOptions{}
+
+	for _, o := range opts {
+		o(&options)
+	}
+
+	return options
+}
+
+// Match returns true if the node passes all filters of the options.
+func (o WatchOptions) Match(node ServiceNode) bool {
+	if o.Service != "" && o.Service != node.Name {
+		return false
+	}
+
+	if o.Namespace != "" && o.Namespace != node.Namespace {
+		return false
+	}
+
+	if o.Region != "" && o.Region != node.Region {
+		return false
+	}
+
+	if o.Version != "" && o.Version != node.Version {
+		return false
+	}
+
+	if len(o.Schemes) > 0 && !slices.Contains(o.Schemes, node.Scheme) {
+		return false
+	}
+
+	for k, v := range o.Metadata {
+		if nv, ok := node.Metadata[k]; !ok || nv != v {
+			return false
+		}
+	}
+
+	return true
+}
+
 // WatchService sets a service name to watch.
 func WatchService(name string) WatchOption {
 	return func(o *WatchOptions) {
 		o.Service = name
 	}
 }
+
+// WatchNamespace limits the watch to the given namespace.
+func WatchNamespace(namespace string) WatchOption {
+	return func(o *WatchOptions) {
+		o.Namespace = namespace
+	}
+}
+
+// WatchRegion limits the watch to the given region.
+func WatchRegion(region string) WatchOption {
+	return func(o *WatchOptions) {
+		o.Region = region
+	}
+}
+
+// WatchVersion limits the watch to the given service version.
+func WatchVersion(version string) WatchOption {
+	return func(o *WatchOptions) {
+		o.Version = version
+	}
+}
+
+// WatchSchemes limits the watch to nodes with one of the given schemes.
+func WatchSchemes(schemes ...string) WatchOption {
+	return func(o *WatchOptions) {
+		o.Schemes = append(o.Schemes, schemes...)
+	}
+}
+
+// WatchMetadata limits the watch to nodes which have the given metadata key and value.
+// It can be given multiple times, all pairs must match.
+func WatchMetadata(key, value string) WatchOption {
+	return func(o *WatchOptions) {
+		if o.Metadata == nil {
+			o.Metadata = make(map[string]string)
+		}
+
+		o.Metadata[key] = value
+	}
+}
@@ -0,0 +1,63 @@
+package registry_test
+
+import (
+	"testing"
+
+	"github.com/go-orb/go-orb/registry"
+)
+
+func TestWatchOptionsMatch(t *testing.T) {
+	node := registry.ServiceNode{
+		Name:      "svc",
+		Namespace: "prod",
+		Region:    "eu",
+		Version:   "v1",
+		Scheme:    "grpc",
+		Metadata:  map[string]string{"zone": "eu-1", "canary": "true"},
+	}
+
+	for _, tc := range []struct {
+		name     string
+		opts     []registry.WatchOption
+		expected bool
+	}{
+		{"no filter", nil, true},
+		{"service", []registry.WatchOption{registry.WatchService("svc")}, true},
+		{"other service", []registry.WatchOption{registry.WatchService("other")}, false},
+		{"namespace", []registry.WatchOption{registry.WatchNamespace("prod")}, true},
+		{"other namespace", []registry.WatchOption{registry.WatchNamespace("dev")}, false},
+		{"region", []registry.WatchOption{registry.WatchRegion("eu")}, true},
+		{"other region", []registry.WatchOption{registry.WatchRegion("us")}, false},
+		{"version", []registry.WatchOption{registry.WatchVersion("v1")}, true},
+		{"other version", []registry.WatchOption{registry.WatchVersion("v2")}, false},
+		{"scheme", []registry.WatchOption{registry.WatchSchemes("http", "grpc")}, true},
+		{"schemes of multiple options", []registry.WatchOption{registry.WatchSchemes("http"), registry.WatchSchemes("grpc")}, true},
+		{"other scheme", []registry.WatchOption{registry.WatchSchemes("http", "drpc")}, false},
+		{"metadata", []registry.WatchOption{registry.WatchMetadata("zone", "eu-1")}, true},
+		{"all metadata", []registry.WatchOption{registry.WatchMetadata("zone", "eu-1"), registry.WatchMetadata("canary", "true")}, true},
+		{"other metadata value", []registry.WatchOption{registry.WatchMetadata("zone", "eu-2")}, false},
+		{"one metadata pair missing", []registry.WatchOption{registry.WatchMetadata("zone", "eu-1"), registry.WatchMetadata("tier", "gold")}, false},
+		{"all filters", []registry.WatchOption{
+			registry.WatchService("svc"),
+			registry.WatchNamespace("prod"),
+			registry.WatchRegion("eu"),
+			registry.WatchVersion("v1"),
+			registry.WatchSchemes("grpc"),
+			registry.WatchMetadata("canary", "true"),
+		}, true},
+		{"one filter fails", []registry.WatchOption{
+			registry.WatchService("svc"),
+			registry.WatchNamespace("prod"),
+			registry.WatchRegion("us"),
+		}, false},
+	} {
+		if got := registry.NewWatchOptions(tc.opts...).Match(node); got != tc.expected {
+			t.Fatalf("%s: expected %v, got %v", tc.name, tc.expected, got)
+		}
+	}
+
+	// Nodes without metadata don't match a metadata filter.
+	if registry.NewWatchOptions(registry.WatchMetadata("zone", "")).Match(registry.ServiceNode{}) {
+		t.Fatal("expected a node without metadata not to match")
+	}
+}
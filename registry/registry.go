@@ -123,6 +123,14 @@ func (r ServiceNode) Valid() error {
 	return nil
 }
 
+// ID returns a key that identifies this node within a registry.
+//
+// Two ServiceNodes with the same ID are the same node, they may only differ
+// in their Metadata and TTL.
+func (r ServiceNode) ID() string {
+	return r.Namespace + "/" + r.Region + "/" + r.Name + "/" + r.Version + "/" + r.Node + "/" + r.Scheme + "/" + r.Address
+}
+
 func (r ServiceNode) String() string {
 	return fmt.Sprintf("ServiceNode{%s %s %s %s %s %s}", r.Namespace, r.Region, r.Name, r.Version, r.Address, r.Scheme)
 }
@@ -193,6 +201,10 @@ func New(
 		return Type{}, err
 	}
 
+	if cfg.Cache.Enabled {
+		return Type{Registry: NewCache(instance.Registry, time.Duration(cfg.Cache.TTL), cLogger)}, nil
+	}
+
 	return Type{Registry: instance}, nil
 }
 
@@ -0,0 +1,87 @@
+package static
+
+import (
+	"time"
+
+	"github.com/go-orb/go-orb/config"
+	"github.com/go-orb/go-orb/registry"
+)
+
+//nolint:gochecknoglobals
+var (
+	// DefaultReloadInterval is the interval in which the nodes file gets checked for changes.
+	DefaultReloadInterval = config.Duration(10 * time.Second)
+)
+
+// Config is the configuration of the static registry.
+type Config struct {
+	registry.Config `yaml:",inline"`
+
+	// Nodes is the static list of nodes.
+	Nodes []registry.ServiceNode `json:"nodes,omitempty" yaml:"nodes,omitempty"`
+
+	// File is an URL readable by config.Read, e.g. "file:///etc/service/nodes.yaml".
+	// Its "nodes" key gets added to Nodes.
+	File string `json:"file,omitempty" yaml:"file,omitempty"`
+
+	// ReloadInterval is the interval in which File gets reloaded, 0 disables reloading.
+	ReloadInterval config.Duration `json:"reloadInterval,omitempty" yaml:"reloadInterval,omitempty"`
+
+	// RejectRegister makes Register and Deregister return ErrReadOnly instead of being a no-op.
+	RejectRegister bool `json:"rejectRegister,omitempty" yaml:"rejectRegister,omitempty"`
+}
+
+// NewConfig creates a new config with the defaults and applies opts on top.
+func NewConfig(opts ...registry.Option) Config {
+	cfg := Config{
+		Config:         registry.NewConfig(registry.WithPlugin(Name)),
+		ReloadInterval: DefaultReloadInterval,
+	}
+
+	// Apply options.
+	for _, o := range opts {
+		o(&cfg)
+	}
+
+	return cfg
+}
+
+// WithNodes adds static nodes.
+func WithNodes(nodes ...registry.ServiceNode) registry.Option {
+	return func(c registry.ConfigType) {
+		cfg, ok := c.(*Config)
+		if ok {
+			cfg.Nodes = append(cfg.Nodes, nodes...)
+		}
+	}
+}
+
+// WithFile sets the URL of the nodes file.
+func WithFile(url string) registry.Option {
+	return func(c registry.ConfigType) {
+		cfg, ok := c.(*Config)
+		if ok {
+			cfg.File = url
+		}
+	}
+}
+
+// WithReloadInterval sets the interval in which the nodes file gets reloaded.
+func WithReloadInterval(n time.Duration) registry.Option {
+	return func(c registry.ConfigType) {
+		cfg, ok := c.(*Config)
+		if ok {
+			cfg.ReloadInterval = config.Duration(n)
+		}
+	}
+}
+
+// WithRejectRegister makes Register and Deregister return ErrReadOnly.
+func WithRejectRegister() registry.Option {
+	return func(c registry.ConfigType) {
+		cfg, ok := c.(*Config)
+		if ok {
+			cfg.RejectRegister = true
+		}
+	}
+}
@@ -0,0 +1,252 @@
+// Package static provides a registry with a fixed list of nodes, taken from
+// the config or a file, for deployments with fixed addresses.
+package static
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/go-orb/go-orb/config"
+	"github.com/go-orb/go-orb/log"
+	"github.com/go-orb/go-orb/registry"
+	"github.com/go-orb/go-orb/registry/memory"
+	"github.com/go-orb/go-orb/types"
+)
+
+// Name is the name of this registry implementation.
+const Name = "static"
+
+// ErrReadOnly is returned by Register and Deregister when RejectRegister is set.
+var ErrReadOnly = errors.New("the static registry is read-only")
+
+var _ registry.Registry = (*Registry)(nil)
+
+func init() {
+	registry.Plugins.Add(Name, Provide)
+}
+
+// Registry serves a fixed list of nodes, it keeps them in a memory registry.
+type Registry struct {
+	config Config
+	logger log.Logger
+
+	mem *memory.Registry
+
+	mu sync.Mutex
+	// current are the currently loaded nodes by their ID.
+	current map[string]registry.ServiceNode
+
+	cancel context.CancelFunc
+}
+
+// Provide creates a new static registry.
+func Provide(
+	configData map[string]any,
+	_ *types.Components,
+	logger log.Logger,
+	opts ...registry.Option,
+) (registry.Type, error) {
+	cfg := NewConfig(opts...)
+
+	if err := config.Parse(nil, registry.DefaultConfigSection, configData, &cfg); err != nil && !errors.Is(err, config.ErrNoSuchKey) {
+		return registry.Type{}, err
+	}
+
+	return registry.Type{Registry: New(cfg, logger)}, nil
+}
+
+// New creates a new static registry without side-effects, the nodes get loaded on Start.
+func New(cfg Config, logger log.Logger) *Registry {
+	return &Registry{
+		config:  cfg,
+		logger:  logger,
+		mem:     memory.New(memory.NewConfig(memory.WithExpiryInterval(0)), logger),
+		current: make(map[string]registry.ServiceNode),
+	}
+}
+
+// Start loads the nodes and starts reloading the file if configured.
+func (r *Registry) Start(ctx context.Context) error {
+	if err := r.mem.Start(ctx); err != nil {
+		return err
+	}
+
+	if err := r.reload(ctx); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.cancel != nil || r.config.File == "" || r.config.ReloadInterval <= 0 {
+		return nil
+	}
+
+	rCtx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+
+	go r.reloadLoop(rCtx, time.Duration(r.config.ReloadInterval))
+
+	return nil
+}
+
+// Stop stops reloading and all watchers.
+func (r *Registry) Stop(ctx context.Context) error {
+	r.mu.Lock()
+	if r.cancel != nil {
+		r.cancel()
+		r.cancel = nil
+	}
+	r.mu.Unlock()
+
+	return r.mem.Stop(ctx)
+}
+
+// String returns the plugin name.
+func (r *Registry) String() string {
+	return Name
+}
+
+// Type returns the component type.
+func (r *Registry) Type() string {
+	return registry.ComponentType
+}
+
+// Register is a no-op or returns ErrReadOnly if RejectRegister is set.
+func (r *Registry) Register(_ context.Context, _ registry.ServiceNode) error {
+	if r.config.RejectRegister {
+		return ErrReadOnly
+	}
+
+	return nil
+}
+
+// Deregister is a no-op or returns ErrReadOnly if RejectRegister is set.
+func (r *Registry) Deregister(_ context.Context, _ registry.ServiceNode) error {
+	if r.config.RejectRegister {
+		return ErrReadOnly
+	}
+
+	return nil
+}
+
+// GetService returns the nodes of a service.
+func (r *Registry) GetService(
+	ctx context.Context,
+	namespace, region, name string,
+	schemes []string,
+) ([]registry.ServiceNode, error) {
+	return r.mem.GetService(ctx, namespace, region, name, schemes)
+}
+
+// ListServices returns all nodes in namespace and region.
+func (r *Registry) ListServices(
+	ctx context.Context,
+	namespace, region string,
+	schemes []string,
+) ([]registry.ServiceNode, error) {
+	return r.mem.ListServices(ctx, namespace, region, schemes)
+}
+
+// Watch returns a Watcher which receives the changes of reloads.
+func (r *Registry) Watch(ctx context.Context, opts ...registry.WatchOption) (registry.Watcher, error) {
+	return r.mem.Watch(ctx, opts...)
+}
+
+func (r *Registry) reloadLoop(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := r.reload(ctx); err != nil {
+				r.logger.Warn("while reloading the nodes, keeping the current ones", "file", r.config.File, "error", err)
+			}
+		}
+	}
+}
+
+// reload loads the nodes and applies the differences to the memory registry,
+// which emits the results for the watchers.
+func (r *Registry) reload(ctx context.Context) error {
+	nodes, err := r.load()
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, node := range nodes {
+		if cur, ok := r.current[id]; ok && equal(cur, node) {
+			continue
+		}
+
+		if err := r.mem.Register(ctx, node); err != nil {
+			return fmt.Errorf("node %s: %w", node, err)
+		}
+
+		r.current[id] = node
+	}
+
+	for id, node := range r.current {
+		if _, ok := nodes[id]; ok {
+			continue
+		}
+
+		if err := r.mem.Deregister(ctx, node); err != nil {
+			return fmt.Errorf("node %s: %w", node, err)
+		}
+
+		delete(r.current, id)
+	}
+
+	return nil
+}
+
+// load returns the configured nodes and the nodes from the file by their ID.
+func (r *Registry) load() (map[string]registry.ServiceNode, error) {
+	nodes := append([]registry.ServiceNode{}, r.config.Nodes...)
+
+	if r.config.File != "" {
+		u, err := url.Parse(r.config.File)
+		if err != nil {
+			return nil, err
+		}
+
+		data, err := config.Read(u)
+		if err != nil {
+			return nil, err
+		}
+
+		fileNodes := []registry.ServiceNode{}
+		if err := config.ParseSlice(nil, "nodes", data, &fileNodes); err != nil && !errors.Is(err, config.ErrNoSuchKey) {
+			return nil, err
+		}
+
+		nodes = append(nodes, fileNodes...)
+	}
+
+	result := make(map[string]registry.ServiceNode, len(nodes))
+
+	for _, node := range nodes {
+		// Static nodes never expire.
+		node.TTL = 0
+		result[node.ID()] = node
+	}
+
+	return result, nil
+}
+
+// equal compares the parts of two nodes with the same ID which may differ.
+func equal(a, b registry.ServiceNode) bool {
+	return a.Network == b.Network && maps.Equal(a.Metadata, b.Metadata)
+}
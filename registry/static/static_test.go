@@ -0,0 +1,178 @@
+package static
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-orb/go-orb/codecs"
+	"github.com/go-orb/go-orb/config/source"
+	"github.com/go-orb/go-orb/log"
+	"github.com/go-orb/go-orb/registry"
+)
+
+// jsonCodec is a minimal JSON codec, config.Parse needs one.
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v any) ([]byte, error)         { return json.Marshal(v) }
+func (jsonCodec) Unmarshal(data []byte, v any) error    { return json.Unmarshal(data, v) }
+func (jsonCodec) Marshals(any) bool                     { return true }
+func (jsonCodec) Unmarshals(any) bool                   { return true }
+func (jsonCodec) NewDecoder(r io.Reader) codecs.Decoder { return json.NewDecoder(r) }
+func (jsonCodec) NewEncoder(w io.Writer) codecs.Encoder { return json.NewEncoder(w) }
+func (jsonCodec) ContentTypes() []string                { return []string{codecs.MimeJSON} }
+func (jsonCodec) Name() string                          { return "json" }
+func (jsonCodec) Exts() []string                        { return []string{".json"} }
+
+// fileSource reads JSON files.
+type fileSource struct{}
+
+func (fileSource) Schemes() []string { return []string{"file"} }
+func (fileSource) String() string    { return "file" }
+
+func (fileSource) Read(u *url.URL) (map[string]any, error) {
+	data, err := os.ReadFile(u.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	result := map[string]any{}
+
+	return result, json.Unmarshal(data, &result)
+}
+
+func init() {
+	codecs.Register("json", jsonCodec{})
+	_ = source.Plugins.Add(fileSource{}) //nolint:errcheck
+}
+
+func testLogger() log.Logger {
+	return log.Logger{Logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+}
+
+func writeNodes(t *testing.T, path string, nodes ...registry.ServiceNode) {
+	t.Helper()
+
+	data, err := json.Marshal(map[string]any{"nodes": nodes})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func testNode(name, address string) registry.ServiceNode {
+	return registry.ServiceNode{Name: name, Version: "v1", Node: "grpc", Scheme: "grpc", Address: address}
+}
+
+func TestProvide(t *testing.T) {
+	ctx := context.Background()
+
+	reg, err := Provide(map[string]any{
+		registry.DefaultConfigSection: map[string]any{
+			"plugin":         Name,
+			"rejectRegister": true,
+			"nodes": []any{
+				map[string]any{"name": "svc", "node": "grpc", "scheme": "grpc", "address": "127.0.0.1:1"},
+				map[string]any{"name": "svc", "node": "http", "scheme": "http", "address": "127.0.0.1:2"},
+			},
+		},
+	}, nil, testLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := reg.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	defer reg.Stop(ctx) //nolint:errcheck
+
+	nodes, err := reg.GetService(ctx, "", "", "svc", []string{"grpc"})
+	if err != nil || len(nodes) != 1 || nodes[0].Address != "127.0.0.1:1" {
+		t.Fatalf("expected the configured grpc node, got %v: %v", nodes, err)
+	}
+
+	if err := reg.Register(ctx, testNode("svc", "127.0.0.1:3")); !errors.Is(err, ErrReadOnly) {
+		t.Fatalf("expected ErrReadOnly, got %v", err)
+	}
+}
+
+func TestReload(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "nodes.json")
+
+	writeNodes(t, path, testNode("svc", "127.0.0.1:1"), testNode("svc", "127.0.0.1:2"))
+
+	reg := New(NewConfig(
+		WithNodes(testNode("static", "127.0.0.1:9")),
+		WithFile("file://"+path),
+		WithReloadInterval(10*time.Millisecond),
+	), testLogger())
+
+	if err := reg.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	defer reg.Stop(ctx) //nolint:errcheck
+
+	nodes, err := reg.ListServices(ctx, "", "", nil)
+	if err != nil || len(nodes) != 3 {
+		t.Fatalf("expected the configured node and 2 nodes from the file, got %d: %v", len(nodes), err)
+	}
+
+	watcher, err := reg.Watch(ctx, registry.WatchService("svc"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer watcher.Stop() //nolint:errcheck
+
+	// Replace one node and change the metadata of the other.
+	changed := testNode("svc", "127.0.0.1:1")
+	changed.Metadata = map[string]string{"weight": "2"}
+	writeNodes(t, path, changed, testNode("svc", "127.0.0.1:3"))
+
+	actions := map[string]registry.EventType{}
+
+	for range 3 {
+		result, err := watcher.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		actions[result.Node.Address] = result.Action
+	}
+
+	expected := map[string]registry.EventType{
+		"127.0.0.1:1": registry.Update,
+		"127.0.0.1:2": registry.Delete,
+		"127.0.0.1:3": registry.Create,
+	}
+
+	for address, action := range expected {
+		if actions[address] != action {
+			t.Fatalf("expected %s for %s, got %v", action, address, actions)
+		}
+	}
+
+	// A broken file keeps the current nodes.
+	if err := os.WriteFile(path, []byte("{"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(50 * time.Millisecond)
+
+	if nodes, err := reg.GetService(ctx, "", "", "svc", nil); err != nil || len(nodes) != 2 {
+		t.Fatalf("expected the nodes to be kept, got %d: %v", len(nodes), err)
+	}
+}
@@ -1,12 +1,18 @@
 package registry
 
-import "time"
+import (
+	"context"
+	"time"
+)
 
 // Watcher is an interface that returns updates
 // about services within the registry.
 type Watcher interface {
-	// Next is a blocking call
+	// Next is a blocking call, it returns ErrWatcherStopped once the watcher has been stopped.
 	Next() (*Result, error)
+
+	// Stop stops the watcher and unblocks Next, it's safe to call it multiple times.
+	Stop() error
 }
 
 // EventType defines registry event type.
@@ -53,3 +59,77 @@ type Event struct {
 	// Service is registry service
 	Service ServiceNode
 }
+
+// FilteredWatcher is a Watcher which only returns results matching its WatchOptions.
+//
+// It's meant for plugins which can't push the filters down to their backend.
+type FilteredWatcher struct {
+	watcher Watcher
+	options WatchOptions
+}
+
+// NewFilteredWatcher wraps watcher and skips all results not matching opts.
+func NewFilteredWatcher(watcher Watcher, opts ...WatchOption) *FilteredWatcher {
+	return &FilteredWatcher{
+		watcher: watcher,
+		options: NewWatchOptions(opts...),
+	}
+}
+
+// Next blocks until a matching result arrives.
+func (w *FilteredWatcher) Next() (*Result, error) {
+	for {
+		result, err := w.watcher.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		if w.options.Match(result.Node) {
+			return result, nil
+		}
+	}
+}
+
+// Stop stops the wrapped watcher.
+func (w *FilteredWatcher) Stop() error {
+	return w.watcher.Stop()
+}
+
+// WatchChan turns watcher into a channel of results.
+//
+// The channel gets closed when ctx is done or the watcher returns an error,
+// the watcher will be stopped in both cases.
+func WatchChan(ctx context.Context, watcher Watcher) <-chan Result {
+	results := make(chan Result)
+	done := make(chan struct{})
+
+	// Unblock Next when ctx is done.
+	go func() {
+		select {
+		case <-ctx.Done():
+			_ = watcher.Stop() //nolint:errcheck
+		case <-done:
+		}
+	}()
+
+	go func() {
+		defer close(results)
+		defer close(done)
+		defer watcher.Stop() //nolint:errcheck
+
+		for {
+			result, err := watcher.Next()
+			if err != nil {
+				return
+			}
+
+			select {
+			case results <- *result:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return results
+}
@@ -0,0 +1,126 @@
+package registry_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-orb/go-orb/registry"
+)
+
+// chanWatcher returns the results sent to it, it returns registry.ErrWatcherStopped once stopped.
+type chanWatcher struct {
+	results chan *registry.Result
+	stopped chan struct{}
+}
+
+func newChanWatcher() *chanWatcher {
+	return &chanWatcher{results: make(chan *registry.Result, 16), stopped: make(chan struct{})}
+}
+
+func (w *chanWatcher) Next() (*registry.Result, error) {
+	select {
+	case r := <-w.results:
+		return r, nil
+	case <-w.stopped:
+		return nil, registry.ErrWatcherStopped
+	}
+}
+
+func (w *chanWatcher) Stop() error {
+	select {
+	case <-w.stopped:
+	default:
+		close(w.stopped)
+	}
+
+	return nil
+}
+
+func TestFilteredWatcher(t *testing.T) {
+	w := newChanWatcher()
+	fw := registry.NewFilteredWatcher(w, registry.WatchRegion("eu"), registry.WatchSchemes("grpc"))
+
+	for _, node := range []registry.ServiceNode{
+		{Name: "a", Region: "us", Scheme: "grpc"},
+		{Name: "b", Region: "eu", Scheme: "http"},
+		{Name: "c", Region: "eu", Scheme: "grpc"},
+		{Name: "d", Region: "", Scheme: "grpc"},
+		{Name: "e", Region: "eu", Scheme: "grpc"},
+	} {
+		w.results <- &registry.Result{Action: registry.Create, Node: node}
+	}
+
+	for _, expected := range []string{"c", "e"} {
+		result, err := fw.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if result.Node.Name != expected {
+			t.Fatalf("expected %s, got %s", expected, result.Node.Name)
+		}
+	}
+
+	// Stop stops the wrapped watcher.
+	if err := fw.Stop(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := fw.Next(); !errors.Is(err, registry.ErrWatcherStopped) {
+		t.Fatalf("expected ErrWatcherStopped, got %v", err)
+	}
+}
+
+func TestWatchChan(t *testing.T) {
+	w := newChanWatcher()
+	ctx, cancel := context.WithCancel(context.Background())
+
+	defer cancel()
+
+	results := registry.WatchChan(ctx, w)
+
+	w.results <- &registry.Result{Action: registry.Create, Node: registry.ServiceNode{Name: "a"}}
+
+	if r := <-results; r.Node.Name != "a" || r.Action != registry.Create {
+		t.Fatalf("expected the create of a, got %+v", r)
+	}
+
+	// Canceling ctx stops the watcher and closes the channel.
+	cancel()
+
+	select {
+	case _, ok := <-results:
+		if ok {
+			t.Fatal("expected the channel to be closed")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("the channel wasn't closed after ctx was done")
+	}
+
+	select {
+	case <-w.stopped:
+	case <-time.After(time.Second):
+		t.Fatal("the watcher wasn't stopped after ctx was done")
+	}
+}
+
+func TestWatchChanStop(t *testing.T) {
+	w := newChanWatcher()
+	results := registry.WatchChan(context.Background(), w)
+
+	// A stopped watcher closes the channel.
+	if err := w.Stop(); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case _, ok := <-results:
+		if ok {
+			t.Fatal("expected the channel to be closed")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("the channel wasn't closed after the watcher stopped")
+	}
+}
@@ -5,8 +5,18 @@ package server
 var DefaultConfigSection = "server" //nolint:gochecknoglobals
 
 // MiddlewareConfig is the base config for all middlewares.
+//
+// Include and Exclude select the calls a middleware handles, they are regular
+// expressions matched against "service/endpoint" from the incoming metadata.
 type MiddlewareConfig struct {
 	Plugin string `json:"plugin,omitempty" yaml:"plugin,omitempty"`
+
+	// Include limits the middleware to the matching calls, it handles all if empty.
+	Include []string `json:"include,omitempty" yaml:"include,omitempty"`
+	// Exclude skips the middleware for the matching calls, even if they're included.
+	Exclude []string `json:"exclude,omitempty" yaml:"exclude,omitempty"`
+	// Entrypoints limits the middleware to the entrypoints with these names, it's used by all if empty.
+	Entrypoints []string `json:"entrypoints,omitempty" yaml:"entrypoints,omitempty"`
 }
 
 // EntrypointConfigType is here so we can do magic work on options.
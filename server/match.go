@@ -0,0 +1,101 @@
+package server
+
+import (
+	"context"
+	"slices"
+
+	"github.com/go-orb/go-orb/util/matcher"
+	"github.com/go-orb/go-orb/util/metadata"
+)
+
+// configuredMiddleware is a middleware from the config, with the entrypoints it's used by.
+type configuredMiddleware struct {
+	middleware  Middleware
+	entrypoints []string
+}
+
+// selectMiddleware adds mw to m with the include and exclude patterns of cfg, it
+// returns mw wrapped so it only handles the selected calls, or mw if it handles all.
+func selectMiddleware(m *matcher.Matcher[Middleware], name string, mw Middleware, cfg MiddlewareConfig) (Middleware, error) {
+	if len(cfg.Include) == 0 && len(cfg.Exclude) == 0 {
+		return mw, nil
+	}
+
+	if len(cfg.Include) == 0 {
+		m.Use(name, mw)
+	}
+
+	for _, selector := range cfg.Include {
+		if err := m.Add(selector, name, mw); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, selector := range cfg.Exclude {
+		if err := m.Exclude(selector, name); err != nil {
+			return nil, err
+		}
+	}
+
+	return &matchedMiddleware{Middleware: mw, name: name, matcher: m}, nil
+}
+
+// entrypointMiddlewares returns the middlewares used by the entrypoint epName.
+func entrypointMiddlewares(epName string, mws []configuredMiddleware) []Middleware {
+	result := make([]Middleware, 0, len(mws))
+
+	for _, mw := range mws {
+		if len(mw.entrypoints) == 0 || slices.Contains(mw.entrypoints, epName) {
+			result = append(result, mw.middleware)
+		}
+	}
+
+	return result
+}
+
+var _ StreamMiddleware = (*matchedMiddleware)(nil)
+
+// matchedMiddleware calls its middleware for the calls its matcher selects,
+// it passes the others on to the next handler.
+type matchedMiddleware struct {
+	Middleware
+
+	name    string
+	matcher *matcher.Matcher[Middleware]
+}
+
+func (m *matchedMiddleware) Call(next MiddlewareCallHandler) MiddlewareCallHandler {
+	wrapped := m.Middleware.Call(next)
+
+	return func(ctx context.Context, req any) (any, error) {
+		if !m.matches(ctx) {
+			return next(ctx, req)
+		}
+
+		return wrapped(ctx, req)
+	}
+}
+
+func (m *matchedMiddleware) Stream(next StreamHandler) StreamHandler {
+	sm, ok := m.Middleware.(StreamMiddleware)
+	if !ok {
+		return next
+	}
+
+	wrapped := sm.Stream(next)
+
+	return func(ctx context.Context, stream Stream) error {
+		if !m.matches(ctx) {
+			return next(ctx, stream)
+		}
+
+		return wrapped(ctx, stream)
+	}
+}
+
+func (m *matchedMiddleware) matches(ctx context.Context) bool {
+	service, _ := metadata.GetIncoming(ctx, metadata.Service)
+	method, _ := metadata.GetIncoming(ctx, metadata.Method)
+
+	return m.matcher.Matches(service+"/"+method, m.name)
+}
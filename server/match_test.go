@@ -0,0 +1,70 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-orb/go-orb/util/matcher"
+	"github.com/go-orb/go-orb/util/metadata"
+)
+
+// marker counts the calls it has handled.
+type marker struct {
+	Middleware
+
+	calls int
+}
+
+func (m *marker) Call(next MiddlewareCallHandler) MiddlewareCallHandler {
+	return func(ctx context.Context, req any) (any, error) {
+		m.calls++
+		return next(ctx, req)
+	}
+}
+
+func TestSelectMiddleware(t *testing.T) {
+	m := matcher.NewMatcher[Middleware](nil)
+
+	auth := &marker{}
+	admin := &marker{}
+
+	authMw, err := selectMiddleware(&m, "0", auth, MiddlewareConfig{Exclude: []string{"Health/Check$"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	adminMw, err := selectMiddleware(&m, "1", admin, MiddlewareConfig{Include: []string{"^admin/"}, Entrypoints: []string{"internal"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mws := []configuredMiddleware{{middleware: authMw}, {middleware: adminMw, entrypoints: []string{"internal"}}}
+
+	if n := len(entrypointMiddlewares("public", mws)); n != 1 {
+		t.Fatalf("expected 1 middleware for the public entrypoint, got %d", n)
+	}
+
+	handler := func(_ context.Context, req any) (any, error) { return req, nil }
+	for _, mw := range entrypointMiddlewares("internal", mws) {
+		handler = mw.Call(handler)
+	}
+
+	for _, op := range [][2]string{
+		{"svc", "Foo.Bar"},
+		{"svc", "grpc.health.v1.Health/Check"},
+		{"admin", "Users.Delete"},
+	} {
+		ctx := metadata.NewIncoming(context.Background(), metadata.Pairs(metadata.Service, op[0], metadata.Method, op[1]))
+		if _, err := handler(ctx, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if auth.calls != 2 || admin.calls != 1 {
+		t.Fatalf("expected auth to handle 2 and admin 1 call, got %d and %d", auth.calls, admin.calls)
+	}
+
+	if _, err := selectMiddleware(&m, "2", auth, MiddlewareConfig{Include: []string{"("}}); err == nil {
+		t.Fatal("expected an error for an invalid pattern")
+	}
+}
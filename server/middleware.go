@@ -8,12 +8,30 @@ import (
 	"github.com/go-orb/go-orb/util/container"
 )
 
+// MiddlewareComponentType is returned when you call SomeMiddleware.Type().
+const MiddlewareComponentType = "middleware"
+
 // MiddlewareCallHandler is the Handler for unary RPC Calls.
 type MiddlewareCallHandler func(ctx context.Context, req any) (any, error)
 
 // MiddlewareStreamHandler is the handler for streaming RPC calls.
 type MiddlewareStreamHandler func(ctx context.Context) error
 
+// StreamHandler is the handler for streaming RPC calls which gets the stream,
+// it's used by stream middlewares, see StreamMiddleware.
+//
+// ctx is the context of the stream, with the incoming metadata. Handlers must use
+// it over the context of the transport's stream, middlewares may have changed it.
+type StreamHandler func(ctx context.Context, stream Stream) error
+
+// Stream is the server side of a stream, entrypoints wrap their transport's stream with it.
+type Stream interface {
+	// Send sends a message to the client.
+	Send(msg any) error
+	// Recv receives a message from the client into msg, it returns io.EOF when the client is done sending.
+	Recv(msg any) error
+}
+
 // Middleware is an interface that must be implemented by server Middlewares.
 type Middleware interface {
 	// Start/Stop will be called many times, if you require the call once, you have to ensure it on your own.
@@ -22,6 +40,58 @@ type Middleware interface {
 	Call(next MiddlewareCallHandler) MiddlewareCallHandler
 }
 
+// StreamMiddleware is implemented by middlewares which handle streams as well.
+//
+// To see the messages of a stream, pass a wrapped stream to next, see InterceptStream.
+type StreamMiddleware interface {
+	Stream(next StreamHandler) StreamHandler
+}
+
+// ChainStream wraps handler with the middlewares which implement StreamMiddleware,
+// the first middleware is the outermost like for calls. Entrypoints call it for each stream.
+func ChainStream(handler StreamHandler, mws ...Middleware) StreamHandler {
+	for i := len(mws) - 1; i >= 0; i-- {
+		if sm, ok := mws[i].(StreamMiddleware); ok {
+			handler = sm.Stream(handler)
+		}
+	}
+
+	return handler
+}
+
+// StreamInterceptor intercepts the calls to a stream, see InterceptStream.
+// Each func gets the call to the wrapped stream as next, nil funcs call it directly.
+type StreamInterceptor struct {
+	Send func(msg any, next func(msg any) error) error
+	Recv func(msg any, next func(msg any) error) error
+}
+
+// InterceptStream wraps stream with the funcs of i.
+func InterceptStream(stream Stream, i StreamInterceptor) Stream {
+	return &interceptedStream{stream: stream, i: i}
+}
+
+type interceptedStream struct {
+	stream Stream
+	i      StreamInterceptor
+}
+
+func (s *interceptedStream) Send(msg any) error {
+	if s.i.Send == nil {
+		return s.stream.Send(msg)
+	}
+
+	return s.i.Send(msg, s.stream.Send)
+}
+
+func (s *interceptedStream) Recv(msg any) error {
+	if s.i.Recv == nil {
+		return s.stream.Recv(msg)
+	}
+
+	return s.i.Recv(msg, s.stream.Recv)
+}
+
 // MiddlewareProvider is the provider for a middleware, each Middleware must supply this to register itself.
 type MiddlewareProvider func(
 	configSection []string,
@@ -34,3 +104,17 @@ var (
 	// Middlewares is the map of Middlewares available for servers.
 	Middlewares = container.NewSafeMap[string, MiddlewareProvider]() //nolint:gochecknoglobals
 )
+
+type transportKey struct{}
+
+// ContextWithTransport returns a copy of ctx with the transport of the entrypoint which received the request.
+// Entrypoints call it before the middlewares run, so those can tell the transports apart.
+func ContextWithTransport(ctx context.Context, transport string) context.Context {
+	return context.WithValue(ctx, transportKey{}, transport)
+}
+
+// TransportFromContext returns the transport set with ContextWithTransport.
+func TransportFromContext(ctx context.Context) (string, bool) {
+	transport, ok := ctx.Value(transportKey{}).(string)
+	return transport, ok && transport != ""
+}
@@ -0,0 +1,130 @@
+// Package auth provides a server middleware which authenticates calls with the
+// configured auth providers, handlers get the caller with auth.PrincipalFromContext.
+//
+// Calls without or with invalid credentials get rejected with orberrors.ErrUnauthorized.
+package auth
+
+import (
+	"context"
+	"errors"
+
+	"github.com/go-orb/go-orb/auth"
+	"github.com/go-orb/go-orb/config"
+	"github.com/go-orb/go-orb/log"
+	"github.com/go-orb/go-orb/server"
+)
+
+// Name is the name of this middleware.
+const Name = "auth"
+
+var (
+	_ server.Middleware       = (*Middleware)(nil)
+	_ server.StreamMiddleware = (*Middleware)(nil)
+)
+
+func init() {
+	server.Middlewares.Add(Name, Provide)
+}
+
+// Config is the config of the auth middleware.
+type Config struct {
+	// Plugin is the name of this middleware.
+	Plugin string `json:"plugin,omitempty" yaml:"plugin,omitempty"`
+
+	// Providers are the configs of the auth providers, they get asked in order.
+	// The key "name" selects the provider, see auth.ProviderConfig.
+	Providers []map[string]any `json:"providers,omitempty" yaml:"providers,omitempty"`
+	// Optional lets calls without credentials through unauthenticated,
+	// invalid credentials still get rejected.
+	Optional bool `json:"optional,omitempty" yaml:"optional,omitempty"`
+}
+
+// Middleware is the server auth middleware.
+type Middleware struct {
+	config        Config
+	authenticator *auth.Authenticator
+}
+
+// Provide creates an auth middleware with the providers from the config.
+func Provide(
+	configSection []string,
+	configKey string,
+	configData map[string]any,
+	logger log.Logger,
+) (server.Middleware, error) {
+	cfg := Config{Plugin: Name}
+
+	if err := config.Parse(configSection, configKey, configData, &cfg); err != nil && !errors.Is(err, config.ErrNoSuchKey) {
+		return nil, err
+	}
+
+	a, err := auth.NewFromConfig(cfg.Optional, cfg.Providers, logger.With("middleware", Name))
+	if err != nil {
+		return nil, err
+	}
+
+	return New(cfg, a), nil
+}
+
+// ProvideWithAuthenticator returns a provider whose middlewares authenticate with a
+// instead of the providers in their config, for example one authenticator shared by all entrypoints.
+func ProvideWithAuthenticator(a *auth.Authenticator) server.MiddlewareProvider {
+	return func(configSection []string, configKey string, configData map[string]any, _ log.Logger) (server.Middleware, error) {
+		cfg := Config{Plugin: Name}
+
+		if err := config.Parse(configSection, configKey, configData, &cfg); err != nil && !errors.Is(err, config.ErrNoSuchKey) {
+			return nil, err
+		}
+
+		return New(cfg, a), nil
+	}
+}
+
+// New creates a new auth middleware.
+func New(cfg Config, authenticator *auth.Authenticator) *Middleware {
+	return &Middleware{config: cfg, authenticator: authenticator}
+}
+
+// Start is a no-op.
+func (m *Middleware) Start(_ context.Context) error {
+	return nil
+}
+
+// Stop is a no-op.
+func (m *Middleware) Stop(_ context.Context) error {
+	return nil
+}
+
+// Type returns the component type.
+func (m *Middleware) Type() string {
+	return server.MiddlewareComponentType
+}
+
+// String returns the name of this middleware.
+func (m *Middleware) String() string {
+	return Name
+}
+
+// Call authenticates the call before it reaches the handler.
+func (m *Middleware) Call(next server.MiddlewareCallHandler) server.MiddlewareCallHandler {
+	return func(ctx context.Context, req any) (any, error) {
+		ctx, err := m.authenticator.Authenticate(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		return next(ctx, req)
+	}
+}
+
+// Stream authenticates the stream once when it gets opened.
+func (m *Middleware) Stream(next server.StreamHandler) server.StreamHandler {
+	return func(ctx context.Context, stream server.Stream) error {
+		ctx, err := m.authenticator.Authenticate(ctx)
+		if err != nil {
+			return err
+		}
+
+		return next(ctx, stream)
+	}
+}
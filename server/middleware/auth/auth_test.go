@@ -0,0 +1,41 @@
+package auth
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-orb/go-orb/auth"
+	"github.com/go-orb/go-orb/auth/apikey"
+	"github.com/go-orb/go-orb/util/metadata"
+	"github.com/go-orb/go-orb/util/orberrors"
+)
+
+func TestCall(t *testing.T) {
+	p, err := apikey.New(apikey.Config{Keys: map[string]string{"key": "billing"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	handler := New(Config{Plugin: Name}, auth.New(false, p)).Call(func(ctx context.Context, _ any) (any, error) {
+		principal, ok := auth.PrincipalFromContext(ctx)
+		if !ok {
+			t.Fatal("expected a principal")
+		}
+
+		return principal.Subject, nil
+	})
+
+	ctx := metadata.NewIncoming(context.Background(), metadata.Pairs(auth.APIKeyKey, "key"))
+
+	subject, err := handler(ctx, nil)
+	if err != nil || subject != "billing" {
+		t.Fatalf("expected the subject billing, got %v: %v", subject, err)
+	}
+
+	for _, md := range []*metadata.MD{metadata.New(nil), metadata.Pairs(auth.APIKeyKey, "other")} {
+		_, err := handler(metadata.NewIncoming(context.Background(), md), nil)
+		if orberrors.From(err).Code != orberrors.ErrUnauthorized.Code {
+			t.Fatalf("expected ErrUnauthorized, got %v", err)
+		}
+	}
+}
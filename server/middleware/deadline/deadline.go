@@ -0,0 +1,199 @@
+// Package deadline provides a server middleware which restores the deadline of
+// the caller from the incoming metadata key metadata.Timeout, minus a safety margin.
+//
+// This way handlers stop working once their caller has given up, and the deadline
+// cascades to the requests they make with the "deadline" client middleware.
+package deadline
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/go-orb/go-orb/config"
+	"github.com/go-orb/go-orb/log"
+	"github.com/go-orb/go-orb/server"
+	"github.com/go-orb/go-orb/util/metadata"
+	"github.com/go-orb/go-orb/util/orberrors"
+)
+
+// Name is the name of this middleware.
+const Name = "deadline"
+
+//nolint:gochecknoglobals
+var (
+	// DefaultMargin is subtracted from the callers timeout, to leave time for the response to travel back.
+	DefaultMargin = config.Duration(5 * time.Millisecond)
+)
+
+// maxTimeoutMillis is the largest timeout in milliseconds a time.Duration can hold.
+const maxTimeoutMillis = int64(math.MaxInt64 / time.Millisecond)
+
+var (
+	_ server.Middleware       = (*Middleware)(nil)
+	_ server.StreamMiddleware = (*Middleware)(nil)
+)
+
+func init() {
+	server.Middlewares.Add(Name, Provide)
+}
+
+// Config is the config of the deadline middleware.
+type Config struct {
+	// Plugin is the name of this middleware.
+	Plugin string `json:"plugin,omitempty" yaml:"plugin,omitempty"`
+
+	// Margin is subtracted from the callers timeout, to leave time for the response to travel back.
+	Margin config.Duration `json:"margin,omitempty" yaml:"margin,omitempty"`
+	// MaxTimeout caps the timeout a caller can set, 0 disables it.
+	MaxTimeout config.Duration `json:"maxTimeout,omitempty" yaml:"maxTimeout,omitempty"`
+}
+
+// Option is a functional option for the deadline middleware.
+type Option func(*Config)
+
+// WithMargin sets the safety margin.
+func WithMargin(n time.Duration) Option {
+	return func(c *Config) {
+		c.Margin = config.Duration(n)
+	}
+}
+
+// WithMaxTimeout caps the timeout a caller can set.
+func WithMaxTimeout(n time.Duration) Option {
+	return func(c *Config) {
+		c.MaxTimeout = config.Duration(n)
+	}
+}
+
+// NewConfig creates a new config with the defaults and applies opts on top.
+func NewConfig(opts ...Option) Config {
+	cfg := Config{
+		Plugin: Name,
+		Margin: DefaultMargin,
+	}
+
+	for _, o := range opts {
+		o(&cfg)
+	}
+
+	return cfg
+}
+
+// Middleware is the server deadline middleware.
+type Middleware struct {
+	config Config
+	logger log.Logger
+}
+
+// Provide creates a new deadline middleware from the config.
+func Provide(
+	configSection []string,
+	configKey string,
+	configData map[string]any,
+	logger log.Logger,
+) (server.Middleware, error) {
+	cfg := NewConfig()
+
+	if err := config.Parse(configSection, configKey, configData, &cfg); err != nil && !errors.Is(err, config.ErrNoSuchKey) {
+		return nil, err
+	}
+
+	return New(cfg, logger), nil
+}
+
+// New creates a new deadline middleware.
+func New(cfg Config, logger log.Logger) *Middleware {
+	return &Middleware{
+		config: cfg,
+		logger: logger.With("middleware", Name),
+	}
+}
+
+// Start is a no-op.
+func (m *Middleware) Start(_ context.Context) error {
+	return nil
+}
+
+// Stop is a no-op.
+func (m *Middleware) Stop(_ context.Context) error {
+	return nil
+}
+
+// Type returns the component type.
+func (m *Middleware) Type() string {
+	return server.MiddlewareComponentType
+}
+
+// String returns the name of this middleware.
+func (m *Middleware) String() string {
+	return Name
+}
+
+// Call sets the deadline of the caller on the handlers context, it rejects
+// requests whose caller has already given up.
+func (m *Middleware) Call(next server.MiddlewareCallHandler) server.MiddlewareCallHandler {
+	return func(ctx context.Context, req any) (any, error) {
+		timeout, ok := m.timeout(ctx)
+		if !ok {
+			return next(ctx, req)
+		}
+
+		if timeout <= 0 {
+			return nil, orberrors.ErrRequestTimeout.WrapNew("the caller's deadline has been exceeded")
+		}
+
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+
+		return next(ctx, req)
+	}
+}
+
+// Stream sets the deadline of the caller on the handlers context, it rejects
+// streams whose caller has already given up.
+func (m *Middleware) Stream(next server.StreamHandler) server.StreamHandler {
+	return func(ctx context.Context, stream server.Stream) error {
+		timeout, ok := m.timeout(ctx)
+		if !ok {
+			return next(ctx, stream)
+		}
+
+		if timeout <= 0 {
+			return orberrors.ErrRequestTimeout.WrapNew("the caller's deadline has been exceeded")
+		}
+
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+
+		return next(ctx, stream)
+	}
+}
+
+// timeout returns the timeout of the caller minus the margin, false if there's none.
+func (m *Middleware) timeout(ctx context.Context) (time.Duration, bool) {
+	value, ok := metadata.GetIncoming(ctx, metadata.Timeout)
+	if !ok {
+		return 0, false
+	}
+
+	ms, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		m.logger.Debug("ignoring an invalid timeout", "timeout", value, "error", err)
+		return 0, false
+	}
+
+	// Bound ms before converting it, larger values overflow time.Duration,
+	// negative ones underflow with the margin subtracted.
+	ms = min(max(ms, 0), maxTimeoutMillis)
+
+	timeout := time.Duration(ms)*time.Millisecond - time.Duration(m.config.Margin)
+
+	if m.config.MaxTimeout > 0 && timeout > time.Duration(m.config.MaxTimeout) {
+		timeout = time.Duration(m.config.MaxTimeout)
+	}
+
+	return timeout, true
+}
@@ -0,0 +1,130 @@
+package deadline
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/go-orb/go-orb/client"
+	cdeadline "github.com/go-orb/go-orb/client/middleware/deadline"
+	"github.com/go-orb/go-orb/log"
+	"github.com/go-orb/go-orb/util/metadata"
+	"github.com/go-orb/go-orb/util/orberrors"
+)
+
+// roundTrip sends a request from ctx through the client and server middlewares,
+// it returns the deadline of the handler.
+func roundTrip(ctx context.Context, opts *client.CallOptions) (time.Time, bool, error) {
+	server := New(NewConfig(WithMargin(0)), log.Logger{Logger: slog.New(slog.NewTextHandler(io.Discard, nil))})
+
+	var (
+		deadline time.Time
+		ok       bool
+	)
+
+	handler := server.Call(func(ctx context.Context, _ any) (any, error) {
+		deadline, ok = ctx.Deadline()
+		return nil, nil //nolint:nilnil
+	})
+
+	request := cdeadline.New().Request(func(ctx context.Context, _, _ string, _, _ any, _ *client.CallOptions) error {
+		// The transport sends the outgoing metadata, the server receives it as incoming metadata.
+		md, _ := metadata.OutgoingMD(ctx)
+		if md == nil {
+			md = metadata.New(nil)
+		}
+
+		_, err := handler(metadata.NewIncoming(context.Background(), md.Clone()), nil)
+
+		return err
+	})
+
+	err := request(ctx, "svc", "ep", nil, nil, opts)
+
+	return deadline, ok, err
+}
+
+func TestRoundTrip(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	expected, _ := ctx.Deadline()
+
+	deadline, ok, err := roundTrip(ctx, &client.CallOptions{})
+	if err != nil || !ok {
+		t.Fatalf("expected a deadline, got %t: %v", ok, err)
+	}
+
+	if d := expected.Sub(deadline).Abs(); d > 50*time.Millisecond {
+		t.Fatalf("expected the deadline of the caller, got a difference of %s", d)
+	}
+
+	// The RequestTimeout applies if it's shorter than the deadline of ctx.
+	deadline, ok, err = roundTrip(ctx, &client.CallOptions{RequestTimeout: time.Second})
+	if err != nil || !ok || time.Until(deadline) > time.Second {
+		t.Fatalf("expected the request timeout as deadline, got %s, %t: %v", time.Until(deadline), ok, err)
+	}
+
+	// Without a deadline nothing gets sent.
+	if _, ok, err := roundTrip(context.Background(), &client.CallOptions{}); err != nil || ok {
+		t.Fatalf("expected no deadline, got %t: %v", ok, err)
+	}
+
+	// An expired deadline fails on the client.
+	expired, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+
+	if _, _, err := roundTrip(expired, &client.CallOptions{}); !errors.Is(err, orberrors.ErrRequestTimeout) {
+		t.Fatalf("expected ErrRequestTimeout, got %v", err)
+	}
+}
+
+func TestIncomingTimeout(t *testing.T) {
+	m := New(NewConfig(WithMargin(10*time.Millisecond), WithMaxTimeout(time.Minute)), log.Logger{Logger: slog.New(slog.NewTextHandler(io.Discard, nil))})
+
+	for _, tc := range []struct {
+		value    string
+		deadline bool
+		err      error
+	}{
+		{"1000", true, nil},
+		{"5", false, orberrors.ErrRequestTimeout},
+		{"-1", false, orberrors.ErrRequestTimeout},
+		{"soon", false, nil},
+		{"", false, nil},
+		{"3600000", true, nil},
+		// Values which overflow time.Duration are capped as well.
+		{"9223372036854775807", true, nil},
+		{"-9223372036854775808", false, orberrors.ErrRequestTimeout},
+	} {
+		md := metadata.New(nil)
+		if tc.value != "" {
+			md.Set(metadata.Timeout, tc.value)
+		}
+
+		var (
+			deadline time.Time
+			ok       bool
+		)
+
+		_, err := m.Call(func(ctx context.Context, _ any) (any, error) {
+			deadline, ok = ctx.Deadline()
+			return nil, nil //nolint:nilnil
+		})(metadata.NewIncoming(context.Background(), md), nil)
+
+		if tc.err != nil && !errors.Is(err, tc.err) || tc.err == nil && err != nil {
+			t.Fatalf("%q: expected %v, got %v", tc.value, tc.err, err)
+		}
+
+		if ok != tc.deadline {
+			t.Fatalf("%q: expected deadline %t, got %t", tc.value, tc.deadline, ok)
+		}
+
+		if ok && time.Until(deadline) > time.Minute {
+			t.Fatalf("%q: expected the timeout to be capped, got %s", tc.value, time.Until(deadline))
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package limiter
+
+import (
+	"time"
+
+	"github.com/go-orb/go-orb/config"
+)
+
+// Priority classes, they get read from the metadata key PriorityMetadataKey.
+const (
+	// PriorityCritical requests may use the reserve above the limits.
+	PriorityCritical = "critical"
+	// PriorityDefault is used for requests without or with an unknown priority.
+	PriorityDefault = "default"
+	// PrioritySheddable requests get shed first, before the limits have been reached.
+	PrioritySheddable = "sheddable"
+)
+
+//nolint:gochecknoglobals
+var (
+	// DefaultCallerMetadataKey is the incoming metadata key with the identity of the caller.
+	DefaultCallerMetadataKey = "caller"
+	// DefaultPriorityMetadataKey is the incoming metadata key with the priority class of a request.
+	DefaultPriorityMetadataKey = "priority"
+	// DefaultRetryAfterMetadataKey is the response metadata key with the seconds after which to retry.
+	DefaultRetryAfterMetadataKey = "retry-after"
+
+	// DefaultRetryAfter is the retry-after hint when the concurrency limit has been reached.
+	DefaultRetryAfter = config.Duration(time.Second)
+	// DefaultCriticalReserve is the fraction of the limits critical requests may use on top.
+	DefaultCriticalReserve = 0.1
+	// DefaultSheddableRatio is the fraction of the limits sheddable requests may use.
+	DefaultSheddableRatio = 0.8
+	// DefaultMaxCallers is the maximum number of tracked callers.
+	DefaultMaxCallers = 10000
+)
+
+// Config is the config of the server limiter middleware.
+type Config struct {
+	// Plugin is the name of this middleware.
+	Plugin string `json:"plugin,omitempty" yaml:"plugin,omitempty"`
+
+	// Rate is the number of requests per second per endpoint, 0 disables it.
+	Rate float64 `json:"rate,omitempty" yaml:"rate,omitempty"`
+	// Burst is the number of requests per endpoint which may arrive at once, defaults to 1.
+	Burst int `json:"burst,omitempty" yaml:"burst,omitempty"`
+	// MaxConcurrent is the maximum number of concurrent calls per endpoint, 0 disables it.
+	MaxConcurrent int `json:"maxConcurrent,omitempty" yaml:"maxConcurrent,omitempty"`
+
+	// CallerRate is the number of requests per second per caller, 0 disables it.
+	CallerRate float64 `json:"callerRate,omitempty" yaml:"callerRate,omitempty"`
+	// CallerBurst is the number of requests per caller which may arrive at once, defaults to 1.
+	CallerBurst int `json:"callerBurst,omitempty" yaml:"callerBurst,omitempty"`
+	// CallerMaxConcurrent is the maximum number of concurrent calls per caller, 0 disables it.
+	CallerMaxConcurrent int `json:"callerMaxConcurrent,omitempty" yaml:"callerMaxConcurrent,omitempty"`
+	// MaxCallers is the maximum number of tracked callers, the least recently seen one gets dropped
+	// for a new one. 0 tracks all callers.
+	MaxCallers int `json:"maxCallers,omitempty" yaml:"maxCallers,omitempty"`
+
+	// CallerMetadataKey is the incoming metadata key with the identity of the caller.
+	CallerMetadataKey string `json:"callerMetadataKey,omitempty" yaml:"callerMetadataKey,omitempty"`
+	// PriorityMetadataKey is the incoming metadata key with the priority class of a request.
+	PriorityMetadataKey string `json:"priorityMetadataKey,omitempty" yaml:"priorityMetadataKey,omitempty"`
+
+	// CriticalReserve is the fraction of the limits critical requests may use on top.
+	CriticalReserve float64 `json:"criticalReserve,omitempty" yaml:"criticalReserve,omitempty"`
+	// SheddableRatio is the fraction of the limits sheddable requests may use.
+	SheddableRatio float64 `json:"sheddableRatio,omitempty" yaml:"sheddableRatio,omitempty"`
+
+	// RetryAfter is the retry-after hint when the concurrency limit has been reached.
+	RetryAfter config.Duration `json:"retryAfter,omitempty" yaml:"retryAfter,omitempty"`
+}
+
+// Option is a functional option for the server limiter middleware.
+type Option func(*Config)
+
+// WithRate sets the rate limit per endpoint in requests per second and the burst.
+func WithRate(rate float64, burst int) Option {
+	return func(c *Config) {
+		c.Rate = rate
+		c.Burst = burst
+	}
+}
+
+// WithMaxConcurrent sets the maximum number of concurrent calls per endpoint.
+func WithMaxConcurrent(n int) Option {
+	return func(c *Config) {
+		c.MaxConcurrent = n
+	}
+}
+
+// WithCallerRate sets the rate limit per caller in requests per second and the burst.
+func WithCallerRate(rate float64, burst int) Option {
+	return func(c *Config) {
+		c.CallerRate = rate
+		c.CallerBurst = burst
+	}
+}
+
+// WithCallerMaxConcurrent sets the maximum number of concurrent calls per caller.
+func WithCallerMaxConcurrent(n int) Option {
+	return func(c *Config) {
+		c.CallerMaxConcurrent = n
+	}
+}
+
+// WithPriorities sets the reserve of critical requests and the ratio of the limits sheddable requests may use.
+func WithPriorities(criticalReserve, sheddableRatio float64) Option {
+	return func(c *Config) {
+		c.CriticalReserve = criticalReserve
+		c.SheddableRatio = sheddableRatio
+	}
+}
+
+// NewConfig creates a new config with the defaults and applies opts on top.
+func NewConfig(opts ...Option) Config {
+	cfg := Config{
+		Plugin:              Name,
+		Burst:               1,
+		CallerBurst:         1,
+		MaxCallers:          DefaultMaxCallers,
+		CallerMetadataKey:   DefaultCallerMetadataKey,
+		PriorityMetadataKey: DefaultPriorityMetadataKey,
+		CriticalReserve:     DefaultCriticalReserve,
+		SheddableRatio:      DefaultSheddableRatio,
+		RetryAfter:          DefaultRetryAfter,
+	}
+
+	for _, o := range opts {
+		o(&cfg)
+	}
+
+	return cfg
+}
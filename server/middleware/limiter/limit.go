@@ -0,0 +1,92 @@
+package limiter
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// limit is a token bucket and a concurrency limit of an endpoint or caller.
+//
+// Requests take a share of the limits by their priority class, a share below 1
+// leaves capacity for the others, a share above 1 uses the reserve.
+type limit struct {
+	rate          float64
+	burst         float64
+	maxConcurrent float64
+	retryAfter    time.Duration
+
+	mu       sync.Mutex
+	tokens   float64
+	updated  time.Time
+	inFlight int
+}
+
+func newLimit(rate float64, burst int, maxConcurrent int, retryAfter time.Duration) *limit {
+	return &limit{
+		rate:          rate,
+		burst:         float64(max(burst, 1)),
+		maxConcurrent: float64(maxConcurrent),
+		retryAfter:    retryAfter,
+		tokens:        float64(max(burst, 1)),
+		updated:       time.Now(),
+	}
+}
+
+// take takes a token and a concurrency slot, if one of the limits has been reached
+// it returns false and the time after which the request may be retried.
+func (l *limit) take(share float64) (time.Duration, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.maxConcurrent > 0 && float64(l.inFlight+1) > l.maxConcurrent*share {
+		return l.retryAfter, false
+	}
+
+	if l.rate > 0 {
+		l.refill(time.Now())
+
+		// The tokens which have to be left for other priority classes, whole tokens
+		// get reserved so a full bucket always admits a request of any class.
+		floor := l.burst * (1 - share)
+		if floor > 0 {
+			floor = min(math.Ceil(floor), l.burst-1)
+		}
+
+		if l.tokens-1 < floor {
+			return time.Duration((floor + 1 - l.tokens) / l.rate * float64(time.Second)), false
+		}
+
+		l.tokens--
+	}
+
+	l.inFlight++
+
+	return 0, true
+}
+
+// release frees the concurrency slot of a completed request.
+func (l *limit) release() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.inFlight--
+}
+
+// undo returns the token and the slot of a request which hasn't been processed.
+func (l *limit) undo() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.inFlight--
+
+	if l.rate > 0 {
+		l.tokens = math.Min(l.tokens+1, l.burst)
+	}
+}
+
+// refill adds the tokens since the last update, the caller must hold l.mu.
+func (l *limit) refill(now time.Time) {
+	l.tokens = math.Min(l.tokens+now.Sub(l.updated).Seconds()*l.rate, l.burst)
+	l.updated = now
+}
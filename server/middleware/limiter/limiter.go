@@ -0,0 +1,231 @@
+// Package limiter provides a server middleware which protects a server from overload.
+//
+// It limits the requests per second and the concurrent calls per endpoint and per
+// caller, the caller gets taken from the incoming metadata key CallerMetadataKey.
+// Requests above the limits get shed with orberrors.ErrUnavailable and a retry-after
+// hint in the response metadata.
+//
+// Requests are prioritized by the priority class in the incoming metadata key
+// PriorityMetadataKey: "sheddable" requests get shed before the limits have been
+// reached, "critical" requests may use a reserve above them.
+package limiter
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-orb/go-orb/config"
+	"github.com/go-orb/go-orb/log"
+	"github.com/go-orb/go-orb/server"
+	"github.com/go-orb/go-orb/util/metadata"
+	"github.com/go-orb/go-orb/util/orberrors"
+)
+
+// Name is the name of this middleware.
+const Name = "limiter"
+
+var (
+	_ server.Middleware       = (*Middleware)(nil)
+	_ server.StreamMiddleware = (*Middleware)(nil)
+)
+
+func init() {
+	server.Middlewares.Add(Name, Provide)
+}
+
+// Middleware is the server limiter middleware.
+type Middleware struct {
+	config Config
+	logger log.Logger
+
+	mu        sync.Mutex
+	endpoints map[string]*limit
+	// callers holds the elements of lru, the most recently seen caller is in front.
+	callers map[string]*list.Element
+	lru     *list.List
+}
+
+// callerLimit is an element of the callers LRU list.
+type callerLimit struct {
+	caller string
+	limit  *limit
+}
+
+// Provide creates a new server limiter middleware from the config.
+func Provide(
+	configSection []string,
+	configKey string,
+	configData map[string]any,
+	logger log.Logger,
+) (server.Middleware, error) {
+	cfg := NewConfig()
+
+	if err := config.Parse(configSection, configKey, configData, &cfg); err != nil && !errors.Is(err, config.ErrNoSuchKey) {
+		return nil, err
+	}
+
+	return New(cfg, logger), nil
+}
+
+// New creates a new server limiter middleware.
+func New(cfg Config, logger log.Logger) *Middleware {
+	return &Middleware{
+		config:    cfg,
+		logger:    logger.With("middleware", Name),
+		endpoints: make(map[string]*limit),
+		callers:   make(map[string]*list.Element),
+		lru:       list.New(),
+	}
+}
+
+// Start is a no-op.
+func (m *Middleware) Start(_ context.Context) error {
+	return nil
+}
+
+// Stop is a no-op.
+func (m *Middleware) Stop(_ context.Context) error {
+	return nil
+}
+
+// Type returns the component type.
+func (m *Middleware) Type() string {
+	return server.MiddlewareComponentType
+}
+
+// String returns the name of this middleware.
+func (m *Middleware) String() string {
+	return Name
+}
+
+// Call sheds requests above the limits of their endpoint and caller.
+func (m *Middleware) Call(next server.MiddlewareCallHandler) server.MiddlewareCallHandler {
+	return func(ctx context.Context, req any) (any, error) {
+		ctx, release, err := m.admit(ctx)
+		if err != nil {
+			return nil, err
+		}
+		defer release()
+
+		return next(ctx, req)
+	}
+}
+
+// Stream applies the limits to streams, a stream holds its concurrency slots until the handler returns.
+func (m *Middleware) Stream(next server.StreamHandler) server.StreamHandler {
+	return func(ctx context.Context, stream server.Stream) error {
+		ctx, release, err := m.admit(ctx)
+		if err != nil {
+			return err
+		}
+		defer release()
+
+		return next(ctx, stream)
+	}
+}
+
+// admit takes the endpoint and caller limits of the request in ctx, or returns the shed error.
+// It returns ctx with outgoing metadata for the retry-after hint, call release once the request is done.
+func (m *Middleware) admit(ctx context.Context) (context.Context, func(), error) {
+	ctx, out := metadata.WithOutgoingMD(ctx)
+
+	priority, _ := metadata.GetIncoming(ctx, m.config.PriorityMetadataKey) //nolint:errcheck
+	share := m.share(priority)
+
+	var endpointLimit, callerLimit *limit
+
+	if m.config.Rate > 0 || m.config.MaxConcurrent > 0 {
+		service, _ := metadata.GetIncoming(ctx, metadata.Service) //nolint:errcheck
+		method, _ := metadata.GetIncoming(ctx, metadata.Method)   //nolint:errcheck
+		endpoint := service + "/" + method
+		endpointLimit = m.endpoint(endpoint)
+
+		if retryAfter, ok := endpointLimit.take(share); !ok {
+			return ctx, nil, m.shed(out, retryAfter, "endpoint", endpoint)
+		}
+	}
+
+	caller, _ := metadata.GetIncoming(ctx, m.config.CallerMetadataKey) //nolint:errcheck
+
+	if caller != "" && (m.config.CallerRate > 0 || m.config.CallerMaxConcurrent > 0) {
+		callerLimit = m.caller(caller)
+
+		if retryAfter, ok := callerLimit.take(share); !ok {
+			if endpointLimit != nil {
+				endpointLimit.undo()
+			}
+
+			return ctx, nil, m.shed(out, retryAfter, "caller", caller)
+		}
+	}
+
+	return ctx, func() {
+		if callerLimit != nil {
+			callerLimit.release()
+		}
+
+		if endpointLimit != nil {
+			endpointLimit.release()
+		}
+	}, nil
+}
+
+// shed sets the retry-after hint in the outgoing metadata and returns the shed error, which has the hint as well.
+func (m *Middleware) shed(out *metadata.MD, retryAfter time.Duration, kind, name string) error {
+	seconds := max(1, int(math.Ceil(retryAfter.Seconds())))
+	out.Set(DefaultRetryAfterMetadataKey, strconv.Itoa(seconds))
+
+	return orberrors.ErrUnavailable.WrapF("limit of %s '%s' reached, retry after %ds", kind, name, seconds)
+}
+
+// share returns the share of the limits a request of priority class may use.
+func (m *Middleware) share(priority string) float64 {
+	switch priority {
+	case PriorityCritical:
+		return 1 + m.config.CriticalReserve
+	case PrioritySheddable:
+		return m.config.SheddableRatio
+	default:
+		return 1
+	}
+}
+
+func (m *Middleware) endpoint(endpoint string) *limit {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	l, ok := m.endpoints[endpoint]
+	if !ok {
+		l = newLimit(m.config.Rate, m.config.Burst, m.config.MaxConcurrent, time.Duration(m.config.RetryAfter))
+		m.endpoints[endpoint] = l
+	}
+
+	return l
+}
+
+// caller returns the limit of caller, it drops the least recently seen caller once MaxCallers are tracked.
+func (m *Middleware) caller(caller string) *limit {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if e, ok := m.callers[caller]; ok {
+		m.lru.MoveToFront(e)
+		return e.Value.(*callerLimit).limit //nolint:errcheck,forcetypeassert
+	}
+
+	if m.config.MaxCallers > 0 && m.lru.Len() >= m.config.MaxCallers {
+		oldest := m.lru.Back()
+		m.lru.Remove(oldest)
+		delete(m.callers, oldest.Value.(*callerLimit).caller) //nolint:errcheck,forcetypeassert
+	}
+
+	l := newLimit(m.config.CallerRate, m.config.CallerBurst, m.config.CallerMaxConcurrent, time.Duration(m.config.RetryAfter))
+	m.callers[caller] = m.lru.PushFront(&callerLimit{caller: caller, limit: l})
+
+	return l
+}
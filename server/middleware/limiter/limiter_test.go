@@ -0,0 +1,190 @@
+package limiter
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/go-orb/go-orb/log"
+	"github.com/go-orb/go-orb/util/metadata"
+	"github.com/go-orb/go-orb/util/orberrors"
+)
+
+func TestPriorities(t *testing.T) {
+	m := New(NewConfig(WithMaxConcurrent(10)), log.Logger{Logger: slog.New(slog.NewTextHandler(io.Discard, nil))})
+	l := m.endpoint("ep")
+
+	for range 8 {
+		if _, ok := l.take(m.share(PriorityDefault)); !ok {
+			t.Fatal("expected default request to pass")
+		}
+	}
+
+	if _, ok := l.take(m.share(PrioritySheddable)); ok {
+		t.Fatal("expected sheddable request to be shed")
+	}
+
+	for range 2 {
+		if _, ok := l.take(m.share(PriorityDefault)); !ok {
+			t.Fatal("expected default request to pass")
+		}
+	}
+
+	if _, ok := l.take(m.share(PriorityDefault)); ok {
+		t.Fatal("expected default request to be shed")
+	}
+
+	if _, ok := l.take(m.share(PriorityCritical)); !ok {
+		t.Fatal("expected critical request to use the reserve")
+	}
+}
+
+func TestRatePriorities(t *testing.T) {
+	logger := log.Logger{Logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+
+	for _, tc := range []struct {
+		priority string
+		burst    int
+		expected int
+	}{
+		// A single token gets taken by each class.
+		{PrioritySheddable, 1, 1},
+		{PriorityDefault, 1, 1},
+		{PriorityCritical, 1, 1},
+		// Sheddable requests leave 2 of 10 tokens, critical requests use 1 on top.
+		{PrioritySheddable, 10, 8},
+		{PriorityDefault, 10, 10},
+		{PriorityCritical, 10, 11},
+	} {
+		// The rate is low enough that no tokens get refilled during the test.
+		l := New(NewConfig(WithRate(0.001, tc.burst)), logger).endpoint("ep")
+		share := New(NewConfig(), logger).share(tc.priority)
+
+		admitted := 0
+
+		for range tc.burst + 2 {
+			if _, ok := l.take(share); ok {
+				admitted++
+			}
+		}
+
+		if admitted != tc.expected {
+			t.Fatalf("%s with burst %d: expected %d requests to pass, got %d", tc.priority, tc.burst, tc.expected, admitted)
+		}
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	m := New(NewConfig(WithCallerRate(0.5, 1)), log.Logger{Logger: slog.New(slog.NewTextHandler(io.Discard, nil))})
+	handler := m.Call(func(_ context.Context, _ any) (any, error) {
+		return nil, nil //nolint:nilnil
+	})
+
+	ctx, in := metadata.WithIncomingMD(context.Background())
+	in.Set(DefaultCallerMetadataKey, "batch")
+
+	ctx, out := metadata.WithOutgoingMD(ctx)
+
+	if _, err := handler(ctx, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := handler(ctx, nil); !errors.Is(err, orberrors.ErrUnavailable) {
+		t.Fatalf("expected the caller to be limited, got %v", err)
+	}
+
+	if v, _ := out.Value(DefaultRetryAfterMetadataKey); v != "2" {
+		t.Fatalf("expected a retry-after of 2 seconds, got %q", v)
+	}
+
+	// Other callers are not affected.
+	in.Set(DefaultCallerMetadataKey, "web")
+
+	if _, err := handler(ctx, nil); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRetryAfterWithoutOutgoing(t *testing.T) {
+	m := New(NewConfig(WithMaxConcurrent(1)), log.Logger{Logger: slog.New(slog.NewTextHandler(io.Discard, nil))})
+
+	var handlerCtx context.Context
+
+	handler := m.Call(func(ctx context.Context, _ any) (any, error) {
+		handlerCtx = ctx
+
+		// The second call arrives while the first one is running.
+		_, err := m.Call(func(context.Context, any) (any, error) {
+			return nil, nil //nolint:nilnil
+		})(context.Background(), nil)
+
+		return nil, err
+	})
+
+	_, err := handler(context.Background(), nil)
+	if !errors.Is(err, orberrors.ErrUnavailable) || !strings.Contains(err.Error(), "retry after 1s") {
+		t.Fatalf("expected the shed error with the retry-after hint, got %v", err)
+	}
+
+	// The handler gets outgoing metadata to set response metadata in.
+	if _, ok := metadata.OutgoingMD(handlerCtx); !ok {
+		t.Fatal("expected outgoing metadata in the handler")
+	}
+}
+
+func TestEndpointKey(t *testing.T) {
+	m := New(NewConfig(WithMaxConcurrent(1)), log.Logger{Logger: slog.New(slog.NewTextHandler(io.Discard, nil))})
+
+	request := func(service string) context.Context {
+		return metadata.NewIncoming(context.Background(), metadata.Pairs(metadata.Service, service, metadata.Method, "Get"))
+	}
+
+	_, release, err := m.admit(request("users"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer release()
+
+	// The same method of another service has its own limit.
+	if _, _, err := m.admit(request("orders")); err != nil {
+		t.Fatalf("expected another service to pass, got %v", err)
+	}
+
+	if _, _, err := m.admit(request("users")); !errors.Is(err, orberrors.ErrUnavailable) {
+		t.Fatalf("expected the endpoint to be limited, got %v", err)
+	}
+}
+
+func TestMaxCallers(t *testing.T) {
+	cfg := NewConfig(WithCallerRate(0.001, 1))
+	cfg.MaxCallers = 2
+
+	m := New(cfg, log.Logger{Logger: slog.New(slog.NewTextHandler(io.Discard, nil))})
+
+	// Callers which spent their token are not idle, they get dropped nonetheless.
+	for i := range 100 {
+		if _, ok := m.caller(strconv.Itoa(i)).take(1); !ok {
+			t.Fatal("expected a new caller to pass")
+		}
+
+		if len(m.callers) > 2 || m.lru.Len() > 2 {
+			t.Fatalf("expected at most 2 callers, got %d", len(m.callers))
+		}
+	}
+
+	// The most recently seen callers are kept.
+	m.caller("98")
+	m.caller("100")
+
+	if _, ok := m.callers["98"]; !ok {
+		t.Fatal("expected the recently seen caller to be kept")
+	}
+
+	if _, ok := m.callers["99"]; ok {
+		t.Fatal("expected the least recently seen caller to be dropped")
+	}
+}
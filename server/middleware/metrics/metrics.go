@@ -0,0 +1,187 @@
+// Package metrics provides a server middleware which records the rate, errors and
+// duration (RED) of calls:
+//
+//   - server.requests, a counter of all calls.
+//   - server.errors, a counter of failed calls.
+//   - server.latency, the duration of all calls.
+//
+// Streams get recorded to server.streams.requests, server.streams.errors and
+// server.streams.duration.
+//
+// All of them are labeled with service, endpoint, transport and status, which is
+// "ok" or the orberrors code of the error. Bound the cardinality with the
+// allowedLabels and blockedLabels of the metrics config, e.g. block "endpoint".
+//
+// The middleware needs the metrics component of the service, register a provider
+// with it before the server gets created:
+//
+//	server.Middlewares.Set(metrics.Name, metrics.ProvideWithMetrics(m))
+package metrics
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/go-orb/go-orb/config"
+	"github.com/go-orb/go-orb/log"
+	"github.com/go-orb/go-orb/metrics"
+	"github.com/go-orb/go-orb/server"
+	"github.com/go-orb/go-orb/util/metadata"
+	"github.com/go-orb/go-orb/util/orberrors"
+)
+
+// Name is the name of this middleware.
+const Name = "metrics"
+
+// Label values.
+const (
+	StatusOK         = "ok"
+	TransportUnknown = "unknown"
+)
+
+// ErrNoMetrics is returned by Provide, the middleware needs a metrics component.
+var ErrNoMetrics = errors.New("the metrics middleware needs a metrics component, register it with metrics.ProvideWithMetrics")
+
+// keys are the metric names of calls or streams.
+type keys struct {
+	requests []string
+	errors   []string
+	duration []string
+}
+
+//nolint:gochecknoglobals
+var (
+	requestKeys = keys{
+		requests: []string{"server", "requests"},
+		errors:   []string{"server", "errors"},
+		duration: []string{"server", "latency"},
+	}
+	streamKeys = keys{
+		requests: []string{"server", "streams", "requests"},
+		errors:   []string{"server", "streams", "errors"},
+		duration: []string{"server", "streams", "duration"},
+	}
+)
+
+var (
+	_ server.Middleware       = (*Middleware)(nil)
+	_ server.StreamMiddleware = (*Middleware)(nil)
+)
+
+func init() {
+	server.Middlewares.Add(Name, Provide)
+}
+
+// Config is the config of the metrics middleware.
+type Config struct {
+	// Plugin is the name of this middleware.
+	Plugin string `json:"plugin,omitempty" yaml:"plugin,omitempty"`
+}
+
+// Middleware is the server metrics middleware.
+type Middleware struct {
+	config  Config
+	metrics metrics.Metrics
+}
+
+// Provide returns ErrNoMetrics, replace it with ProvideWithMetrics.
+func Provide(_ []string, _ string, _ map[string]any, _ log.Logger) (server.Middleware, error) {
+	return nil, ErrNoMetrics
+}
+
+// ProvideWithMetrics returns a provider whose middlewares report server calls to m,
+// usually the metrics component of the service.
+func ProvideWithMetrics(m metrics.Metrics) server.MiddlewareProvider {
+	return func(configSection []string, configKey string, configData map[string]any, _ log.Logger) (server.Middleware, error) {
+		cfg := Config{Plugin: Name}
+
+		if err := config.Parse(configSection, configKey, configData, &cfg); err != nil && !errors.Is(err, config.ErrNoSuchKey) {
+			return nil, err
+		}
+
+		return New(cfg, m), nil
+	}
+}
+
+// New creates a new metrics middleware.
+func New(cfg Config, m metrics.Metrics) *Middleware {
+	return &Middleware{config: cfg, metrics: m}
+}
+
+// Start is a no-op.
+func (m *Middleware) Start(_ context.Context) error {
+	return nil
+}
+
+// Stop is a no-op.
+func (m *Middleware) Stop(_ context.Context) error {
+	return nil
+}
+
+// Type returns the component type.
+func (m *Middleware) Type() string {
+	return server.MiddlewareComponentType
+}
+
+// String returns the name of this middleware.
+func (m *Middleware) String() string {
+	return Name
+}
+
+// Call records the call.
+func (m *Middleware) Call(next server.MiddlewareCallHandler) server.MiddlewareCallHandler {
+	return func(ctx context.Context, req any) (any, error) {
+		start := time.Now()
+
+		result, err := next(ctx, req)
+
+		m.record(ctx, requestKeys, start, err)
+
+		return result, err
+	}
+}
+
+// Stream records the stream.
+func (m *Middleware) Stream(next server.StreamHandler) server.StreamHandler {
+	return func(ctx context.Context, stream server.Stream) error {
+		start := time.Now()
+
+		err := next(ctx, stream)
+
+		m.record(ctx, streamKeys, start, err)
+
+		return err
+	}
+}
+
+func (m *Middleware) record(ctx context.Context, k keys, start time.Time, err error) {
+	service, _ := metadata.GetIncoming(ctx, metadata.Service)
+	endpoint, _ := metadata.GetIncoming(ctx, metadata.Method)
+
+	transport, ok := server.TransportFromContext(ctx)
+	if !ok {
+		transport = TransportUnknown
+	}
+
+	status := StatusOK
+	if err != nil {
+		status = strconv.Itoa(orberrors.From(err).Code)
+	}
+
+	labels := []metrics.Label{
+		{Name: "service", Value: service},
+		{Name: "endpoint", Value: endpoint},
+		{Name: "transport", Value: transport},
+		{Name: "status", Value: status},
+	}
+
+	m.metrics.IncrCounterWithLabels(k.requests, 1, labels)
+
+	if err != nil {
+		m.metrics.IncrCounterWithLabels(k.errors, 1, labels)
+	}
+
+	m.metrics.MeasureSinceWithLabels(k.duration, start, labels)
+}
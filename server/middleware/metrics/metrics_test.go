@@ -0,0 +1,140 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-orb/go-orb/metrics"
+	"github.com/go-orb/go-orb/server"
+	"github.com/go-orb/go-orb/util/metadata"
+	"github.com/go-orb/go-orb/util/orberrors"
+)
+
+// recorder implements the metrics methods used by the middleware.
+type recorder struct {
+	metrics.Metrics
+
+	mu       sync.Mutex
+	counters map[string]float32
+	samples  map[string]int
+}
+
+func key(name []string, labels []metrics.Label) string {
+	parts := []string{strings.Join(name, ".")}
+	for _, l := range labels {
+		parts = append(parts, l.Name+"="+l.Value)
+	}
+
+	return strings.Join(parts, ",")
+}
+
+func (r *recorder) IncrCounterWithLabels(name []string, val float32, labels []metrics.Label) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.counters[key(name, labels)] += val
+}
+
+func (r *recorder) MeasureSinceWithLabels(name []string, _ time.Time, labels []metrics.Label) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.samples[key(name, labels)]++
+}
+
+func TestMetrics(t *testing.T) {
+	r := &recorder{counters: make(map[string]float32), samples: make(map[string]int)}
+	mw := New(Config{Plugin: Name}, r)
+
+	handler := mw.Call(func(_ context.Context, req any) (any, error) {
+		if req == "fail" {
+			return nil, orberrors.ErrNotFound
+		}
+
+		return req, nil
+	})
+
+	ctx := metadata.NewIncoming(context.Background(), metadata.Pairs(metadata.Service, "svc", metadata.Method, "Foo.Bar"))
+	ctx = server.ContextWithTransport(ctx, "grpc")
+
+	for _, req := range []string{"ok", "ok", "fail"} {
+		_, _ = handler(ctx, req) //nolint:errcheck
+	}
+
+	labels := "service=svc,endpoint=Foo.Bar,transport=grpc,status="
+
+	expected := map[string]float32{
+		"server.requests," + labels + "ok":  2,
+		"server.requests," + labels + "404": 1,
+		"server.errors," + labels + "404":   1,
+	}
+
+	if len(r.counters) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, r.counters)
+	}
+
+	for k, v := range expected {
+		if r.counters[k] != v {
+			t.Fatalf("expected %s=%v, got %v", k, v, r.counters)
+		}
+	}
+
+	if r.samples["server.latency,"+labels+"ok"] != 2 || r.samples["server.latency,"+labels+"404"] != 1 {
+		t.Fatalf("unexpected latency samples %v", r.samples)
+	}
+}
+
+type stream struct{}
+
+func (stream) Send(_ any) error { return nil }
+func (stream) Recv(_ any) error { return io.EOF }
+
+func TestStream(t *testing.T) {
+	r := &recorder{counters: make(map[string]float32), samples: make(map[string]int)}
+
+	received := 0
+	handler := server.ChainStream(func(_ context.Context, s server.Stream) error {
+		if err := s.Recv(nil); !errors.Is(err, io.EOF) {
+			return err
+		}
+
+		return nil
+	}, New(Config{Plugin: Name}, r), &counter{received: &received})
+
+	ctx := metadata.NewIncoming(context.Background(), metadata.Pairs(metadata.Service, "svc", metadata.Method, "Foo.Stream"))
+
+	if err := handler(ctx, stream{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if received != 1 {
+		t.Fatalf("expected the interceptor to see 1 Recv, got %d", received)
+	}
+
+	if r.counters["server.streams.requests,service=svc,endpoint=Foo.Stream,transport=unknown,status=ok"] != 1 {
+		t.Fatalf("expected the stream to be recorded, got %v", r.counters)
+	}
+}
+
+// counter is a stream middleware which counts the calls to Recv.
+type counter struct {
+	server.Middleware
+
+	received *int
+}
+
+func (c *counter) Stream(next server.StreamHandler) server.StreamHandler {
+	return func(ctx context.Context, s server.Stream) error {
+		return next(ctx, server.InterceptStream(s, server.StreamInterceptor{
+			Recv: func(msg any, next func(any) error) error {
+				*c.received++
+				return next(msg)
+			},
+		}))
+	}
+}
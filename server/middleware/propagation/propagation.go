@@ -0,0 +1,94 @@
+// Package propagation provides a server middleware which takes the incoming metadata
+// selected by its config, the "propagation" client middleware forwards it to the
+// requests the handler makes. See metadata.Propagation.
+package propagation
+
+import (
+	"context"
+	"errors"
+
+	"github.com/go-orb/go-orb/config"
+	"github.com/go-orb/go-orb/log"
+	"github.com/go-orb/go-orb/server"
+	"github.com/go-orb/go-orb/util/metadata"
+)
+
+// Name is the name of this middleware.
+const Name = "propagation"
+
+var (
+	_ server.Middleware       = (*Middleware)(nil)
+	_ server.StreamMiddleware = (*Middleware)(nil)
+)
+
+func init() {
+	server.Middlewares.Add(Name, Provide)
+}
+
+// Config is the config of the propagation middleware.
+type Config struct {
+	// Plugin is the name of this middleware.
+	Plugin string `json:"plugin,omitempty" yaml:"plugin,omitempty"`
+
+	metadata.Propagation `yaml:",inline"`
+}
+
+// Middleware is the server propagation middleware.
+type Middleware struct {
+	config Config
+}
+
+// Provide creates a new propagation middleware from the config.
+func Provide(
+	configSection []string,
+	configKey string,
+	configData map[string]any,
+	_ log.Logger,
+) (server.Middleware, error) {
+	cfg := Config{Plugin: Name}
+
+	if err := config.Parse(configSection, configKey, configData, &cfg); err != nil && !errors.Is(err, config.ErrNoSuchKey) {
+		return nil, err
+	}
+
+	return New(cfg), nil
+}
+
+// New creates a new propagation middleware.
+func New(cfg Config) *Middleware {
+	return &Middleware{config: cfg}
+}
+
+// Start is a no-op.
+func (m *Middleware) Start(_ context.Context) error {
+	return nil
+}
+
+// Stop is a no-op.
+func (m *Middleware) Stop(_ context.Context) error {
+	return nil
+}
+
+// Type returns the component type.
+func (m *Middleware) Type() string {
+	return server.MiddlewareComponentType
+}
+
+// String returns the name of this middleware.
+func (m *Middleware) String() string {
+	return Name
+}
+
+// Call takes the selected incoming metadata before the handler runs.
+func (m *Middleware) Call(next server.MiddlewareCallHandler) server.MiddlewareCallHandler {
+	return func(ctx context.Context, req any) (any, error) {
+		return next(metadata.Propagate(ctx, m.config.Propagation), req)
+	}
+}
+
+// Stream takes the selected incoming metadata before the handler runs.
+func (m *Middleware) Stream(next server.StreamHandler) server.StreamHandler {
+	return func(ctx context.Context, stream server.Stream) error {
+		return next(metadata.Propagate(ctx, m.config.Propagation), stream)
+	}
+}
@@ -0,0 +1,115 @@
+package propagation
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-orb/go-orb/client"
+	clientpropagation "github.com/go-orb/go-orb/client/middleware/propagation"
+	"github.com/go-orb/go-orb/server"
+	"github.com/go-orb/go-orb/util/metadata"
+)
+
+// hop is a server which makes a request to the next hop with the propagation client
+// middleware and returns the metadata the next hop receives.
+func hop(srv *Middleware, cli *clientpropagation.Middleware) server.MiddlewareCallHandler {
+	request := cli.Request(func(ctx context.Context, _, _ string, _, result any, _ *client.CallOptions) error {
+		out, _ := metadata.OutgoingMD(ctx)
+		*result.(**metadata.MD) = out //nolint:errcheck,forcetypeassert
+
+		return nil
+	})
+
+	return srv.Call(func(ctx context.Context, _ any) (any, error) {
+		var out *metadata.MD
+		err := request(ctx, "svc", "Foo.Bar", nil, &out, &client.CallOptions{})
+
+		return out, err
+	})
+}
+
+func TestRoundTrip(t *testing.T) {
+	srv := New(Config{Propagation: metadata.Propagation{
+		Keys:         []string{"tenant-id"},
+		Prefixes:     []string{"x-"},
+		DenyPrefixes: []string{"x-internal-"},
+	}})
+	cli := clientpropagation.New(clientpropagation.Config{Propagation: metadata.Propagation{Keys: []string{"authorization"}}})
+
+	in := metadata.Pairs(
+		"Tenant-ID", "t1",
+		"x-trace-id", "abc",
+		"x-internal-secret", "secret",
+		"authorization", "token",
+		"other", "value",
+		metadata.Method, "Foo.Bar",
+	)
+
+	handler := hop(srv, cli)
+
+	result, err := handler(metadata.NewIncoming(context.Background(), in), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out := result.(*metadata.MD) //nolint:errcheck,forcetypeassert
+	expected := map[string]string{
+		"tenant-id":     "t1",
+		"x-trace-id":    "abc",
+		"authorization": "token",
+	}
+
+	if out.Len() != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, out.Map())
+	}
+
+	for k, v := range expected {
+		if got, _ := out.Value(k); got != v {
+			t.Fatalf("expected %s=%s, got %v", k, v, out.Map())
+		}
+	}
+
+	// The next hop propagates the keys again, without the client's own selection.
+	next := hop(srv, clientpropagation.New(clientpropagation.Config{}))
+
+	result, err = next(metadata.NewIncoming(context.Background(), out), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out = result.(*metadata.MD) //nolint:errcheck,forcetypeassert
+	if _, ok := out.Value("authorization"); ok || out.Len() != 2 {
+		t.Fatalf("expected tenant-id and x-trace-id, got %v", out.Map())
+	}
+}
+
+func TestRoundTripStream(t *testing.T) {
+	srv := New(Config{Propagation: metadata.Propagation{Keys: []string{"tenant-id"}}})
+
+	var out *metadata.MD
+
+	open := clientpropagation.New(clientpropagation.Config{}).Stream(
+		func(ctx context.Context, _, _ string, _ *client.CallOptions) (client.StreamIface[any, any], error) {
+			out, _ = metadata.OutgoingMD(ctx)
+			return nil, nil //nolint:nilnil
+		},
+	)
+
+	handler := srv.Stream(func(ctx context.Context, _ server.Stream) error {
+		_, err := open(ctx, "svc", "Foo.Watch", &client.CallOptions{})
+		return err
+	})
+
+	ctx := metadata.NewIncoming(context.Background(), metadata.Pairs("tenant-id", "t1", "other", "value"))
+	if err := handler(ctx, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if out == nil || out.Len() != 1 {
+		t.Fatalf("expected tenant-id, got %v", out)
+	}
+
+	if v, _ := out.Value("tenant-id"); v != "t1" {
+		t.Fatalf("expected tenant-id=t1, got %v", out.Map())
+	}
+}
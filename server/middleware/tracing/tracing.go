@@ -0,0 +1,176 @@
+// Package tracing provides a server middleware which records a span for each call,
+// as child of the trace context the "tracing" client middleware sends.
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/go-orb/go-orb/config"
+	"github.com/go-orb/go-orb/log"
+	"github.com/go-orb/go-orb/server"
+	"github.com/go-orb/go-orb/tracing"
+	"github.com/go-orb/go-orb/util/metadata"
+)
+
+// Name is the name of this middleware.
+const Name = "tracing"
+
+var (
+	_ server.Middleware       = (*Middleware)(nil)
+	_ server.StreamMiddleware = (*Middleware)(nil)
+)
+
+func init() {
+	server.Middlewares.Add(Name, Provide)
+}
+
+// Config is the config of the tracing middleware, it takes the options of the
+// "tracing" section for the tracer of the middleware.
+type Config struct {
+	// Plugin is the name of this middleware.
+	Plugin string `json:"plugin,omitempty" yaml:"plugin,omitempty"`
+
+	tracing.Config `yaml:",inline"`
+}
+
+// Middleware is the server tracing middleware.
+type Middleware struct {
+	config Config
+	tracer *tracing.Tracer
+	// owned is set if the middleware created the tracer, it starts and stops it then.
+	owned bool
+}
+
+// Provide creates a tracing middleware with a tracer from its config. It fails with
+// tracing.ErrNoExporter if there's no exporter in the config, use ProvideWithTracer
+// to share the tracer of the service.
+func Provide(
+	configSection []string,
+	configKey string,
+	configData map[string]any,
+	logger log.Logger,
+) (server.Middleware, error) {
+	cfg, err := parse(configSection, configKey, configData)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.Exporter == "" {
+		return nil, fmt.Errorf("server middleware %s: %w", Name, tracing.ErrNoExporter)
+	}
+
+	// The exporter gets the config of the middleware.
+	exporterData, err := config.WalkMap(append(slices.Clone(configSection), configKey), configData)
+	if err != nil {
+		return nil, err
+	}
+
+	tracer, err := tracing.NewFromConfig(cfg.Config, exporterData, logger.With("middleware", Name))
+	if err != nil {
+		return nil, err
+	}
+
+	m := New(cfg, tracer)
+	m.owned = true
+
+	return m, nil
+}
+
+// ProvideWithTracer returns a provider whose middlewares record spans with t instead of
+// a tracer of their own, for example the component from tracing.Provide. They don't start or stop t.
+func ProvideWithTracer(t *tracing.Tracer) server.MiddlewareProvider {
+	return func(configSection []string, configKey string, configData map[string]any, _ log.Logger) (server.Middleware, error) {
+		cfg, err := parse(configSection, configKey, configData)
+		if err != nil {
+			return nil, err
+		}
+
+		return New(cfg, t), nil
+	}
+}
+
+func parse(configSection []string, configKey string, configData map[string]any) (Config, error) {
+	cfg := Config{Plugin: Name, Config: tracing.NewConfig()}
+
+	if err := config.Parse(configSection, configKey, configData, &cfg); err != nil && !errors.Is(err, config.ErrNoSuchKey) {
+		return Config{}, err
+	}
+
+	return cfg, nil
+}
+
+// New creates a new tracing middleware, tracer may be nil to only restore the trace context of the caller.
+func New(cfg Config, tracer *tracing.Tracer) *Middleware {
+	return &Middleware{config: cfg, tracer: tracer}
+}
+
+// Start starts the tracer if the middleware created it.
+func (m *Middleware) Start(ctx context.Context) error {
+	if !m.owned {
+		return nil
+	}
+
+	return m.tracer.Start(ctx)
+}
+
+// Stop exports the buffered spans of the tracer if the middleware created it.
+func (m *Middleware) Stop(ctx context.Context) error {
+	if !m.owned {
+		return nil
+	}
+
+	return m.tracer.Stop(ctx)
+}
+
+// Type returns the component type.
+func (m *Middleware) Type() string {
+	return server.MiddlewareComponentType
+}
+
+// String returns the name of this middleware.
+func (m *Middleware) String() string {
+	return Name
+}
+
+// Call records a server span around the handler.
+func (m *Middleware) Call(next server.MiddlewareCallHandler) server.MiddlewareCallHandler {
+	return func(ctx context.Context, req any) (any, error) {
+		ctx, span := m.start(ctx)
+		defer span.End()
+
+		result, err := next(ctx, req)
+		span.SetError(err)
+
+		return result, err
+	}
+}
+
+// Stream records a server span around the stream handler.
+func (m *Middleware) Stream(next server.StreamHandler) server.StreamHandler {
+	return func(ctx context.Context, stream server.Stream) error {
+		ctx, span := m.start(ctx)
+		defer span.End()
+
+		err := next(ctx, stream)
+		span.SetError(err)
+
+		return err
+	}
+}
+
+// start starts a server span as child of the callers trace context.
+func (m *Middleware) start(ctx context.Context) (context.Context, *tracing.Span) {
+	ctx = tracing.ExtractIncoming(ctx)
+
+	service, _ := metadata.GetIncoming(ctx, metadata.Service)
+	method, _ := metadata.GetIncoming(ctx, metadata.Method)
+
+	return m.tracer.StartSpan(ctx, service+"/"+method,
+		tracing.WithKind(tracing.KindServer),
+		tracing.WithAttribute(tracing.AttrService, service),
+		tracing.WithAttribute(tracing.AttrEndpoint, method),
+	)
+}
@@ -0,0 +1,127 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/go-orb/go-orb/codecs"
+	"github.com/go-orb/go-orb/log"
+	"github.com/go-orb/go-orb/server"
+	"github.com/go-orb/go-orb/tracing"
+	"github.com/go-orb/go-orb/tracing/memory"
+	"github.com/go-orb/go-orb/util/metadata"
+	"github.com/go-orb/go-orb/util/orberrors"
+)
+
+// jsonCodec is a minimal JSON codec, config.Parse needs one.
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v any) ([]byte, error)         { return json.Marshal(v) }
+func (jsonCodec) Unmarshal(data []byte, v any) error    { return json.Unmarshal(data, v) }
+func (jsonCodec) Marshals(any) bool                     { return true }
+func (jsonCodec) Unmarshals(any) bool                   { return true }
+func (jsonCodec) NewDecoder(r io.Reader) codecs.Decoder { return json.NewDecoder(r) }
+func (jsonCodec) NewEncoder(w io.Writer) codecs.Encoder { return json.NewEncoder(w) }
+func (jsonCodec) ContentTypes() []string                { return []string{codecs.MimeJSON} }
+func (jsonCodec) Name() string                          { return "json" }
+func (jsonCodec) Exts() []string                        { return []string{".json"} }
+
+func init() {
+	codecs.Register("json", jsonCodec{})
+}
+
+func testLogger() log.Logger {
+	return log.Logger{Logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+}
+
+// incoming returns a context with the metadata a server receives from a traced client.
+func incoming(parent tracing.SpanContext) context.Context {
+	md := metadata.Pairs(metadata.Service, "svc", metadata.Method, "ep", tracing.TraceparentKey, parent.Traceparent())
+	return metadata.NewIncoming(context.Background(), md)
+}
+
+func TestProvide(t *testing.T) {
+	configData := func(mw map[string]any) map[string]any {
+		return map[string]any{"server": map[string]any{"middlewares": []any{mw}}}
+	}
+
+	if _, err := Provide([]string{"server", "middlewares"}, "0", configData(map[string]any{"plugin": Name}), testLogger()); !errors.Is(err, tracing.ErrNoExporter) {
+		t.Fatalf("expected ErrNoExporter, got %v", err)
+	}
+
+	mw, err := Provide([]string{"server", "middlewares"}, "0", configData(map[string]any{"plugin": Name, "exporter": memory.Name}), testLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m, ok := mw.(*Middleware)
+	if !ok || m.tracer == nil || m.config.Exporter != memory.Name {
+		t.Fatalf("expected a middleware with a tracer for the memory exporter, got %+v", mw)
+	}
+
+	if err := m.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := m.Stop(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCall(t *testing.T) {
+	exporter := memory.New()
+	m := New(Config{Plugin: Name}, tracing.New(tracing.NewConfig(), exporter, testLogger()))
+
+	parent := tracing.SpanContext{TraceID: tracing.TraceID{1}, SpanID: tracing.SpanID{2}, Flags: tracing.FlagSampled}
+
+	var handlerSpan tracing.SpanContext
+
+	_, err := m.Call(func(ctx context.Context, _ any) (any, error) {
+		handlerSpan = tracing.SpanFromContext(ctx).SpanContext()
+		return nil, orberrors.ErrNotFound
+	})(incoming(parent), nil)
+	if !errors.Is(err, orberrors.ErrNotFound) {
+		t.Fatalf("expected the error of the handler, got %v", err)
+	}
+
+	spans := exporter.Spans()
+	if len(spans) != 1 {
+		t.Fatalf("expected 1 span, got %d", len(spans))
+	}
+
+	span := spans[0]
+	if span.Name != "svc/ep" || span.Kind != tracing.KindServer {
+		t.Fatalf("expected a server span for svc/ep, got %+v", span)
+	}
+
+	if span.SpanContext.TraceID != parent.TraceID || span.Parent != parent.SpanID || handlerSpan != span.SpanContext {
+		t.Fatalf("expected the span to continue the callers trace, got %+v", span)
+	}
+
+	if !errors.Is(span.Err, orberrors.ErrNotFound) {
+		t.Fatalf("expected the error on the span, got %v", span.Err)
+	}
+}
+
+func TestStream(t *testing.T) {
+	exporter := memory.New()
+	m := New(Config{Plugin: Name}, tracing.New(tracing.NewConfig(), exporter, testLogger()))
+
+	parent := tracing.SpanContext{TraceID: tracing.TraceID{1}, SpanID: tracing.SpanID{2}, Flags: tracing.FlagSampled}
+
+	err := m.Stream(func(context.Context, server.Stream) error {
+		return nil
+	})(incoming(parent), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	spans := exporter.Spans()
+	if len(spans) != 1 || spans[0].Parent != parent.SpanID || spans[0].Err != nil {
+		t.Fatalf("expected a successful span below the caller, got %+v", spans)
+	}
+}
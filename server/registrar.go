@@ -0,0 +1,332 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+
+	"github.com/go-orb/go-orb/cli"
+	"github.com/go-orb/go-orb/config"
+	"github.com/go-orb/go-orb/log"
+	"github.com/go-orb/go-orb/registry"
+	"github.com/go-orb/go-orb/types"
+)
+
+var _ types.Component = (*Registrar)(nil)
+
+// RegistrarComponentType is the registrar component type name.
+const RegistrarComponentType = "registrar"
+
+const (
+	// maxRegistrarJitter is the largest jitter, a larger one could make the interval zero.
+	maxRegistrarJitter = 0.9
+	// minRegistrarInterval is the shortest refresh interval, so nodes don't get re-registered in a loop.
+	minRegistrarInterval = 10 * time.Millisecond
+)
+
+//nolint:gochecknoglobals
+var (
+	// DefaultRegistrarTTL is the TTL the registrar sets on the nodes.
+	DefaultRegistrarTTL = config.Duration(30 * time.Second)
+	// DefaultRegistrarRefreshRatio is the fraction of the TTL after which nodes get re-registered.
+	DefaultRegistrarRefreshRatio = 0.5
+	// DefaultRegistrarJitter is the fraction of the refresh interval which gets randomly added or subtracted.
+	DefaultRegistrarJitter = 0.1
+)
+
+// RegistrarConfig is the config of the registrar, it lives in the "registrar" key of the server config.
+type RegistrarConfig struct {
+	// TTL is set on all registered nodes, 0 registers them once without TTL.
+	TTL config.Duration `json:"ttl,omitempty" yaml:"ttl,omitempty"`
+	// RefreshRatio is the fraction of the TTL after which the nodes get re-registered.
+	RefreshRatio float64 `json:"refreshRatio,omitempty" yaml:"refreshRatio,omitempty"`
+	// Jitter is the fraction of the refresh interval which gets randomly added or subtracted,
+	// it's limited to [0, 0.9].
+	Jitter float64 `json:"jitter,omitempty" yaml:"jitter,omitempty"`
+
+	// Namespace of the nodes.
+	Namespace string `json:"namespace,omitempty" yaml:"namespace,omitempty"`
+	// Region of the nodes.
+	Region string `json:"region,omitempty" yaml:"region,omitempty"`
+	// Metadata of the nodes.
+	Metadata map[string]string `json:"metadata,omitempty" yaml:"metadata,omitempty"`
+}
+
+// RegistrarOption is a functional option for the registrar.
+type RegistrarOption func(*RegistrarConfig)
+
+// WithRegistrarTTL sets the TTL of the nodes.
+func WithRegistrarTTL(n time.Duration) RegistrarOption {
+	return func(c *RegistrarConfig) {
+		c.TTL = config.Duration(n)
+	}
+}
+
+// WithRegistrarRefreshRatio sets the fraction of the TTL after which nodes get re-registered.
+func WithRegistrarRefreshRatio(n float64) RegistrarOption {
+	return func(c *RegistrarConfig) {
+		c.RefreshRatio = n
+	}
+}
+
+// WithRegistrarJitter sets the jitter of the refresh interval.
+func WithRegistrarJitter(n float64) RegistrarOption {
+	return func(c *RegistrarConfig) {
+		c.Jitter = n
+	}
+}
+
+// WithRegistrarNamespace sets the namespace of the nodes.
+func WithRegistrarNamespace(n string) RegistrarOption {
+	return func(c *RegistrarConfig) {
+		c.Namespace = n
+	}
+}
+
+// WithRegistrarRegion sets the region of the nodes.
+func WithRegistrarRegion(n string) RegistrarOption {
+	return func(c *RegistrarConfig) {
+		c.Region = n
+	}
+}
+
+// WithRegistrarMetadata sets the metadata of the nodes.
+func WithRegistrarMetadata(md map[string]string) RegistrarOption {
+	return func(c *RegistrarConfig) {
+		c.Metadata = md
+	}
+}
+
+// NewRegistrarConfig creates a new registrar config with the given opts.
+func NewRegistrarConfig(opts ...RegistrarOption) RegistrarConfig {
+	cfg := RegistrarConfig{
+		TTL:          DefaultRegistrarTTL,
+		RefreshRatio: DefaultRegistrarRefreshRatio,
+		Jitter:       DefaultRegistrarJitter,
+	}
+
+	for _, option := range opts {
+		option(&cfg)
+	}
+
+	return cfg
+}
+
+// Registrar registers the entrypoints of a server in the registry and re-registers
+// them before their TTL runs out. On Stop it deregisters them.
+//
+// Add it with types.PriorityRegistrar to the components, so it starts after the
+// registry and the servers, and stops before them.
+type Registrar struct {
+	name        string
+	version     string
+	config      RegistrarConfig
+	entrypoints []Entrypoint
+	registry    registry.Type
+	logger      log.Logger
+
+	mu     sync.Mutex
+	nodes  []registry.ServiceNode
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewRegistrar creates a new registrar for the given entrypoints.
+func NewRegistrar(
+	name string,
+	version string,
+	entrypoints []Entrypoint,
+	reg registry.Type,
+	logger log.Logger,
+	cfg RegistrarConfig,
+) *Registrar {
+	return &Registrar{
+		name:        name,
+		version:     version,
+		config:      cfg,
+		entrypoints: entrypoints,
+		registry:    reg,
+		logger:      logger.With("component", RegistrarComponentType),
+	}
+}
+
+// ProvideRegistrar creates a registrar for all entrypoints of srv and adds it to the components.
+func ProvideRegistrar(
+	svcCtx *cli.ServiceContextWithConfig,
+	components *types.Components,
+	logger log.Logger,
+	reg registry.Type,
+	srv Server,
+	opts ...RegistrarOption,
+) (*Registrar, error) {
+	cfg := NewRegistrarConfig(opts...)
+
+	err := config.Parse([]string{DefaultConfigSection}, "registrar", svcCtx.Config(), &cfg)
+	if err != nil && !errors.Is(err, config.ErrNoSuchKey) {
+		return nil, err
+	}
+
+	entrypoints := []Entrypoint{}
+
+	srv.GetEntrypoints().Range(func(_ string, ep Entrypoint) bool {
+		entrypoints = append(entrypoints, ep)
+		return true
+	})
+
+	registrar := NewRegistrar(svcCtx.Name(), svcCtx.Version(), entrypoints, reg, logger, cfg)
+
+	if err := components.Add(registrar, types.PriorityRegistrar); err != nil {
+		logger.Warn("while registering the registrar as a component", "error", err)
+	}
+
+	return registrar, nil
+}
+
+// NodeFromEntrypoint creates the registry node of an entrypoint.
+func NodeFromEntrypoint(name, version string, ep Entrypoint) registry.ServiceNode {
+	return registry.ServiceNode{
+		Name:    name,
+		Version: version,
+		Node:    ep.Name(),
+		Network: ep.Network(),
+		Scheme:  ep.Transport(),
+		Address: ep.Address(),
+	}
+}
+
+// Start registers the nodes and starts the heartbeat.
+func (r *Registrar) Start(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.cancel != nil {
+		return nil
+	}
+
+	// Entrypoints know their address after they have been started.
+	r.nodes = make([]registry.ServiceNode, 0, len(r.entrypoints))
+	for _, ep := range r.entrypoints {
+		node := NodeFromEntrypoint(r.name, r.version, ep)
+		node.Namespace = r.config.Namespace
+		node.Region = r.config.Region
+		node.Metadata = maps.Clone(r.config.Metadata)
+		node.TTL = time.Duration(r.config.TTL)
+
+		r.nodes = append(r.nodes, node)
+	}
+
+	if err := r.register(ctx); err != nil {
+		r.nodes = nil
+		return err
+	}
+
+	if r.config.TTL <= 0 {
+		return nil
+	}
+
+	hCtx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+	r.done = make(chan struct{})
+
+	go r.heartbeat(hCtx, r.nodes)
+
+	return nil
+}
+
+// Stop stops the heartbeat and deregisters the nodes.
+func (r *Registrar) Stop(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.cancel != nil {
+		r.cancel()
+		<-r.done
+
+		r.cancel = nil
+	}
+
+	var result error
+
+	for _, node := range r.nodes {
+		if err := r.registry.Deregister(ctx, node); err != nil {
+			result = multierror.Append(result, fmt.Errorf("deregister %s: %w", node, err))
+		}
+	}
+
+	r.nodes = nil
+
+	return result
+}
+
+// Type returns the component type.
+func (r *Registrar) Type() string {
+	return RegistrarComponentType
+}
+
+// String returns the service name.
+func (r *Registrar) String() string {
+	return r.name
+}
+
+// register registers the nodes, if one fails it deregisters the ones before it.
+func (r *Registrar) register(ctx context.Context) error {
+	for i, node := range r.nodes {
+		err := r.registry.Register(ctx, node)
+		if err == nil {
+			continue
+		}
+
+		result := fmt.Errorf("register %s: %w", node, err)
+
+		for _, registered := range r.nodes[:i] {
+			if err := r.registry.Deregister(ctx, registered); err != nil {
+				result = multierror.Append(result, fmt.Errorf("deregister %s: %w", registered, err))
+			}
+		}
+
+		return result
+	}
+
+	return nil
+}
+
+func (r *Registrar) heartbeat(ctx context.Context, nodes []registry.ServiceNode) {
+	defer close(r.done)
+
+	for {
+		timer := time.NewTimer(r.interval())
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		for _, node := range nodes {
+			if err := r.registry.Register(ctx, node); err != nil {
+				r.logger.Warn("while re-registering a node", "node", node.String(), "error", err)
+			}
+		}
+	}
+}
+
+// interval returns the refresh interval with jitter applied, it's at least minRegistrarInterval.
+func (r *Registrar) interval() time.Duration {
+	interval := float64(r.config.TTL) * r.config.RefreshRatio
+	if interval <= 0 || r.config.RefreshRatio >= 1 {
+		interval = float64(r.config.TTL) * DefaultRegistrarRefreshRatio
+	}
+
+	if jitter := min(r.config.Jitter, maxRegistrarJitter); jitter > 0 {
+		interval += interval * jitter * (rand.Float64()*2 - 1) //nolint:gosec
+	}
+
+	return max(time.Duration(interval), minRegistrarInterval)
+}
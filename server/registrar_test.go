@@ -0,0 +1,122 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/go-orb/go-orb/log"
+	"github.com/go-orb/go-orb/registry"
+	"github.com/go-orb/go-orb/registry/memory"
+)
+
+// testEntrypoint is an entrypoint which only has an address.
+type testEntrypoint struct {
+	Entrypoint
+
+	name    string
+	address string
+}
+
+func (e *testEntrypoint) Name() string      { return e.name }
+func (e *testEntrypoint) Transport() string { return "grpc" }
+func (e *testEntrypoint) Network() string   { return "tcp" }
+func (e *testEntrypoint) Address() string   { return e.address }
+
+func newTestRegistrar(t *testing.T, cfg RegistrarConfig, entrypoints ...Entrypoint) (*Registrar, registry.Type) {
+	t.Helper()
+
+	logger := log.Logger{Logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+
+	reg := memory.New(memory.NewConfig(memory.WithExpiryInterval(10*time.Millisecond)), logger)
+	if err := reg.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { _ = reg.Stop(context.Background()) }) //nolint:errcheck
+
+	return NewRegistrar("svc", "v1", entrypoints, registry.Type{Registry: reg}, logger, cfg), registry.Type{Registry: reg}
+}
+
+func TestRegistrar(t *testing.T) {
+	ctx := context.Background()
+	md := map[string]string{"zone": "a"}
+
+	r, reg := newTestRegistrar(t,
+		NewRegistrarConfig(WithRegistrarTTL(100*time.Millisecond), WithRegistrarJitter(0), WithRegistrarMetadata(md)),
+		&testEntrypoint{name: "grpc", address: "127.0.0.1:1"},
+		&testEntrypoint{name: "grpcs", address: "127.0.0.1:2"},
+	)
+
+	if err := r.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// Each node has its own copy of the metadata.
+	r.nodes[0].Metadata["zone"] = "b"
+
+	if r.nodes[1].Metadata["zone"] != "a" || md["zone"] != "a" {
+		t.Fatal("expected the metadata not to be shared between the nodes and the config")
+	}
+
+	// The heartbeat re-registers the nodes before their TTL runs out.
+	time.Sleep(300 * time.Millisecond)
+
+	nodes, err := reg.GetService(ctx, "", "", "svc", nil)
+	if err != nil || len(nodes) != 2 {
+		t.Fatalf("expected 2 nodes after the TTL, got %d: %v", len(nodes), err)
+	}
+
+	if err := r.Stop(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := reg.GetService(ctx, "", "", "svc", nil); !errors.Is(err, registry.ErrNotFound) {
+		t.Fatalf("expected the nodes to be deregistered, got %v", err)
+	}
+}
+
+func TestRegistrarStartFailure(t *testing.T) {
+	ctx := context.Background()
+
+	r, reg := newTestRegistrar(t,
+		NewRegistrarConfig(WithRegistrarTTL(0)),
+		&testEntrypoint{name: "grpc", address: "127.0.0.1:1"},
+		// A node without a name is invalid.
+		&testEntrypoint{name: "", address: "127.0.0.1:2"},
+	)
+
+	if err := r.Start(ctx); err == nil {
+		t.Fatal("expected the invalid node to fail")
+	}
+
+	if _, err := reg.GetService(ctx, "", "", "svc", nil); !errors.Is(err, registry.ErrNotFound) {
+		t.Fatalf("expected the registered nodes to be rolled back, got %v", err)
+	}
+}
+
+func TestRegistrarInterval(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		cfg      RegistrarConfig
+		min, max time.Duration
+	}{
+		{"no jitter", NewRegistrarConfig(WithRegistrarTTL(time.Second), WithRegistrarJitter(0)), 500 * time.Millisecond, 500 * time.Millisecond},
+		{"jitter", NewRegistrarConfig(WithRegistrarTTL(time.Second), WithRegistrarJitter(0.2)), 400 * time.Millisecond, 600 * time.Millisecond},
+		{"negative jitter", NewRegistrarConfig(WithRegistrarTTL(time.Second), WithRegistrarJitter(-2)), 500 * time.Millisecond, 500 * time.Millisecond},
+		// A jitter above 1 could make the interval zero or negative.
+		{"jitter above 1", NewRegistrarConfig(WithRegistrarTTL(time.Second), WithRegistrarJitter(5)), 50 * time.Millisecond, 950 * time.Millisecond},
+		{"tiny ttl", NewRegistrarConfig(WithRegistrarTTL(time.Nanosecond)), minRegistrarInterval, minRegistrarInterval},
+	} {
+		r := &Registrar{config: tc.cfg}
+
+		for range 1000 {
+			if d := r.interval(); d < tc.min || d > tc.max {
+				t.Fatalf("%s: expected an interval in [%s, %s], got %s", tc.name, tc.min, tc.max, d)
+			}
+		}
+	}
+}
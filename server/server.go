@@ -17,6 +17,7 @@ import (
 	"github.com/go-orb/go-orb/registry"
 	"github.com/go-orb/go-orb/types"
 	"github.com/go-orb/go-orb/util/container"
+	"github.com/go-orb/go-orb/util/matcher"
 )
 
 var _ types.Component = (*Server)(nil)
@@ -52,7 +53,8 @@ func New(
 	}
 
 	// Configure Middlewares.
-	mws := []Middleware{}
+	mws := []configuredMiddleware{}
+	mwMatcher := matcher.NewMatcher[Middleware](nil)
 
 	for idx, cfgMw := range cfg.Middlewares {
 		pFunc, ok := Middlewares.Get(cfgMw.Plugin)
@@ -65,7 +67,13 @@ func New(
 			return Server{}, err
 		}
 
-		mws = append(mws, mw)
+		// The index is the name, a plugin may be configured multiple times.
+		mw, err = selectMiddleware(&mwMatcher, strconv.Itoa(idx), mw, cfgMw)
+		if err != nil {
+			return Server{}, fmt.Errorf("middleware '%s': %w", cfgMw.Plugin, err)
+		}
+
+		mws = append(mws, configuredMiddleware{middleware: mw, entrypoints: cfgMw.Entrypoints})
 	}
 
 	// Get handlers.
@@ -121,7 +129,7 @@ func New(
 
 		epLogger := logger.With("component", ComponentType, "plugin", cfgEp.Plugin, "entrypoint", epName)
 
-		ep, err := pFunc(name, version, epName, epConfig, epLogger, reg, WithEntrypointMiddlewares(mws...), WithEntrypointHandlers(handlers...))
+		ep, err := pFunc(name, version, epName, epConfig, epLogger, reg, WithEntrypointMiddlewares(entrypointMiddlewares(epName, mws)...), WithEntrypointHandlers(handlers...))
 		if err != nil {
 			return Server{}, err
 		}
@@ -0,0 +1,93 @@
+package tracing
+
+import (
+	"time"
+
+	"github.com/go-orb/go-orb/config"
+)
+
+//nolint:gochecknoglobals
+var (
+	// DefaultConfigSection is the section key used in config files used to configure tracing.
+	DefaultConfigSection = "tracing"
+
+	// DefaultSampleRatio is the ratio of new traces which get sampled.
+	DefaultSampleRatio = 1.0
+	// DefaultBatchSize is the maximum number of spans exported at once.
+	DefaultBatchSize = 256
+	// DefaultQueueSize is the number of spans buffered for export, spans get dropped when it's full.
+	DefaultQueueSize = 4096
+	// DefaultFlushInterval is the interval in which buffered spans get exported.
+	DefaultFlushInterval = config.Duration(time.Second)
+)
+
+// Config is the tracing config.
+type Config struct {
+	// Exporter is the name of the exporter plugin, tracing is disabled if it's empty.
+	Exporter string `json:"exporter,omitempty" yaml:"exporter,omitempty"`
+
+	// SampleRatio is the ratio of new traces which get sampled, from 0 to 1.
+	// Traces started by other services follow the callers decision.
+	SampleRatio float64 `json:"sampleRatio,omitempty" yaml:"sampleRatio,omitempty"`
+
+	// BatchSize is the maximum number of spans exported at once.
+	BatchSize int `json:"batchSize,omitempty" yaml:"batchSize,omitempty"`
+	// QueueSize is the number of spans buffered for export, spans get dropped when it's full.
+	QueueSize int `json:"queueSize,omitempty" yaml:"queueSize,omitempty"`
+	// FlushInterval is the interval in which buffered spans get exported.
+	FlushInterval config.Duration `json:"flushInterval,omitempty" yaml:"flushInterval,omitempty"`
+}
+
+// Option is a functional option for tracing.
+type Option func(*Config)
+
+// WithExporter sets the exporter plugin.
+func WithExporter(n string) Option {
+	return func(c *Config) {
+		c.Exporter = n
+	}
+}
+
+// WithSampleRatio sets the ratio of new traces which get sampled.
+func WithSampleRatio(n float64) Option {
+	return func(c *Config) {
+		c.SampleRatio = n
+	}
+}
+
+// WithBatchSize sets the maximum number of spans exported at once.
+func WithBatchSize(n int) Option {
+	return func(c *Config) {
+		c.BatchSize = n
+	}
+}
+
+// WithQueueSize sets the number of spans buffered for export.
+func WithQueueSize(n int) Option {
+	return func(c *Config) {
+		c.QueueSize = n
+	}
+}
+
+// WithFlushInterval sets the interval in which buffered spans get exported.
+func WithFlushInterval(n time.Duration) Option {
+	return func(c *Config) {
+		c.FlushInterval = config.Duration(n)
+	}
+}
+
+// NewConfig creates a new config with the defaults and applies opts on top.
+func NewConfig(opts ...Option) Config {
+	cfg := Config{
+		SampleRatio:   DefaultSampleRatio,
+		BatchSize:     DefaultBatchSize,
+		QueueSize:     DefaultQueueSize,
+		FlushInterval: DefaultFlushInterval,
+	}
+
+	for _, o := range opts {
+		o(&cfg)
+	}
+
+	return cfg
+}
@@ -0,0 +1,61 @@
+// Package memory provides a tracing exporter which keeps spans in memory, for tests.
+package memory
+
+import (
+	"slices"
+	"sync"
+
+	"github.com/go-orb/go-orb/log"
+	"github.com/go-orb/go-orb/tracing"
+)
+
+// Name is the name of this exporter.
+const Name = "memory"
+
+var _ tracing.Exporter = (*Exporter)(nil)
+
+func init() {
+	tracing.Exporters.Add(Name, Provide)
+}
+
+// Exporter keeps exported spans in memory.
+type Exporter struct {
+	mu    sync.Mutex
+	spans []tracing.SpanData
+}
+
+// Provide creates a new memory exporter.
+func Provide(_ map[string]any, _ log.Logger) (tracing.Exporter, error) {
+	return New(), nil
+}
+
+// New creates a new memory exporter.
+func New() *Exporter {
+	return &Exporter{}
+}
+
+// Export stores spans.
+func (e *Exporter) Export(spans []tracing.SpanData) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.spans = append(e.spans, spans...)
+
+	return nil
+}
+
+// Spans returns a copy of the exported spans, in the order they've been exported.
+func (e *Exporter) Spans() []tracing.SpanData {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return slices.Clone(e.spans)
+}
+
+// Reset removes all exported spans.
+func (e *Exporter) Reset() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.spans = nil
+}
@@ -0,0 +1,80 @@
+package memory
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/go-orb/go-orb/log"
+	"github.com/go-orb/go-orb/tracing"
+)
+
+func TestExporter(t *testing.T) {
+	e := New()
+
+	if err := e.Export([]tracing.SpanData{{Name: "a"}, {Name: "b"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := e.Export([]tracing.SpanData{{Name: "c"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	spans := e.Spans()
+	if len(spans) != 3 || spans[0].Name != "a" || spans[2].Name != "c" {
+		t.Fatalf("expected the spans in export order, got %+v", spans)
+	}
+
+	// Spans returns a copy.
+	spans[0].Name = "changed"
+
+	if e.Spans()[0].Name != "a" {
+		t.Fatal("expected Spans to return a copy")
+	}
+
+	e.Reset()
+
+	if n := len(e.Spans()); n != 0 {
+		t.Fatalf("expected no spans after Reset, got %d", n)
+	}
+}
+
+func TestTracer(t *testing.T) {
+	logger := log.Logger{Logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+
+	provider, ok := tracing.Exporters.Get(Name)
+	if !ok {
+		t.Fatal("expected the exporter to be registered")
+	}
+
+	exporter, err := provider(nil, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e, ok := exporter.(*Exporter)
+	if !ok {
+		t.Fatalf("expected a memory exporter, got %T", exporter)
+	}
+
+	tracer := tracing.New(tracing.NewConfig(tracing.WithExporter(Name)), e, logger)
+
+	if err := tracer.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{"a", "b"} {
+		_, span := tracer.StartSpan(context.Background(), name)
+		span.End()
+	}
+
+	// Stop exports the buffered spans.
+	if err := tracer.Stop(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if spans := e.Spans(); len(spans) != 2 || spans[0].Name != "a" || spans[1].Name != "b" {
+		t.Fatalf("expected both spans, got %+v", spans)
+	}
+}
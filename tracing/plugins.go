@@ -0,0 +1,25 @@
+package tracing
+
+import (
+	"errors"
+
+	"github.com/go-orb/go-orb/log"
+	"github.com/go-orb/go-orb/util/container"
+)
+
+// ErrNoExporter is returned by the tracing middlewares when their config has no exporter.
+var ErrNoExporter = errors.New("no tracing exporter configured")
+
+// Exporter sends ended spans to a tracing backend.
+type Exporter interface {
+	// Export gets called with batches of sampled spans, from a single goroutine at a time.
+	Export(spans []SpanData) error
+}
+
+// ExporterProvider creates an exporter from the config of the "tracing" section.
+type ExporterProvider func(configData map[string]any, logger log.Logger) (Exporter, error)
+
+// Exporters is the plugins container for exporters.
+//
+//nolint:gochecknoglobals
+var Exporters = container.NewMap[string, ExporterProvider]()
@@ -0,0 +1,148 @@
+package tracing
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"strings"
+
+	"github.com/go-orb/go-orb/util/metadata"
+)
+
+// W3C trace context metadata keys, see https://www.w3.org/TR/trace-context/.
+const (
+	TraceparentKey = "traceparent"
+	TracestateKey  = "tracestate"
+)
+
+// traceparentVersion is the only version of the traceparent header we write.
+const traceparentVersion = "00"
+
+// ErrInvalidTraceparent is returned by ParseTraceparent.
+var ErrInvalidTraceparent = errors.New("invalid traceparent")
+
+// Traceparent returns sc in the W3C traceparent format.
+func (sc SpanContext) Traceparent() string {
+	return traceparentVersion + "-" + sc.TraceID.String() + "-" + sc.SpanID.String() + "-" + hex.EncodeToString([]byte{sc.Flags})
+}
+
+// ParseTraceparent parses a W3C traceparent header, the result is marked as remote.
+// Headers of future versions are accepted as long as they start with the version 00 fields.
+func ParseTraceparent(value string) (SpanContext, error) {
+	parts := strings.Split(strings.TrimSpace(value), "-")
+	if len(parts) < 4 {
+		return SpanContext{}, ErrInvalidTraceparent
+	}
+
+	version, traceID, spanID, flags := parts[0], parts[1], parts[2], parts[3]
+
+	if len(version) != 2 || version == "ff" || (version == traceparentVersion && len(parts) != 4) {
+		return SpanContext{}, ErrInvalidTraceparent
+	}
+
+	if _, err := hex.DecodeString(version); err != nil {
+		return SpanContext{}, ErrInvalidTraceparent
+	}
+
+	sc := SpanContext{Remote: true}
+
+	if err := decodeHex(sc.TraceID[:], traceID); err != nil {
+		return SpanContext{}, err
+	}
+
+	if err := decodeHex(sc.SpanID[:], spanID); err != nil {
+		return SpanContext{}, err
+	}
+
+	var f [1]byte
+	if err := decodeHex(f[:], flags); err != nil {
+		return SpanContext{}, err
+	}
+
+	sc.Flags = f[0]
+
+	if !sc.IsValid() {
+		return SpanContext{}, ErrInvalidTraceparent
+	}
+
+	return sc, nil
+}
+
+// decodeHex decodes lowercase hex s into dst, s must fill dst exactly.
+func decodeHex(dst []byte, s string) error {
+	if len(s) != hex.EncodedLen(len(dst)) || strings.ToLower(s) != s {
+		return ErrInvalidTraceparent
+	}
+
+	if _, err := hex.Decode(dst, []byte(s)); err != nil {
+		return ErrInvalidTraceparent
+	}
+
+	return nil
+}
+
+// Inject writes the span context of ctx into md, it's a no-op if ctx has none.
+func Inject(ctx context.Context, md *metadata.MD) {
+	sc, ok := SpanContextFromContext(ctx)
+	if !ok {
+		return
+	}
+
+	md.Set(TraceparentKey, sc.Traceparent())
+
+	if sc.TraceState != "" {
+		md.Set(TracestateKey, sc.TraceState)
+	} else {
+		md.Delete(TracestateKey)
+	}
+}
+
+// Extract reads the span context from md and returns a copy of ctx with it as remote parent.
+// It returns ctx unchanged if md has no valid traceparent.
+func Extract(ctx context.Context, md *metadata.MD) context.Context {
+	if md == nil {
+		return ctx
+	}
+
+	value, ok := md.Value(TraceparentKey)
+	if !ok {
+		return ctx
+	}
+
+	sc, err := ParseTraceparent(value)
+	if err != nil {
+		return ctx
+	}
+
+	sc.TraceState = strings.Join(md.Get(TracestateKey), ",")
+
+	return ContextWithRemoteSpanContext(ctx, sc)
+}
+
+// InjectOutgoing returns a copy of ctx with a clone of its outgoing metadata which
+// contains the span context of ctx. The parents metadata doesn't change, so
+// parallel requests with the same parent don't share it.
+func InjectOutgoing(ctx context.Context) context.Context {
+	if _, ok := SpanContextFromContext(ctx); !ok {
+		return ctx
+	}
+
+	md := metadata.New(nil)
+	if parent, ok := metadata.OutgoingMD(ctx); ok {
+		md = parent.Clone()
+	}
+
+	Inject(ctx, md)
+
+	return metadata.NewOutgoing(ctx, md)
+}
+
+// ExtractIncoming is Extract with the incoming metadata of ctx.
+func ExtractIncoming(ctx context.Context) context.Context {
+	md, ok := metadata.IncomingMD(ctx)
+	if !ok {
+		return ctx
+	}
+
+	return Extract(ctx, md)
+}
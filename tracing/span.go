@@ -0,0 +1,254 @@
+package tracing
+
+import (
+	"context"
+	"encoding/hex"
+	"maps"
+	"sync"
+	"time"
+
+	"github.com/go-orb/go-orb/util/orberrors"
+)
+
+// SpanKind tells the role of a span in a trace.
+type SpanKind int
+
+// Span kinds.
+const (
+	// KindInternal is an operation within a service.
+	KindInternal SpanKind = iota
+	// KindServer handles a request of a remote client.
+	KindServer
+	// KindClient makes a request to a remote server.
+	KindClient
+	// KindProducer publishes a message.
+	KindProducer
+	// KindConsumer receives a published message.
+	KindConsumer
+)
+
+// String returns the name of the kind.
+func (k SpanKind) String() string {
+	switch k {
+	case KindServer:
+		return "server"
+	case KindClient:
+		return "client"
+	case KindProducer:
+		return "producer"
+	case KindConsumer:
+		return "consumer"
+	case KindInternal:
+		return "internal"
+	default:
+		return "internal"
+	}
+}
+
+// Attribute keys used by the go-orb integrations.
+const (
+	AttrService   = "rpc.service"
+	AttrEndpoint  = "rpc.method"
+	AttrTransport = "rpc.transport"
+	AttrAddress   = "server.address"
+	AttrTier      = "orb.tier"
+	AttrRegion    = "orb.region"
+	AttrTopic     = "messaging.destination"
+	AttrErrorCode = "error.code"
+)
+
+// TraceID identifies a trace.
+type TraceID [16]byte
+
+// IsValid returns false for the all-zero id.
+func (t TraceID) IsValid() bool {
+	return t != TraceID{}
+}
+
+// String returns the hex encoded id.
+func (t TraceID) String() string {
+	return hex.EncodeToString(t[:])
+}
+
+// SpanID identifies a span within a trace.
+type SpanID [8]byte
+
+// IsValid returns false for the all-zero id.
+func (s SpanID) IsValid() bool {
+	return s != SpanID{}
+}
+
+// String returns the hex encoded id.
+func (s SpanID) String() string {
+	return hex.EncodeToString(s[:])
+}
+
+// FlagSampled is the sampled bit of the trace flags.
+const FlagSampled byte = 0x01
+
+// SpanContext is the part of a span which gets propagated to other services.
+type SpanContext struct {
+	TraceID TraceID
+	SpanID  SpanID
+	Flags   byte
+	// TraceState is the vendor specific tracestate header, it's passed on as is.
+	TraceState string
+	// Remote is true if the span context has been extracted from metadata.
+	Remote bool
+}
+
+// IsValid returns true if trace and span id are set.
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID.IsValid() && sc.SpanID.IsValid()
+}
+
+// IsSampled returns true if the sampled flag is set.
+func (sc SpanContext) IsSampled() bool {
+	return sc.Flags&FlagSampled != 0
+}
+
+// SpanData is a snapshot of an ended span, it's handed to exporters.
+type SpanData struct {
+	Name        string
+	Kind        SpanKind
+	SpanContext SpanContext
+	// Parent is the span id of the parent, invalid for root spans.
+	Parent     SpanID
+	Start      time.Time
+	End        time.Time
+	Attributes map[string]any
+	// Err is the error the operation failed with, nil if it succeeded.
+	Err error
+}
+
+// Duration returns the duration of the span.
+func (d SpanData) Duration() time.Duration {
+	return d.End.Sub(d.Start)
+}
+
+// Span is a single operation within a trace.
+//
+// All methods are safe for concurrent use and on a nil Span, which is returned
+// when there's no span in a context.
+type Span struct {
+	tracer *Tracer
+
+	mu    sync.Mutex
+	data  SpanData
+	ended bool
+}
+
+// SpanContext returns the span context of s.
+func (s *Span) SpanContext() SpanContext {
+	if s == nil {
+		return SpanContext{}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.data.SpanContext
+}
+
+// SetAttribute sets an attribute, it's a no-op once the span has ended.
+func (s *Span) SetAttribute(key string, value any) {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.ended {
+		return
+	}
+
+	if s.data.Attributes == nil {
+		s.data.Attributes = make(map[string]any)
+	}
+
+	s.data.Attributes[key] = value
+}
+
+// SetError marks the span as failed and records the orberrors code of err, a nil err is ignored.
+func (s *Span) SetError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.ended {
+		return
+	}
+
+	s.data.Err = err
+
+	if s.data.Attributes == nil {
+		s.data.Attributes = make(map[string]any)
+	}
+
+	s.data.Attributes[AttrErrorCode] = orberrors.From(err).Code
+}
+
+// End ends the span and hands it to the exporter if it's sampled.
+// Only the first call has an effect.
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+
+	s.ended = true
+	s.data.End = time.Now()
+	data := s.data
+	data.Attributes = maps.Clone(s.data.Attributes)
+
+	s.mu.Unlock()
+
+	if s.tracer != nil && data.SpanContext.IsSampled() {
+		s.tracer.export(data)
+	}
+}
+
+type spanKey struct{}
+
+type remoteKey struct{}
+
+// ContextWithSpan returns a copy of ctx with span as the current span.
+func ContextWithSpan(ctx context.Context, span *Span) context.Context {
+	return context.WithValue(ctx, spanKey{}, span)
+}
+
+// SpanFromContext returns the current span of ctx, nil if there's none.
+func SpanFromContext(ctx context.Context) *Span {
+	span, _ := ctx.Value(spanKey{}).(*Span) //nolint:errcheck
+	return span
+}
+
+// ContextWithRemoteSpanContext returns a copy of ctx with sc as the parent of the next span.
+// It's used by Extract, it hides the current span of ctx.
+func ContextWithRemoteSpanContext(ctx context.Context, sc SpanContext) context.Context {
+	sc.Remote = true
+
+	return context.WithValue(ContextWithSpan(ctx, nil), remoteKey{}, sc)
+}
+
+// SpanContextFromContext returns the span context of the current span of ctx,
+// or the remote span context set by Extract.
+func SpanContextFromContext(ctx context.Context) (SpanContext, bool) {
+	if span := SpanFromContext(ctx); span != nil {
+		return span.SpanContext(), true
+	}
+
+	sc, ok := ctx.Value(remoteKey{}).(SpanContext)
+
+	return sc, ok && sc.IsValid()
+}
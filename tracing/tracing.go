@@ -0,0 +1,361 @@
+// Package tracing provides distributed tracing for go-orb, with W3C trace context
+// propagation through util/metadata and pluggable exporters.
+//
+// Spans get created by the "tracing" client and server middlewares and by the
+// event client wrapper in event/tracing, exporters register themselves in Exporters.
+package tracing
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-orb/go-orb/cli"
+	"github.com/go-orb/go-orb/config"
+	"github.com/go-orb/go-orb/log"
+	"github.com/go-orb/go-orb/types"
+)
+
+// ComponentType is the components name.
+const ComponentType = "tracing"
+
+var _ types.Component = (*Tracer)(nil)
+
+// StartOption configures a span in Tracer.StartSpan.
+type StartOption func(*SpanData)
+
+// WithKind sets the kind of the span, the default is KindInternal.
+func WithKind(kind SpanKind) StartOption {
+	return func(d *SpanData) {
+		d.Kind = kind
+	}
+}
+
+// WithAttribute sets an attribute on the span.
+func WithAttribute(key string, value any) StartOption {
+	return func(d *SpanData) {
+		if d.Attributes == nil {
+			d.Attributes = make(map[string]any)
+		}
+
+		d.Attributes[key] = value
+	}
+}
+
+// Tracer creates spans and exports them.
+//
+// Once started, spans are exported in batches from a background goroutine,
+// before that and after Stop they are exported when they end.
+type Tracer struct {
+	config   Config
+	exporter Exporter
+	logger   log.Logger
+
+	// mu guards running, spans are only queued while it's set.
+	mu      sync.RWMutex
+	running bool
+	queue   chan SpanData
+	stop    chan struct{}
+	done    chan struct{}
+
+	// exportMu makes sure the exporter is called by one goroutine at a time.
+	exportMu sync.Mutex
+	dropped  atomic.Uint64
+}
+
+// New creates a tracer, exporter may be nil to only propagate trace contexts.
+func New(cfg Config, exporter Exporter, logger log.Logger) *Tracer {
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = DefaultBatchSize
+	}
+
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = DefaultQueueSize
+	}
+
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = DefaultFlushInterval
+	}
+
+	return &Tracer{
+		config:   cfg,
+		exporter: exporter,
+		logger:   logger,
+	}
+}
+
+// NewFromConfig creates a tracer with the exporter plugin cfg.Exporter, configData gets
+// passed to its provider. Without an exporter the tracer only propagates trace contexts.
+func NewFromConfig(cfg Config, configData map[string]any, logger log.Logger) (*Tracer, error) {
+	if cfg.Exporter == "" {
+		return New(cfg, nil, logger), nil
+	}
+
+	provider, ok := Exporters.Get(cfg.Exporter)
+	if !ok {
+		return nil, fmt.Errorf("tracing exporter '%s' not found, did you import it?", cfg.Exporter)
+	}
+
+	exporter, err := provider(configData, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	return New(cfg, exporter, logger), nil
+}
+
+// Provide creates a tracer with the exporter from the "tracing" config section
+// and registers it as component.
+func Provide(
+	svcCtx *cli.ServiceContextWithConfig,
+	components *types.Components,
+	logger log.Logger,
+	opts ...Option,
+) (*Tracer, error) {
+	cfg := NewConfig(opts...)
+
+	if err := config.Parse(nil, DefaultConfigSection, svcCtx.Config(), &cfg); err != nil && !errors.Is(err, config.ErrNoSuchKey) {
+		return nil, err
+	}
+
+	configData, err := config.WalkMap([]string{DefaultConfigSection}, svcCtx.Config())
+	if err != nil && !errors.Is(err, config.ErrNoSuchKey) {
+		return nil, err
+	}
+
+	// Configure the logger.
+	cLogger, err := logger.WithConfig([]string{}, configData)
+	if err != nil {
+		return nil, err
+	}
+
+	cLogger = cLogger.With(slog.String("component", ComponentType), slog.String("exporter", cfg.Exporter))
+
+	instance, err := NewFromConfig(cfg, configData, cLogger)
+	if err != nil {
+		return nil, err
+	}
+
+	// Register the tracer as a component.
+	if err := components.Add(instance, types.PriorityTracing); err != nil {
+		logger.Warn("while registering tracing as a component", "error", err)
+	}
+
+	return instance, nil
+}
+
+// Start starts exporting spans in batches.
+func (t *Tracer) Start(_ context.Context) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.running || t.exporter == nil {
+		return nil
+	}
+
+	t.running = true
+	t.queue = make(chan SpanData, t.config.QueueSize)
+	t.stop = make(chan struct{})
+	t.done = make(chan struct{})
+
+	go t.loop(t.queue, t.stop, t.done)
+
+	return nil
+}
+
+// Stop exports the buffered spans.
+func (t *Tracer) Stop(ctx context.Context) error {
+	t.mu.Lock()
+
+	if !t.running {
+		t.mu.Unlock()
+		return nil
+	}
+
+	t.running = false
+	close(t.stop)
+	done := t.done
+
+	t.mu.Unlock()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	if n := t.dropped.Swap(0); n > 0 {
+		t.logger.Warn("dropped spans, the export queue was full", "count", n)
+	}
+
+	return nil
+}
+
+// String returns the name of the exporter.
+func (t *Tracer) String() string {
+	return t.config.Exporter
+}
+
+// Type returns the component type.
+func (t *Tracer) Type() string {
+	return ComponentType
+}
+
+// StartSpan starts a span which is a child of the current span or the remote span context of ctx,
+// it returns a copy of ctx with the new span as current span. Call End on the span when
+// the operation is done.
+//
+// A nil Tracer returns ctx and a nil Span, which can be used like any other span.
+func (t *Tracer) StartSpan(ctx context.Context, name string, opts ...StartOption) (context.Context, *Span) {
+	if t == nil {
+		return ctx, nil
+	}
+
+	data := SpanData{
+		Name:  name,
+		Start: time.Now(),
+	}
+
+	for _, o := range opts {
+		o(&data)
+	}
+
+	if parent, ok := SpanContextFromContext(ctx); ok {
+		data.Parent = parent.SpanID
+		data.SpanContext.TraceID = parent.TraceID
+		data.SpanContext.Flags = parent.Flags
+		data.SpanContext.TraceState = parent.TraceState
+	} else {
+		data.SpanContext.TraceID = newTraceID()
+		if t.sample(data.SpanContext.TraceID) {
+			data.SpanContext.Flags = FlagSampled
+		}
+	}
+
+	data.SpanContext.SpanID = newSpanID()
+
+	span := &Span{tracer: t, data: data}
+
+	return ContextWithSpan(ctx, span), span
+}
+
+// sample decides whether a new trace gets sampled, based on its id so the decision is stable.
+func (t *Tracer) sample(id TraceID) bool {
+	switch {
+	case t.config.SampleRatio >= 1:
+		return true
+	case t.config.SampleRatio <= 0:
+		return false
+	default:
+		return float64(binary.BigEndian.Uint64(id[8:])>>11) < t.config.SampleRatio*(1<<53)
+	}
+}
+
+// export queues data, or exports it right away when the tracer isn't running.
+func (t *Tracer) export(data SpanData) {
+	if t.exporter == nil {
+		return
+	}
+
+	t.mu.RLock()
+
+	if t.running {
+		select {
+		case t.queue <- data:
+		default:
+			t.dropped.Add(1)
+		}
+
+		t.mu.RUnlock()
+
+		return
+	}
+
+	t.mu.RUnlock()
+
+	t.exportBatch([]SpanData{data})
+}
+
+func (t *Tracer) exportBatch(batch []SpanData) {
+	if len(batch) == 0 {
+		return
+	}
+
+	t.exportMu.Lock()
+	defer t.exportMu.Unlock()
+
+	if err := t.exporter.Export(batch); err != nil {
+		t.logger.Error("while exporting spans", "error", err, "count", len(batch))
+	}
+}
+
+func (t *Tracer) loop(queue chan SpanData, stop, done chan struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(time.Duration(t.config.FlushInterval))
+	defer ticker.Stop()
+
+	batch := make([]SpanData, 0, t.config.BatchSize)
+
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+
+		t.exportBatch(batch)
+		batch = make([]SpanData, 0, t.config.BatchSize)
+	}
+
+	for {
+		select {
+		case data := <-queue:
+			batch = append(batch, data)
+			if len(batch) >= t.config.BatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		case <-stop:
+			// Nothing gets queued anymore, export whats left.
+			for {
+				select {
+				case data := <-queue:
+					batch = append(batch, data)
+					if len(batch) >= t.config.BatchSize {
+						flush()
+					}
+				default:
+					flush()
+					return
+				}
+			}
+		}
+	}
+}
+
+func newTraceID() TraceID {
+	var id TraceID
+
+	for !id.IsValid() {
+		binary.BigEndian.PutUint64(id[:8], rand.Uint64()) //nolint:gosec
+		binary.BigEndian.PutUint64(id[8:], rand.Uint64()) //nolint:gosec
+	}
+
+	return id
+}
+
+func newSpanID() SpanID {
+	var id SpanID
+
+	for !id.IsValid() {
+		binary.BigEndian.PutUint64(id[:], rand.Uint64()) //nolint:gosec
+	}
+
+	return id
+}
@@ -0,0 +1,114 @@
+package tracing_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/go-orb/go-orb/log"
+	"github.com/go-orb/go-orb/tracing"
+	"github.com/go-orb/go-orb/tracing/memory"
+	"github.com/go-orb/go-orb/util/metadata"
+	"github.com/go-orb/go-orb/util/orberrors"
+)
+
+func TestTraceparent(t *testing.T) {
+	value := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+	sc, err := tracing.ParseTraceparent(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !sc.IsSampled() || !sc.Remote {
+		t.Fatalf("expected a sampled remote span context, got %+v", sc)
+	}
+
+	if got := sc.Traceparent(); got != value {
+		t.Fatalf("expected %s, got %s", value, got)
+	}
+
+	for _, invalid := range []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+	} {
+		if _, err := tracing.ParseTraceparent(invalid); err == nil {
+			t.Fatalf("expected an error for %q", invalid)
+		}
+	}
+
+	if _, err := tracing.ParseTraceparent("01" + value[2:] + "-future"); err != nil {
+		t.Fatalf("expected future versions to be accepted: %v", err)
+	}
+}
+
+func TestPropagation(t *testing.T) {
+	logger := log.Logger{Logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+	exporter := memory.New()
+	tracer := tracing.New(tracing.NewConfig(), exporter, logger)
+
+	if err := tracer.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	// Client side.
+	ctx, clientSpan := tracer.StartSpan(context.Background(), "client", tracing.WithKind(tracing.KindClient))
+	md := metadata.New(nil)
+	tracing.Inject(ctx, md)
+	clientSpan.End()
+
+	// Server side.
+	ctx = tracing.Extract(context.Background(), md)
+	_, serverSpan := tracer.StartSpan(ctx, "server", tracing.WithKind(tracing.KindServer))
+	serverSpan.SetError(orberrors.ErrNotFound)
+	serverSpan.End()
+
+	if err := tracer.Stop(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	spans := exporter.Spans()
+	if len(spans) != 2 {
+		t.Fatalf("expected 2 spans, got %d", len(spans))
+	}
+
+	client, server := spans[0], spans[1]
+
+	if server.SpanContext.TraceID != client.SpanContext.TraceID {
+		t.Fatal("expected the server span in the trace of the client")
+	}
+
+	if server.Parent != client.SpanContext.SpanID {
+		t.Fatal("expected the client span as parent of the server span")
+	}
+
+	if !errors.Is(server.Err, orberrors.ErrNotFound) || server.Attributes[tracing.AttrErrorCode] != 404 {
+		t.Fatalf("expected the error on the server span, got %v %v", server.Err, server.Attributes)
+	}
+}
+
+func TestSampling(t *testing.T) {
+	logger := log.Logger{Logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+	exporter := memory.New()
+	tracer := tracing.New(tracing.NewConfig(tracing.WithSampleRatio(0)), exporter, logger)
+
+	ctx, span := tracer.StartSpan(context.Background(), "root")
+	_, child := tracer.StartSpan(ctx, "child")
+
+	if span.SpanContext().IsSampled() || child.SpanContext().IsSampled() {
+		t.Fatal("expected unsampled spans")
+	}
+
+	child.End()
+	span.End()
+
+	if n := len(exporter.Spans()); n != 0 {
+		t.Fatalf("expected no exported spans, got %d", n)
+	}
+}
@@ -8,15 +8,17 @@ import (
 
 // Priority constants.
 const (
-	PriorityLogger   = 1000
-	PriorityMetrics  = 1100
-	PriorityRegistry = 1200
-	PriorityEvent    = 1300
-	PriorityHandler  = 1350
-	PriorityServer   = 1400
-	PriorityClient   = 1500
-	PriorityKVStore  = 1600
-	PriorityCustom   = 2000
+	PriorityLogger    = 1000
+	PriorityMetrics   = 1100
+	PriorityTracing   = 1150
+	PriorityRegistry  = 1200
+	PriorityEvent     = 1300
+	PriorityHandler   = 1350
+	PriorityServer    = 1400
+	PriorityRegistrar = 1450
+	PriorityClient    = 1500
+	PriorityKVStore   = 1600
+	PriorityCustom    = 2000
 )
 
 // Component needs to be implemented by every component.
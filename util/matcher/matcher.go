@@ -0,0 +1,334 @@
+// Package matcher provides a generic map with regex keys, that can be used to
+// match items to paths, e.g. when using middleware.
+package matcher
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/go-orb/go-orb/util/container"
+	"github.com/go-orb/go-orb/util/slicemap"
+)
+
+// maxCached is the number of operations whose matches get cached.
+const maxCached = 1024
+
+type itemContainer[T any] struct {
+	Name string
+	Item T
+
+	// global items match all operations which aren't excluded.
+	global   bool
+	includes []*regexp.Regexp
+	excludes []*regexp.Regexp
+}
+
+// matches returns true if operation is selected by the items selectors.
+func (ic *itemContainer[T]) matches(operation string) bool {
+	for _, re := range ic.excludes {
+		if re.MatchString(operation) {
+			return false
+		}
+	}
+
+	if ic.global {
+		return true
+	}
+
+	for _, re := range ic.includes {
+		if re.MatchString(operation) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Matcher is a map with regular expressions as keys. It is used to add
+// middleware on a path based level.
+//
+// In addition to setting regex selectors for each item, they are also
+// de-duplicated with a name key, to make sure each item is only added once.
+// Items are matched in the order they have been added.
+//
+// Matcher must be set up before Match gets called, Match is safe for concurrent use.
+type Matcher[T any] struct {
+	plugins *container.Map[string, T]
+	items   []*itemContainer[T]
+
+	// cache holds the results of Match by operation.
+	cache *cache[T]
+}
+
+type cache[T any] struct {
+	mu      sync.RWMutex
+	matches map[string][]*itemContainer[T]
+}
+
+// NewMatcher creates a new matcher object.
+func NewMatcher[T any](plugins *container.Map[string, T]) Matcher[T] {
+	return Matcher[T]{
+		plugins: plugins,
+		cache:   &cache[T]{matches: make(map[string][]*itemContainer[T])},
+	}
+}
+
+// Use will use the elements provided on all paths.
+func (m *Matcher[T]) Use(name string, item T) {
+	m.item(name, item).global = true
+	m.reset()
+}
+
+// AddPlugin will add plugin item, with a selector.
+func (m *Matcher[T]) AddPlugin(selector, plugin string) error {
+	if m.plugins == nil {
+		return fmt.Errorf("plugin not found '%s'", plugin)
+	}
+
+	item, ok := m.plugins.Get(plugin)
+	if !ok {
+		return fmt.Errorf("plugin not found '%s'", plugin)
+	}
+
+	return m.Add(selector, plugin, item)
+}
+
+// Add will add an item to every item the selector matches. The selector
+// is a regexp.
+//
+// Example selector:
+//   - /*
+//   - /echo
+//   - /echo/*
+//   - /echo[1-9]
+//   - suffix$
+func (m *Matcher[T]) Add(selector, name string, item T) error {
+	if isGlobal(selector) {
+		m.Use(name, item)
+		return nil
+	}
+
+	re, err := compile(selector)
+	if err != nil {
+		return err
+	}
+
+	ic := m.item(name, item)
+
+	for _, existing := range ic.includes {
+		if existing.String() == re.String() {
+			return nil
+		}
+	}
+
+	ic.includes = append(ic.includes, re)
+	m.reset()
+
+	return nil
+}
+
+// Exclude will remove the item called name from all operations the selector matches,
+// even if it has been added with Use. The selector is a regexp, like with Add.
+func (m *Matcher[T]) Exclude(selector, name string) error {
+	ic, ok := m.find(name)
+	if !ok {
+		return fmt.Errorf("item not found '%s'", name)
+	}
+
+	if isGlobal(selector) {
+		selector = ".*"
+	}
+
+	re, err := compile(selector)
+	if err != nil {
+		return err
+	}
+
+	ic.excludes = append(ic.excludes, re)
+	m.reset()
+
+	return nil
+}
+
+// Match will fetch the list of items that match against a path.
+func (m *Matcher[T]) Match(operation string) []T {
+	return extractItems(m.match(operation))
+}
+
+// Matches returns true if the item called name matches operation.
+func (m *Matcher[T]) Matches(operation, name string) bool {
+	for _, ic := range m.match(operation) {
+		if ic.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Len returns the total number of items defined.
+func (m Matcher[T]) Len() int {
+	return len(m.items)
+}
+
+func (m *Matcher[T]) match(operation string) []*itemContainer[T] {
+	if m.cache == nil {
+		m.cache = &cache[T]{matches: make(map[string][]*itemContainer[T])}
+	}
+
+	m.cache.mu.RLock()
+	matches, ok := m.cache.matches[operation]
+	m.cache.mu.RUnlock()
+
+	if ok {
+		return matches
+	}
+
+	matches = make([]*itemContainer[T], 0, len(m.items))
+
+	for _, ic := range m.items {
+		if ic.matches(operation) {
+			matches = append(matches, ic)
+		}
+	}
+
+	m.cache.mu.Lock()
+	if len(m.cache.matches) < maxCached {
+		m.cache.matches[operation] = matches
+	}
+	m.cache.mu.Unlock()
+
+	return matches
+}
+
+// item returns the item called name, it adds it if it's not present.
+func (m *Matcher[T]) item(name string, item T) *itemContainer[T] {
+	if ic, ok := m.find(name); ok {
+		return ic
+	}
+
+	ic := &itemContainer[T]{Name: name, Item: item}
+	m.items = append(m.items, ic)
+
+	return ic
+}
+
+func (m *Matcher[T]) find(name string) (*itemContainer[T], bool) {
+	for _, ic := range m.items {
+		if ic.Name == name {
+			return ic, true
+		}
+	}
+
+	return nil, false
+}
+
+// reset clears the cache, the caller must not call Match concurrently.
+func (m *Matcher[T]) reset() {
+	m.cache = &cache[T]{matches: make(map[string][]*itemContainer[T])}
+}
+
+func isGlobal(selector string) bool {
+	switch selector {
+	case "/*", "*", ".*", "^.*":
+		return true
+	default:
+		return false
+	}
+}
+
+func compile(selector string) (*regexp.Regexp, error) {
+	if strings.HasSuffix(selector, "/*") {
+		selector = strings.TrimSuffix(selector, "/*")
+		selector += "/.*"
+	}
+
+	re, err := regexp.Compile(selector)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile selector as regexp '%s': %w", selector, err)
+	}
+
+	return re, nil
+}
+
+func extractItems[T any](items []*itemContainer[T]) []T {
+	output := make([]T, 0, len(items))
+
+	for _, item := range items {
+		output = append(output, item.Item)
+	}
+
+	return output
+}
+
+// UnmarshalJSON will unmarshal a JSON file into the matcher.
+// JSON can contain either a string or <name, selector, exclude> map.
+//
+// All items provided through the config need to be registered as plugins.
+// All config will add to, not replace the exisiting items.
+//
+// JSON config example:
+//
+//	{
+//	   "middleware":[
+//	      "abc",
+//	      {
+//	         "name":"def",
+//	         "selector":"/foo",
+//	         "exclude":"/foo/health"
+//	      }
+//	   ]
+//	}
+func (m *Matcher[T]) UnmarshalJSON(data []byte) error {
+	var a any
+
+	if err := json.Unmarshal(data, &a); err != nil {
+		return err
+	}
+
+	b, ok := a.([]any)
+	if !ok {
+		return nil
+	}
+
+	return m.unmarshal(b)
+}
+
+func (m *Matcher[T]) unmarshal(yee []any) error {
+	for _, i := range yee {
+		if item, ok := i.(map[string]any); ok {
+			name, ok := slicemap.Get[string](item, "name")
+			if !ok {
+				continue
+			}
+
+			selector, ok := slicemap.Get[string](item, "selector")
+			if !ok {
+				selector = "/*"
+			}
+
+			if err := m.AddPlugin(selector, name); err != nil {
+				return err
+			}
+
+			if exclude, ok := slicemap.Get[string](item, "exclude"); ok {
+				if err := m.Exclude(exclude, name); err != nil {
+					return err
+				}
+			}
+
+			continue
+		}
+
+		if global, ok := i.(string); ok {
+			if err := m.AddPlugin("/*", global); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,154 @@
+package matcher
+
+import (
+	"encoding/json"
+	"slices"
+	"strconv"
+	"testing"
+
+	"github.com/go-orb/go-orb/util/container"
+	"github.com/go-orb/go-orb/util/slicemap"
+)
+
+var jsonFile = `{"middleware": ["abc", {"name": "def", "selector": "/foo[1-9]", "exclude": "/foo9"}]}`
+
+type test struct {
+	selector string
+	add      []int
+	expected []int
+}
+
+var tests = []test{
+	{selector: "/*", add: []int{1, 2}, expected: []int{1, 2}},
+	{selector: "/helloworld", add: []int{3}, expected: []int{1, 2, 3}},
+	{selector: "/helloworld/echo", add: []int{4}, expected: []int{1, 2, 3, 4}},
+	{selector: "/foo", add: []int{}, expected: []int{1, 2}},
+	{selector: "/foo/*", add: []int{5}, expected: []int{1, 2, 5}},
+	{selector: "/foo/bar", add: []int{}, expected: []int{1, 2, 5}},
+}
+
+func TestMatcher(t *testing.T) {
+	m := NewMatcher[int](nil)
+
+	for _, test := range tests {
+		// Add test cases.
+		for _, item := range test.add {
+			name := strconv.Itoa(item)
+			if err := m.Add(test.selector, name, item); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		// Verify test cases.
+		res := m.Match(test.selector)
+		for _, i := range test.expected {
+			if !slicemap.In(res, i) {
+				t.Fatalf("%s: expected %d in %v", test.selector, i, res)
+			}
+		}
+	}
+}
+
+func TestMatcherDuplication(t *testing.T) {
+	plugins := container.NewMap[string, string]()
+	plugins.Set("one", "itemOne")
+	plugins.Set("two", "itemTwo")
+
+	m := NewMatcher(plugins)
+
+	m.Use("customOne", "itemOneC")
+	m.Use("customOne", "itemOneC")
+	m.Use("customOne", "itemOneC")
+
+	if m.Len() != 1 {
+		t.Fatalf("expected 1 item, got %d", m.Len())
+	}
+
+	for range 2 {
+		if err := m.AddPlugin("/helloworld", "one"); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := m.AddPlugin("/helloworld", "two"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if m.Len() != 3 {
+		t.Fatalf("expected 3 items, got %d", m.Len())
+	}
+
+	for _, ic := range m.items[1:] {
+		if len(ic.includes) != 1 {
+			t.Fatalf("expected 1 selector for %s, got %d", ic.Name, len(ic.includes))
+		}
+	}
+
+	if err := m.AddPlugin("/foo", "three"); err == nil {
+		t.Fatal("expected an error for an unknown plugin")
+	}
+}
+
+func TestMatcherExclude(t *testing.T) {
+	m := NewMatcher[string](nil)
+
+	m.Use("auth", "auth")
+
+	if err := m.Add("^admin/", "admin", "admin"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := m.Exclude("Health/Check$", "auth"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := m.Exclude("/foo", "unknown"); err == nil {
+		t.Fatal("expected an error for an unknown item")
+	}
+
+	for _, tc := range []struct {
+		operation string
+		expected  []string
+	}{
+		{"svc/Foo.Bar", []string{"auth"}},
+		{"svc/grpc.health.v1.Health/Check", []string{}},
+		{"admin/Users.Delete", []string{"auth", "admin"}},
+	} {
+		// Twice, to hit the cache.
+		for range 2 {
+			if got := m.Match(tc.operation); !slices.Equal(got, tc.expected) {
+				t.Fatalf("%s: expected %v, got %v", tc.operation, tc.expected, got)
+			}
+		}
+	}
+
+	if !m.Matches("admin/Users.Delete", "admin") || m.Matches("svc/Foo.Bar", "admin") {
+		t.Fatal("unexpected result of Matches")
+	}
+}
+
+func TestMatcherJson(t *testing.T) {
+	plugins := container.NewMap[string, string]()
+	plugins.Set("abc", "abc")
+	plugins.Set("def", "def")
+
+	a := struct {
+		Middlware Matcher[string] `json:"middleware"`
+	}{
+		Middlware: NewMatcher(plugins),
+	}
+
+	if err := json.Unmarshal([]byte(jsonFile), &a); err != nil {
+		t.Fatal(err)
+	}
+
+	for operation, expected := range map[string][]string{
+		"/bar":  {"abc"},
+		"/foo1": {"abc", "def"},
+		"/foo9": {"abc"},
+	} {
+		if got := a.Middlware.Match(operation); !slices.Equal(got, expected) {
+			t.Fatalf("%s: expected %v, got %v", operation, expected, got)
+		}
+	}
+}
@@ -0,0 +1,343 @@
+package metadata
+
+import (
+	"encoding/base64"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// BinarySuffix marks keys with binary values, they are base64 encoded, like in gRPC.
+const BinarySuffix = "-bin"
+
+// MD is metadata with case-insensitive keys and multiple values per key,
+// it's safe for concurrent use.
+//
+// Clone is cheap, the data gets shared until one of the copies is written to.
+type MD struct {
+	mu   sync.Mutex
+	data map[string][]string
+	// shared is set when data is shared with a clone, it must be copied before writing.
+	shared bool
+	// view is the plain map handed out by Incoming, Outgoing, WithIncoming and WithOutgoing,
+	// seen is the view as of the last sync. Writes to the view are picked up on the next
+	// access, writes to MD get mirrored into it.
+	view map[string]string
+	seen map[string]string
+}
+
+// New creates metadata from a plain map.
+func New(kv map[string]string) *MD {
+	md := &MD{data: make(map[string][]string, len(kv))}
+
+	for k, v := range kv {
+		md.data[strings.ToLower(k)] = []string{v}
+	}
+
+	return md
+}
+
+// Pairs creates metadata from key, value pairs, a key may be repeated for multiple values.
+// A trailing key without value is ignored.
+func Pairs(kv ...string) *MD {
+	md := &MD{data: make(map[string][]string, len(kv)/2)}
+
+	for i := 0; i+1 < len(kv); i += 2 {
+		k := strings.ToLower(kv[i])
+		md.data[k] = append(md.data[k], kv[i+1])
+	}
+
+	return md
+}
+
+// Get returns a copy of all values of key.
+func (md *MD) Get(key string) []string {
+	md.mu.Lock()
+	defer md.mu.Unlock()
+
+	md.sync()
+
+	return slices.Clone(md.data[strings.ToLower(key)])
+}
+
+// Value returns the first value of key.
+func (md *MD) Value(key string) (string, bool) {
+	md.mu.Lock()
+	defer md.mu.Unlock()
+
+	md.sync()
+
+	values := md.data[strings.ToLower(key)]
+	if len(values) == 0 {
+		return "", false
+	}
+
+	return values[0], true
+}
+
+// Set replaces the values of key.
+func (md *MD) Set(key string, values ...string) {
+	md.mu.Lock()
+	defer md.mu.Unlock()
+
+	md.sync()
+	md.own()
+
+	key = strings.ToLower(key)
+
+	if len(values) == 0 {
+		delete(md.data, key)
+		md.mirror(key, "", false)
+
+		return
+	}
+
+	md.data[key] = slices.Clone(values)
+	md.mirror(key, values[0], true)
+}
+
+// Append adds values to key.
+func (md *MD) Append(key string, values ...string) {
+	if len(values) == 0 {
+		return
+	}
+
+	md.mu.Lock()
+	defer md.mu.Unlock()
+
+	md.sync()
+	md.own()
+
+	key = strings.ToLower(key)
+	md.data[key] = append(slices.Clone(md.data[key]), values...)
+	md.mirror(key, md.data[key][0], true)
+}
+
+// Delete removes key.
+func (md *MD) Delete(key string) {
+	md.Set(key)
+}
+
+// Len returns the number of keys.
+func (md *MD) Len() int {
+	md.mu.Lock()
+	defer md.mu.Unlock()
+
+	md.sync()
+
+	return len(md.data)
+}
+
+// Keys returns the sorted keys.
+func (md *MD) Keys() []string {
+	md.mu.Lock()
+	defer md.mu.Unlock()
+
+	md.sync()
+
+	return slices.Sorted(maps.Keys(md.data))
+}
+
+// Range calls f for each key with a copy of its values, in sorted order, until f returns false.
+func (md *MD) Range(f func(key string, values []string) bool) {
+	c := md.Clone()
+
+	for _, k := range slices.Sorted(maps.Keys(c.data)) {
+		if !f(k, slices.Clone(c.data[k])) {
+			return
+		}
+	}
+}
+
+// Clone returns a copy, the data gets copied once one of them is written to.
+func (md *MD) Clone() *MD {
+	md.mu.Lock()
+	defer md.mu.Unlock()
+
+	md.sync()
+
+	md.shared = true
+
+	return &MD{data: md.data, shared: true}
+}
+
+// Merge appends the values of others.
+func (md *MD) Merge(others ...*MD) {
+	for _, other := range others {
+		if other == nil || other == md {
+			continue
+		}
+
+		other.Range(func(key string, values []string) bool {
+			md.Append(key, values...)
+			return true
+		})
+	}
+}
+
+// Map returns a plain map with the first value of each key.
+func (md *MD) Map() map[string]string {
+	md.mu.Lock()
+	defer md.mu.Unlock()
+
+	md.sync()
+
+	result := make(map[string]string, len(md.data))
+
+	for k, v := range md.data {
+		if len(v) > 0 {
+			result[k] = v[0]
+		}
+	}
+
+	return result
+}
+
+// SetBinary replaces the values of key with the base64 encoded values,
+// BinarySuffix gets added to key if it's missing.
+func (md *MD) SetBinary(key string, values ...[]byte) {
+	if !IsBinaryKey(key) {
+		key += BinarySuffix
+	}
+
+	encoded := make([]string, len(values))
+	for i, v := range values {
+		encoded[i] = EncodeBinary(v)
+	}
+
+	md.Set(key, encoded...)
+}
+
+// GetBinary returns the decoded values of key, BinarySuffix gets added to key if it's missing.
+func (md *MD) GetBinary(key string) ([][]byte, error) {
+	if !IsBinaryKey(key) {
+		key += BinarySuffix
+	}
+
+	values := md.Get(key)
+	result := make([][]byte, 0, len(values))
+
+	for _, v := range values {
+		b, err := DecodeBinary(v)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, b)
+	}
+
+	return result, nil
+}
+
+// IsBinaryKey returns true if key has binary values.
+func IsBinaryKey(key string) bool {
+	return len(key) >= len(BinarySuffix) && strings.EqualFold(key[len(key)-len(BinarySuffix):], BinarySuffix)
+}
+
+// EncodeBinary encodes a binary value.
+func EncodeBinary(v []byte) string {
+	return base64.RawStdEncoding.EncodeToString(v)
+}
+
+// DecodeBinary decodes a binary value, with or without padding.
+func DecodeBinary(v string) ([]byte, error) {
+	if len(v)%4 == 0 {
+		return base64.StdEncoding.DecodeString(v)
+	}
+
+	return base64.RawStdEncoding.DecodeString(v)
+}
+
+// own copies the data if it's shared, the caller must hold md.mu.
+func (md *MD) own() {
+	if md.data == nil {
+		md.data = make(map[string][]string)
+	}
+
+	if !md.shared {
+		return
+	}
+
+	data := make(map[string][]string, len(md.data))
+	for k, v := range md.data {
+		data[k] = slices.Clone(v)
+	}
+
+	md.data = data
+	md.shared = false
+}
+
+// plain returns the plain view of md, the caller must not hold md.mu.
+func (md *MD) plain() map[string]string {
+	md.mu.Lock()
+	defer md.mu.Unlock()
+
+	if md.view == nil {
+		md.view = make(map[string]string, len(md.data))
+
+		for k, v := range md.data {
+			if len(v) > 0 {
+				md.view[k] = v[0]
+			}
+		}
+
+		md.seen = maps.Clone(md.view)
+	}
+
+	return md.view
+}
+
+// sync picks up the writes to the plain view, the caller must hold md.mu.
+//
+// A key written in mixed case is stored in lower case, the view gets the lower case key as well.
+// A deleted key gets removed in all cases.
+func (md *MD) sync() {
+	if md.view == nil {
+		return
+	}
+
+	for k := range md.seen {
+		if _, ok := md.view[k]; !ok {
+			md.own()
+			delete(md.data, strings.ToLower(k))
+			md.mirror(strings.ToLower(k), "", false)
+		}
+	}
+
+	for k, v := range md.view {
+		if old, ok := md.seen[k]; ok && old == v {
+			continue
+		}
+
+		md.own()
+
+		key := strings.ToLower(k)
+		md.data[key] = []string{v}
+		md.mirror(key, v, true)
+	}
+}
+
+// mirror writes a change of key into the plain view, to all cases of key which are in it
+// and to the lower case key. The caller must hold md.mu.
+func (md *MD) mirror(key, value string, ok bool) {
+	if md.view == nil {
+		return
+	}
+
+	for _, m := range []map[string]string{md.view, md.seen} {
+		for k := range m {
+			if strings.EqualFold(k, key) {
+				delete(m, k)
+
+				if ok {
+					m[k] = value
+				}
+			}
+		}
+
+		if ok {
+			m[key] = value
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package metadata
+
+import (
+	"bytes"
+	"context"
+	"slices"
+	"testing"
+)
+
+func TestMD(t *testing.T) {
+	md := Pairs("Accept", "a", "accept", "b", "X-Key-Bin", EncodeBinary([]byte{0, 1, 2}))
+
+	if got := md.Get("ACCEPT"); !slices.Equal(got, []string{"a", "b"}) {
+		t.Fatalf("expected case-insensitive multi-value keys, got %v", got)
+	}
+
+	clone := md.Clone()
+	clone.Append("accept", "c")
+	md.Set("accept", "z")
+
+	if got := clone.Get("accept"); !slices.Equal(got, []string{"a", "b", "c"}) {
+		t.Fatalf("clone changed by a write to the original: %v", got)
+	}
+
+	if got := md.Get("accept"); !slices.Equal(got, []string{"z"}) {
+		t.Fatalf("original changed by a write to the clone: %v", got)
+	}
+
+	b, err := md.GetBinary("x-key")
+	if err != nil || len(b) != 1 || !bytes.Equal(b[0], []byte{0, 1, 2}) {
+		t.Fatalf("unexpected binary value %v, error: %v", b, err)
+	}
+
+	md.Merge(Pairs("accept", "y"))
+
+	if got := md.Get("accept"); !slices.Equal(got, []string{"z", "y"}) {
+		t.Fatalf("unexpected merge result: %v", got)
+	}
+}
+
+func TestPlainView(t *testing.T) {
+	ctx, plain := WithIncoming(context.Background())
+	plain[Service] = "svc"
+	plain["X-Tenant"] = "t1"
+
+	if v, _ := GetIncoming(ctx, "x-tenant"); v != "t1" {
+		t.Fatalf("write to the plain map not picked up, got %q", v)
+	}
+
+	if v, _ := GetIncoming(ctx, Service); v != "svc" {
+		t.Fatalf("write to the plain map not picked up, got %q", v)
+	}
+
+	md, _ := IncomingMD(ctx)
+	md.Set("x-tenant", "t2")
+	md.Set("caller", "web")
+
+	// Writes to the MD are mirrored in all cases of the key, lookups in lower case always hit.
+	if plain["X-Tenant"] != "t2" || plain["x-tenant"] != "t2" || plain["caller"] != "web" {
+		t.Fatalf("write to the MD not mirrored, got %v", plain)
+	}
+
+	delete(plain, "X-Tenant")
+	delete(plain, "x-tenant")
+
+	if _, ok := GetIncoming(ctx, "x-tenant"); ok {
+		t.Fatal("delete from the plain map not picked up")
+	}
+
+	ctx, out := WithOutgoing(ctx)
+	out["Retry-After"] = "2"
+
+	if v, _ := GetOutgoing(ctx, "retry-after"); v != "2" {
+		t.Fatalf("write to the plain map not picked up, got %q", v)
+	}
+
+	// The plain map of an existing MD is the same view.
+	if again, _ := Outgoing(ctx); again["retry-after"] != "2" {
+		t.Fatalf("expected the same view, got %v", again)
+	}
+
+	SetOutgoing(ctx, "retry-after", "3")
+
+	if out["Retry-After"] != "3" {
+		t.Fatalf("write to the MD not mirrored, got %v", out)
+	}
+}
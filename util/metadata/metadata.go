@@ -11,41 +11,149 @@ const Service = "service"
 // Method is the key for the RPC Method.
 const Method = "method"
 
+// Timeout is the key for the time in milliseconds the caller waits for the response.
+const Timeout = "timeout"
+
 type incomingKey struct{}
 type outgoingKey struct{}
 
-// Incoming retrieves incoming metadata from the context.
-func Incoming(ctx context.Context) (map[string]string, bool) {
-	md, ok := ctx.Value(incomingKey{}).(map[string]string)
-	return md, ok
+func from(ctx context.Context, key any) (*MD, bool) {
+	md, ok := ctx.Value(key).(*MD)
+	return md, ok && md != nil
 }
 
-// WithIncoming sets metadata as value to the context and returns context as well as the metadata.
-func WithIncoming(ctx context.Context) (context.Context, map[string]string) {
-	if md, ok := ctx.Value(incomingKey{}).(map[string]string); ok {
+func with(ctx context.Context, key any) (context.Context, *MD) {
+	if md, ok := from(ctx, key); ok {
 		return ctx, md
 	}
 
-	md := make(map[string]string)
-	ctx = context.WithValue(ctx, incomingKey{}, md)
+	md := &MD{data: make(map[string][]string)}
+
+	return context.WithValue(ctx, key, md), md
+}
+
+// IncomingMD returns the incoming metadata of ctx.
+func IncomingMD(ctx context.Context) (*MD, bool) {
+	return from(ctx, incomingKey{})
+}
+
+// WithIncomingMD returns the incoming metadata of ctx, it adds empty metadata to ctx if there's none.
+func WithIncomingMD(ctx context.Context) (context.Context, *MD) {
+	return with(ctx, incomingKey{})
+}
+
+// NewIncoming stores md as incoming metadata in ctx.
+func NewIncoming(ctx context.Context, md *MD) context.Context {
+	return context.WithValue(ctx, incomingKey{}, md)
+}
+
+// OutgoingMD returns the outgoing metadata of ctx.
+func OutgoingMD(ctx context.Context) (*MD, bool) {
+	return from(ctx, outgoingKey{})
+}
+
+// WithOutgoingMD returns the outgoing metadata of ctx, it adds empty metadata to ctx if there's none.
+func WithOutgoingMD(ctx context.Context) (context.Context, *MD) {
+	return with(ctx, outgoingKey{})
+}
+
+// NewOutgoing stores md as outgoing metadata in ctx, it hides the outgoing metadata of the parents.
+// Use it with a clone of the parents metadata to change it for a single request.
+func NewOutgoing(ctx context.Context, md *MD) context.Context {
+	return context.WithValue(ctx, outgoingKey{}, md)
+}
+
+// Incoming retrieves incoming metadata from the context.
+//
+// The map is a live view of the first value of each key of IncomingMD, it's not safe for
+// concurrent use. Writes and deletes are picked up by the MD, keys are stored in lower case
+// and the view holds each key in lower case, look keys up in lower case.
+func Incoming(ctx context.Context) (map[string]string, bool) {
+	md, ok := IncomingMD(ctx)
+	if !ok {
+		return nil, false
+	}
+
+	return md.plain(), true
+}
 
-	return ctx, md
+// WithIncoming sets metadata as value to the context and returns context as well as the metadata.
+//
+// See Incoming for the limits of the map.
+func WithIncoming(ctx context.Context) (context.Context, map[string]string) {
+	ctx, md := WithIncomingMD(ctx)
+	return ctx, md.plain()
 }
 
 // Outgoing retrieves outgoing metadata from the context.
+//
+// The map is a live view of the first value of each key of OutgoingMD, see Incoming for its limits.
 func Outgoing(ctx context.Context) (map[string]string, bool) {
-	md, ok := ctx.Value(outgoingKey{}).(map[string]string)
-	return md, ok
+	md, ok := OutgoingMD(ctx)
+	if !ok {
+		return nil, false
+	}
+
+	return md.plain(), true
 }
 
 // WithOutgoing sets metadata as value to the context and returns context as well as the metadata.
+//
+// See Incoming for the limits of the map.
 func WithOutgoing(ctx context.Context) (context.Context, map[string]string) {
-	if md, ok := ctx.Value(outgoingKey{}).(map[string]string); ok {
-		return ctx, md
+	ctx, md := WithOutgoingMD(ctx)
+	return ctx, md.plain()
+}
+
+// GetIncoming returns the first value of key in the incoming metadata.
+func GetIncoming(ctx context.Context, key string) (string, bool) {
+	md, ok := IncomingMD(ctx)
+	if !ok {
+		return "", false
+	}
+
+	return md.Value(key)
+}
+
+// GetOutgoing returns the first value of key in the outgoing metadata.
+func GetOutgoing(ctx context.Context, key string) (string, bool) {
+	md, ok := OutgoingMD(ctx)
+	if !ok {
+		return "", false
 	}
 
-	md := make(map[string]string)
-	ctx = context.WithValue(ctx, outgoingKey{}, md)
+	return md.Value(key)
+}
+
+// SetOutgoing sets a value of the outgoing metadata.
+// It returns false if ctx has no outgoing metadata, use WithOutgoingMD to add it.
+func SetOutgoing(ctx context.Context, key, value string) bool {
+	md, ok := OutgoingMD(ctx)
+	if !ok {
+		return false
+	}
+
+	md.Set(key, value)
+
+	return true
+}
+
+// CloneIncoming returns a plain copy of the incoming metadata.
+func CloneIncoming(ctx context.Context) (map[string]string, bool) {
+	md, ok := IncomingMD(ctx)
+	if !ok {
+		return nil, false
+	}
+
+	return md.Map(), true
+}
+
+// CloneOutgoing returns a plain copy of the outgoing metadata.
+func CloneOutgoing(ctx context.Context) (map[string]string, bool) {
+	md, ok := OutgoingMD(ctx)
+	if !ok {
+		return nil, false
+	}
 
-	return ctx, md
+	return md.Map(), true
 }
@@ -0,0 +1,151 @@
+package metadata
+
+import (
+	"context"
+	"slices"
+	"strings"
+)
+
+type propagatedKey struct{}
+
+// Propagation selects the incoming metadata which gets forwarded to outgoing requests,
+// for example tenant IDs, auth tokens and trace headers.
+//
+// A key is forwarded if it's in Keys or starts with one of Prefixes, unless it's in
+// DenyKeys or starts with one of DenyPrefixes. Keys are compared case-insensitive.
+// Service, Method and Timeout belong to a single hop and are never forwarded.
+type Propagation struct {
+	// Keys are forwarded.
+	Keys []string `json:"keys,omitempty" yaml:"keys,omitempty"`
+	// Prefixes of keys which are forwarded.
+	Prefixes []string `json:"prefixes,omitempty" yaml:"prefixes,omitempty"`
+	// DenyKeys are never forwarded.
+	DenyKeys []string `json:"denyKeys,omitempty" yaml:"denyKeys,omitempty"`
+	// DenyPrefixes of keys which are never forwarded.
+	DenyPrefixes []string `json:"denyPrefixes,omitempty" yaml:"denyPrefixes,omitempty"`
+}
+
+// Empty returns true if p doesn't forward any key.
+func (p Propagation) Empty() bool {
+	return len(p.Keys) == 0 && len(p.Prefixes) == 0
+}
+
+// Allowed returns true if key gets forwarded.
+func (p Propagation) Allowed(key string) bool {
+	key = strings.ToLower(key)
+
+	switch key {
+	case Service, Method, Timeout:
+		return false
+	}
+
+	if matchAny(key, p.DenyKeys, false) || matchAny(key, p.DenyPrefixes, true) {
+		return false
+	}
+
+	return matchAny(key, p.Keys, false) || matchAny(key, p.Prefixes, true)
+}
+
+// Select returns the entries of md which get forwarded.
+func (p Propagation) Select(md *MD) *MD {
+	result := &MD{data: make(map[string][]string)}
+
+	md.Range(func(key string, values []string) bool {
+		if p.Allowed(key) {
+			result.data[key] = values
+		}
+
+		return true
+	})
+
+	return result
+}
+
+func matchAny(key string, patterns []string, prefix bool) bool {
+	return slices.ContainsFunc(patterns, func(pattern string) bool {
+		pattern = strings.ToLower(pattern)
+
+		if prefix {
+			return strings.HasPrefix(key, pattern)
+		}
+
+		return key == pattern
+	})
+}
+
+// Propagate takes the incoming metadata selected by p and keeps it in ctx, those
+// entries get added to the outgoing metadata by WithPropagated. It's used by the
+// server side of the "propagation" middleware, so handlers can pass ctx to their
+// requests and goroutines without copying metadata by hand.
+func Propagate(ctx context.Context, p Propagation) context.Context {
+	if p.Empty() {
+		return ctx
+	}
+
+	in, ok := IncomingMD(ctx)
+	if !ok {
+		return ctx
+	}
+
+	selected := p.Select(in)
+
+	// Keep what has been propagated to this hop already.
+	if parent, ok := Propagated(ctx); ok {
+		addMissing(selected, parent)
+	}
+
+	return context.WithValue(ctx, propagatedKey{}, selected)
+}
+
+// Propagated returns a copy of the metadata taken by Propagate.
+func Propagated(ctx context.Context) (*MD, bool) {
+	md, ok := ctx.Value(propagatedKey{}).(*MD)
+	if !ok || md == nil {
+		return nil, false
+	}
+
+	return md.Clone(), true
+}
+
+// WithPropagated returns a context with a clone of the outgoing metadata, to which
+// the metadata taken by Propagate and the incoming metadata selected by p have been
+// added. Values in the outgoing metadata win.
+//
+// The clone belongs to the returned context only, so it's safe to use it for
+// parallel requests with the same parent.
+func WithPropagated(ctx context.Context, p Propagation) context.Context {
+	add, ok := Propagated(ctx)
+	if !ok {
+		add = &MD{data: make(map[string][]string)}
+	}
+
+	if !p.Empty() {
+		if in, ok := IncomingMD(ctx); ok {
+			add.Merge(p.Select(in))
+		}
+	}
+
+	if add.Len() == 0 {
+		return ctx
+	}
+
+	out := &MD{data: make(map[string][]string)}
+	if parent, ok := OutgoingMD(ctx); ok {
+		out = parent.Clone()
+	}
+
+	addMissing(out, add)
+
+	return NewOutgoing(ctx, out)
+}
+
+// addMissing adds the keys of src which are missing in dst.
+func addMissing(dst, src *MD) {
+	src.Range(func(key string, values []string) bool {
+		if _, ok := dst.Value(key); !ok {
+			dst.Set(key, values...)
+		}
+
+		return true
+	})
+}
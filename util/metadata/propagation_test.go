@@ -0,0 +1,53 @@
+package metadata
+
+import (
+	"context"
+	"testing"
+)
+
+func TestPropagation(t *testing.T) {
+	ctx := NewIncoming(context.Background(), Pairs(
+		"Tenant-ID", "t1",
+		"x-trace-id", "abc",
+		"x-internal-secret", "secret",
+		"authorization", "token",
+		Method, "Foo.Bar",
+	))
+
+	ctx = Propagate(ctx, Propagation{
+		Keys:         []string{"tenant-id"},
+		Prefixes:     []string{"x-"},
+		DenyPrefixes: []string{"x-internal-"},
+	})
+
+	ctx, out := WithOutgoingMD(ctx)
+	out.Set("x-trace-id", "override")
+
+	reqCtx := WithPropagated(ctx, Propagation{Keys: []string{"authorization"}})
+
+	md, ok := CloneOutgoing(reqCtx)
+	if !ok {
+		t.Fatal("expected outgoing metadata")
+	}
+
+	expected := map[string]string{
+		"tenant-id":     "t1",
+		"x-trace-id":    "override",
+		"authorization": "token",
+	}
+
+	if len(md) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, md)
+	}
+
+	for k, v := range expected {
+		if md[k] != v {
+			t.Fatalf("expected %s=%s, got %v", k, v, md)
+		}
+	}
+
+	// The parents metadata must not change.
+	if out.Len() != 1 {
+		t.Fatalf("the parents outgoing metadata has been modified: %v", out)
+	}
+}
@@ -126,6 +126,8 @@ func HTTP(code int) *Error {
 		return ErrCanceled
 	case 401:
 		return ErrUnauthorized
+	case 429:
+		return ErrTooManyRequests
 	case 408:
 		return ErrRequestTimeout
 	case 400:
@@ -173,10 +175,11 @@ func As(err error) (*Error, bool) {
 
 // A list of default errors.
 var (
-	ErrBadRequest          = newHTTP(http.StatusBadRequest)     // 400
-	ErrUnauthorized        = newHTTP(http.StatusUnauthorized)   // 401
-	ErrNotFound            = newHTTP(http.StatusNotFound)       // 404
-	ErrRequestTimeout      = newHTTP(http.StatusRequestTimeout) // 408
+	ErrBadRequest          = newHTTP(http.StatusBadRequest)      // 400
+	ErrUnauthorized        = newHTTP(http.StatusUnauthorized)    // 401
+	ErrNotFound            = newHTTP(http.StatusNotFound)        // 404
+	ErrRequestTimeout      = newHTTP(http.StatusRequestTimeout)  // 408
+	ErrTooManyRequests     = newHTTP(http.StatusTooManyRequests) // 429
 	ErrCanceled            = newHTTP(499)
 	ErrUnimplemented       = newHTTP(http.StatusInternalServerError) // 500
 	ErrNotImplemented      = newHTTP(http.StatusNotImplemented)      // 501